@@ -0,0 +1,42 @@
+// Command breached-filter converts a list of breached password SHA-1 hashes
+// into the bloom filter file read by the auth service through
+// PASSWORD_BREACHED_LIST. Loading the compact filter is much faster than
+// building it from the full list on every start.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"rhythmify/services/auth-service/internal/passwords"
+)
+
+func main() {
+	in := flag.String("in", "", "hash list file, one SHA-1 hash per line")
+	out := flag.String("out", "breached.bloom", "bloom filter output file")
+	rate := flag.Float64("fp", 0.001, "false positive rate")
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	filter, err := passwords.LoadBreachedFilter(*in, *rate)
+	if err != nil {
+		log.Fatalf("Failed to build filter: %v", err)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", *out, err)
+	}
+	defer file.Close()
+
+	if _, err := filter.WriteTo(file); err != nil {
+		log.Fatalf("Failed to write filter: %v", err)
+	}
+
+	log.Printf("Wrote %s", *out)
+}
@@ -2,18 +2,29 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
 
+	"rhythmify/services/auth-service/internal/audit"
 	"rhythmify/services/auth-service/internal/config"
 	"rhythmify/services/auth-service/internal/handlers"
+	"rhythmify/services/auth-service/internal/mailer"
+	"rhythmify/services/auth-service/internal/mfa"
 	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/passwords"
 	"rhythmify/services/auth-service/internal/repository"
 	"rhythmify/services/auth-service/internal/service"
 	"rhythmify/shared/database"
@@ -48,24 +59,294 @@ func main() {
 	}
 	defer database.CloseConnection(db)
 
+	// Initialize token denylist and login attempt tracker (Redis, or
+	// in-memory when Redis is disabled)
+	var denylist repository.TokenDenylist
+	var loginAttempts repository.LoginAttemptTracker
+	var rateLimitStore repository.RateLimitStore
+	var nonces repository.NonceStore
+	if cfg.Redis.Enabled {
+		redisClient, err := database.NewRedisConnection(database.RedisConfig{
+			Addr:     cfg.GetRedisAddr(),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		defer database.CloseRedisConnection(redisClient)
+
+		denylist = repository.NewRedisTokenDenylist(redisClient)
+		loginAttempts = repository.NewRedisLoginAttemptTracker(redisClient)
+		rateLimitStore = repository.NewRedisRateLimitStore(redisClient)
+		nonces = repository.NewRedisNonceStore(redisClient)
+	} else {
+		log.Println("Warning: Redis is disabled, token revocations, login lockouts, rate limits and request nonces are kept in memory")
+		denylist = repository.NewMemoryTokenDenylist()
+		loginAttempts = repository.NewMemoryLoginAttemptTracker()
+		rateLimitStore = repository.NewMemoryRateLimitStore()
+		nonces = repository.NewMemoryNonceStore()
+	}
+
 	// Initialize JWT manager
-	jwtManager := jwt.NewJWTManager(
-		cfg.JWT.Secret,
-		cfg.JWT.AccessExpiration,
-		cfg.JWT.RefreshExpiration,
-	)
+	var jwtManager *jwt.JWTManager
+	if cfg.UsesAsymmetricJWT() {
+		keyring, err := jwt.LoadKeyring(cfg.JWT.PrivateKeyFile, cfg.JWT.PreviousKeyFiles)
+		if err != nil {
+			log.Fatalf("Failed to load JWT signing keys: %v", err)
+		}
+		log.Printf("Signing tokens with %s key %s", keyring.Active().Algorithm, keyring.Active().ID)
+
+		jwtManager = jwt.NewJWTManagerWithKeyring(
+			keyring,
+			cfg.JWT.AccessExpiration,
+			cfg.JWT.RefreshExpiration,
+		)
+	} else {
+		jwtManager = jwt.NewJWTManager(
+			cfg.JWT.Secret,
+			cfg.JWT.AccessExpiration,
+			cfg.JWT.RefreshExpiration,
+		)
+	}
+
+	// Initialize mailer
+	var mail mailer.Mailer
+	if cfg.Mail.Driver == "smtp" {
+		mail = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		})
+	} else {
+		mail, err = mailer.NewFileMailer(cfg.Mail.OutputDir, cfg.Mail.From)
+		if err != nil {
+			log.Fatalf("Failed to initialize mailer: %v", err)
+		}
+		log.Printf("Emails are written to %s", cfg.Mail.OutputDir)
+	}
+
+	// Initialize audit log streaming
+	var auditSinks []audit.Sink
+	for _, name := range cfg.Audit.Sinks {
+		var sink audit.Sink
+		if name == "syslog" {
+			sink, err = audit.NewSyslogSink(cfg.Audit.SyslogNetwork, cfg.Audit.SyslogAddr, cfg.Audit.SyslogTag)
+		} else {
+			sink, err = audit.NewFileSink(cfg.Audit.File)
+		}
+		if err != nil {
+			log.Fatalf("Failed to initialize audit sink %s: %v", name, err)
+		}
+		auditSinks = append(auditSinks, sink)
+	}
+
+	// Initialize the cipher for two-factor secrets
+	mfaKey, err := loadMFAKey(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load MFA encryption key: %v", err)
+	}
+	mfaCipher, err := mfa.NewCipher(mfaKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize MFA cipher: %v", err)
+	}
+
+	// Initialize the WebAuthn relying party for passkeys
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     cfg.WebAuthn.Origins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: cfg.WebAuthn.CeremonyExpiration},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: cfg.WebAuthn.CeremonyExpiration},
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize WebAuthn: %v", err)
+	}
+
+	// Initialize the password policy
+	passwordPolicy := &passwords.Policy{
+		MinLength:        cfg.Password.MinLength,
+		RequireUppercase: cfg.Password.RequireUppercase,
+		RequireLowercase: cfg.Password.RequireLowercase,
+		RequireDigit:     cfg.Password.RequireDigit,
+		RequireSymbol:    cfg.Password.RequireSymbol,
+		MinScore:         cfg.Password.MinScore,
+	}
+	if cfg.Password.BreachedList != "" {
+		breached, err := passwords.LoadBreachedFilter(cfg.Password.BreachedList, cfg.Password.BreachedFalsePositiveRate)
+		if err != nil {
+			log.Fatalf("Failed to load breached password list: %v", err)
+		}
+		passwordPolicy.Breached = breached
+	} else {
+		log.Println("Warning: PASSWORD_BREACHED_LIST is not set, breached passwords are not rejected")
+	}
+
+	// Initialize the password hasher
+	if cfg.Password.Pepper == "" {
+		log.Println("Warning: PASSWORD_PEPPER is not set, passwords are hashed without a pepper")
+	}
+	hasher, err := passwords.NewPasswordHasher(passwords.HasherConfig{
+		Algorithm:  cfg.Password.HashAlgorithm,
+		BcryptCost: cfg.Password.BcryptCost,
+		Argon2: passwords.Argon2Params{
+			Memory:     uint32(cfg.Password.Argon2Memory),
+			Time:       uint32(cfg.Password.Argon2Time),
+			Threads:    uint8(cfg.Password.Argon2Threads),
+			SaltLength: 16,
+			KeyLength:  32,
+		},
+		Pepper:          cfg.Password.Pepper,
+		PreviousPeppers: cfg.Password.PreviousPeppers,
+		Workers:         cfg.Password.HashWorkers,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize password hasher: %v", err)
+	}
 
 	// Initialize repository layer
 	userRepo := repository.NewPostgresUserRepository(db)
+	sessionRepo := repository.NewPostgresSessionRepository(db)
+	refreshTokenRepo := repository.NewPostgresRefreshTokenRepository(db)
+	oneTimeTokenRepo := repository.NewPostgresOneTimeTokenRepository(db)
+	mfaRepo := repository.NewPostgresMFARepository(db)
+	credentialRepo := repository.NewPostgresCredentialRepository(db)
+	exportRepo := repository.NewPostgresDataExportRepository(db)
+	roleRepo := repository.NewPostgresRoleRepository(db)
+	adminActionRepo := repository.NewPostgresAdminActionRepository(db)
+	apiKeyRepo := repository.NewPostgresAPIKeyRepository(db)
+	auditRepo := repository.NewPostgresAuditEventRepository(db)
 
 	// Initialize service layer
-	authService := service.NewAuthService(userRepo, jwtManager)
+	auditService := service.NewAuditService(auditRepo, auditSinks...)
+	tokenService := service.NewTokenService(userRepo, sessionRepo, refreshTokenRepo, oneTimeTokenRepo, roleRepo, denylist, jwtManager, cfg.JWT.Audience)
+	loginGuard := service.NewLoginGuard(loginAttempts, service.LockoutSettings{
+		MaxAccountFailures: cfg.Lockout.MaxAttempts,
+		MaxIPFailures:      cfg.Lockout.MaxIPAttempts,
+		Window:             cfg.Lockout.Window,
+		LockoutDuration:    cfg.Lockout.Duration,
+		BaseDelay:          cfg.Lockout.DelayBase,
+		MaxDelay:           cfg.Lockout.DelayMax,
+	})
+	mfaService := service.NewMFAService(userRepo, mfaRepo, tokenService, hasher, mfaCipher, loginGuard, auditService, cfg.MFA.Issuer, cfg.MFA.PendingExpiration)
+	passkeyService := service.NewPasskeyService(userRepo, credentialRepo, tokenService, webAuthn, auditService, cfg.WebAuthn.CeremonyExpiration)
+	if cfg.Telegram.BotToken == "" {
+		log.Println("Warning: TELEGRAM_BOT_TOKEN is not set, Telegram linking is disabled")
+	}
+	telegramService := service.NewTelegramService(userRepo, oneTimeTokenRepo, mfaService, auditService, service.TelegramSettings{
+		BotToken:         cfg.Telegram.BotToken,
+		BotUsername:      cfg.Telegram.BotUsername,
+		AuthMaxAge:       cfg.Telegram.AuthMaxAge,
+		LinkCodeTTL:      cfg.Telegram.LinkCodeExpiration,
+		LinkCodesPerHour: cfg.Telegram.LinkCodesPerHour,
+	})
+	authService := service.NewAuthService(userRepo, tokenService, mfaService, loginGuard, hasher, mail, auditService, service.AccountSettings{
+		PublicURL:            cfg.Server.PublicURL,
+		EmailVerificationTTL: cfg.Account.EmailVerificationExpiration,
+		PasswordResetTTL:     cfg.Account.PasswordResetExpiration,
+		PasswordPolicy:       passwordPolicy,
+		DeletionGracePeriod:  cfg.Account.DeletionGracePeriod,
+	})
+
+	roleService := service.NewRoleService(roleRepo, userRepo, adminActionRepo)
+	if err := roleService.BootstrapAdmins(context.Background(), cfg.RBAC.BootstrapAdmins); err != nil {
+		log.Fatalf("Failed to bootstrap admins: %v", err)
+	}
+	if err := roleService.Refresh(context.Background()); err != nil {
+		log.Fatalf("Failed to load roles: %v", err)
+	}
+
+	adminService := service.NewAdminService(userRepo, adminActionRepo, authService)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, auditService, cfg.APIKey.MaxPerUser, cfg.JWT.Audience)
+	introspectionService := service.NewIntrospectionService(tokenService, apiKeyService, userRepo, cfg.Internal.IntrospectionCacheTTL)
+
+	exportService, err := service.NewExportService(exportRepo, userRepo, tokenService, mail, service.ExportSettings{
+		Dir:         cfg.Export.Dir,
+		DownloadURL: strings.TrimSuffix(cfg.Server.APIURL, "/") + "/api/v1/auth/account/export/download",
+		TTL:         cfg.Export.Expiration,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize data exports: %v", err)
+	}
+
+	// Register the data held by this service in user data exports
+	for _, source := range authService.ExportSources() {
+		exportService.Register(source)
+	}
+	exportService.Register(mfaService.ExportSource())
+	exportService.Register(passkeyService.ExportSource())
+	exportService.Register(apiKeyService.ExportSource())
+	exportService.Register(auditService.ExportSource())
+
+	// Purge deleted accounts once their grace period has ended, remove data
+	// exports that were not downloaded in time and expired sessions, and
+	// pick up role changes
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go authService.RunAccountPurge(jobsCtx, cfg.Account.PurgeInterval)
+	go exportService.RunExportCleanup(jobsCtx, cfg.Export.CleanupInterval)
+	go tokenService.RunSessionCleanup(jobsCtx, cfg.JWT.SessionCleanupInterval)
+	go roleService.RunRoleRefresh(jobsCtx, cfg.RBAC.RefreshInterval)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
+	mfaHandler := handlers.NewMFAHandler(mfaService)
+	passkeyHandler := handlers.NewPasskeyHandler(passkeyService)
+	telegramHandler := handlers.NewTelegramHandler(telegramService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	introspectionHandler := handlers.NewIntrospectionHandler(introspectionService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	// Initialize rate limits
+	if !cfg.RateLimit.Enabled {
+		log.Println("Warning: rate limiting is disabled")
+	}
+	limits := &rateLimiters{
+		register: newRateLimiter(cfg, rateLimitStore, "register", cfg.RateLimit.Register),
+		login:    newRateLimiter(cfg, rateLimitStore, "login", cfg.RateLimit.Login),
+		refresh:  newRateLimiter(cfg, rateLimitStore, "refresh", cfg.RateLimit.Refresh),
+		user:     newRateLimiter(cfg, rateLimitStore, "user", cfg.RateLimit.User),
+		apiKey:   newRateLimiter(cfg, rateLimitStore, "api_key", cfg.RateLimit.APIKey),
+		internal: newRateLimiter(cfg, rateLimitStore, "internal", cfg.RateLimit.Internal),
+	}
+
+	// Initialize service-to-service authentication for internal routes
+	var internalAuth gin.HandlersChain
+	if len(cfg.Internal.AllowedCIDRs) > 0 {
+		networks, err := middleware.ParseCIDRs(cfg.Internal.AllowedCIDRs)
+		if err != nil {
+			log.Fatalf("Invalid INTERNAL_ALLOWED_CIDRS: %v", err)
+		}
+		internalAuth = append(internalAuth, middleware.IPAllowlistMiddleware(networks))
+	}
+	if len(cfg.Internal.Services) == 0 {
+		log.Println("Warning: INTERNAL_SERVICE_KEYS is not set, internal routes reject every request")
+	}
+	internalAuth = append(internalAuth, middleware.ServiceAuthMiddleware(cfg.Internal.Services, nonces, cfg.Internal.MaxClockSkew))
+
+	// Only the bot may redeem Telegram link codes, not every internal service
+	if cfg.Telegram.WebhookSecret == "" {
+		log.Println("Warning: TELEGRAM_WEBHOOK_SECRET is not set, Telegram link codes cannot be redeemed")
+	}
+	telegramBotAuth := middleware.TelegramBotMiddleware(cfg.Telegram.WebhookSecret)
 
 	// Setup HTTP server
-	router := setupRouter(authHandler, jwtManager)
+	router := setupRouter(authHandler, mfaHandler, passkeyHandler, telegramHandler, exportHandler, roleHandler, adminHandler, apiKeyHandler, introspectionHandler, auditHandler, jwtManager, tokenService, roleService, apiKeyService, limits, internalAuth, telegramBotAuth)
+
+	// Only believe X-Forwarded-For from our own proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -92,6 +373,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Create a deadline for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -105,11 +387,48 @@ func main() {
 	log.Println("Server exited")
 }
 
+// rateLimiters holds the rate limiting middleware of each route group
+type rateLimiters struct {
+	register gin.HandlerFunc
+	login    gin.HandlerFunc
+	refresh  gin.HandlerFunc
+	user     gin.HandlerFunc
+	apiKey   gin.HandlerFunc
+	internal gin.HandlerFunc
+}
+
+// newRateLimiter creates the rate limiting middleware of a route group, or a
+// pass-through when rate limiting is disabled
+func newRateLimiter(cfg *config.Config, store repository.RateLimitStore, name string, rule config.RateLimitRule) gin.HandlerFunc {
+	if !cfg.RateLimit.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	keyFunc := middleware.KeyByIP
+	switch rule.Key {
+	case "user":
+		keyFunc = middleware.KeyByUser
+	case "api_key":
+		keyFunc = middleware.KeyByAPIKey
+	case "service":
+		keyFunc = middleware.KeyByService
+	}
+
+	limit := models.RateLimit{
+		Requests: rule.Requests,
+		Period:   rule.Period,
+		Burst:    rule.Burst,
+	}
+
+	return middleware.RateLimitMiddleware(store, name, limit, keyFunc)
+}
+
 // setupRouter configures and returns the Gin router
-func setupRouter(authHandler *handlers.AuthHandler, jwtManager *jwt.JWTManager) *gin.Engine {
+func setupRouter(authHandler *handlers.AuthHandler, mfaHandler *handlers.MFAHandler, passkeyHandler *handlers.PasskeyHandler, telegramHandler *handlers.TelegramHandler, exportHandler *handlers.ExportHandler, roleHandler *handlers.RoleHandler, adminHandler *handlers.AdminHandler, apiKeyHandler *handlers.APIKeyHandler, introspectionHandler *handlers.IntrospectionHandler, auditHandler *handlers.AuditHandler, jwtManager *jwt.JWTManager, revocations middleware.RevocationChecker, permissions middleware.PermissionResolver, apiKeys middleware.APIKeyAuthenticator, limits *rateLimiters, internalAuth gin.HandlersChain, telegramBotAuth gin.HandlerFunc) *gin.Engine {
 	router := gin.New()
 
 	// Add middleware
+	router.Use(middleware.RequestContextMiddleware())
 	router.Use(middleware.LoggingMiddleware())
 	router.Use(middleware.RecoveryMiddleware())
 	router.Use(middleware.CORSMiddleware())
@@ -117,32 +436,126 @@ func setupRouter(authHandler *handlers.AuthHandler, jwtManager *jwt.JWTManager)
 	// Health check endpoint (no authentication required)
 	router.GET("/health", authHandler.HealthCheck)
 
+	// Public signing keys for token verification by other services
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
 		// Auth routes (no authentication required)
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/register", limits.register, authHandler.Register)
+			auth.POST("/telegram/webapp", limits.register, telegramHandler.WebAppLogin)
+			auth.POST("/login", limits.login, authHandler.Login)
+			auth.POST("/login/mfa", limits.login, mfaHandler.LoginMFA)
+			auth.POST("/passkeys/login/begin", limits.login, passkeyHandler.BeginLogin)
+			auth.POST("/passkeys/login/finish", limits.login, passkeyHandler.FinishLogin)
+			auth.POST("/refresh", limits.refresh, authHandler.RefreshToken)
+			auth.POST("/verify-email", limits.login, authHandler.VerifyEmail)
+			auth.POST("/password/forgot", limits.login, authHandler.ForgotPassword)
+			auth.POST("/password/reset", limits.login, authHandler.ResetPassword)
+			auth.POST("/password/expired", limits.login, authHandler.ChangeExpiredPassword)
+			auth.POST("/account/restore", limits.login, authHandler.RestoreAccount)
+			auth.GET("/account/export/download", limits.login, exportHandler.DownloadExport)
 
 			// Protected auth routes (authentication required)
 			protected := auth.Group("")
-			protected.Use(middleware.JWTMiddleware(jwtManager))
+			protected.Use(middleware.JWTMiddleware(jwtManager, revocations))
+			protected.Use(limits.user)
+			{
+				protected.POST("/logout", authHandler.Logout)
+				protected.POST("/logout-all", authHandler.LogoutAll)
+				protected.POST("/verify-email/resend", authHandler.ResendVerificationEmail)
+				protected.PUT("/password", authHandler.ChangePassword)
+				protected.POST("/password", authHandler.SetPassword)
+				protected.DELETE("/account", authHandler.DeleteAccount)
+				protected.POST("/telegram", telegramHandler.LinkTelegram)
+				protected.DELETE("/telegram", telegramHandler.UnlinkTelegram)
+				protected.POST("/telegram/link-code", telegramHandler.IssueLinkCode)
+
+				// Two-factor authentication
+				protected.POST("/mfa/totp/enroll", mfaHandler.EnrollTOTP)
+				protected.POST("/mfa/totp/confirm", mfaHandler.ConfirmTOTP)
+				protected.DELETE("/mfa/totp", mfaHandler.DisableTOTP)
+				protected.POST("/mfa/recovery-codes/regenerate", mfaHandler.RegenerateRecoveryCodes)
+
+				// Passkeys
+				protected.GET("/passkeys", passkeyHandler.ListPasskeys)
+				protected.POST("/passkeys/register/begin", passkeyHandler.BeginRegistration)
+				protected.POST("/passkeys/register/finish", passkeyHandler.FinishRegistration)
+				protected.DELETE("/passkeys/:id", passkeyHandler.DeletePasskey)
+
+				// API keys (managing keys requires a login)
+				protected.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+				protected.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+				protected.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+				// Account activity from the audit log
+				protected.GET("/activity", auditHandler.ListActivity)
+			}
+
+			// Routes that also accept API keys with the required scope
+			keyed := auth.Group("")
+			keyed.Use(middleware.JWTOrAPIKeyMiddleware(jwtManager, revocations, apiKeys))
+			keyed.Use(limits.apiKey)
 			{
-				protected.GET("/profile", authHandler.GetProfile)
-				protected.PUT("/profile", authHandler.UpdateProfile)
-				protected.POST("/telegram", authHandler.LinkTelegram)
+				keyed.GET("/profile", middleware.RequireScope(models.ScopeProfileRead), authHandler.GetProfile)
+				keyed.PUT("/profile", middleware.RequireScope(models.ScopeProfileWrite), authHandler.UpdateProfile)
+				keyed.GET("/sessions", middleware.RequireScope(models.ScopeSessionsRead), authHandler.ListSessions)
+				keyed.DELETE("/sessions/:id", middleware.RequireScope(models.ScopeSessionsWrite), authHandler.DeleteSession)
+				keyed.POST("/account/export", middleware.RequireScope(models.ScopeExport), exportHandler.RequestExport)
+				keyed.GET("/account/export/:id", middleware.RequireScope(models.ScopeExport), exportHandler.GetExport)
 			}
 		}
+
+		// Admin routes (staff role and permissions required)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.JWTMiddleware(jwtManager, revocations))
+		admin.Use(limits.user)
+		admin.Use(middleware.RequireRole(models.RoleAdmin, models.RoleSupport))
+		{
+			readUsers := middleware.RequirePermission(permissions, models.PermissionUsersRead)
+			writeUsers := middleware.RequirePermission(permissions, models.PermissionUsersWrite)
+			manageRoles := middleware.RequirePermission(permissions, models.PermissionRolesManage)
+
+			// User administration
+			admin.GET("/users", readUsers, adminHandler.ListUsers)
+			admin.GET("/users/:id", readUsers, adminHandler.GetUser)
+			admin.PUT("/users/:id", writeUsers, adminHandler.UpdateUser)
+			admin.POST("/users/:id/suspend", writeUsers, adminHandler.SuspendUser)
+			admin.POST("/users/:id/unsuspend", writeUsers, adminHandler.UnsuspendUser)
+			admin.POST("/users/:id/password-reset", writeUsers, adminHandler.ForcePasswordReset)
+			admin.DELETE("/users/:id/sessions", writeUsers, adminHandler.RevokeSessions)
+			admin.DELETE("/users/:id/lockout", writeUsers, adminHandler.ClearLockout)
+			admin.GET("/actions", readUsers, adminHandler.ListActions)
+			admin.GET("/audit-events", readUsers, auditHandler.ListEvents)
+
+			// Roles
+			admin.GET("/roles", manageRoles, roleHandler.ListRoles)
+			admin.GET("/users/:id/roles", manageRoles, roleHandler.GetUserRoles)
+			admin.POST("/users/:id/roles", manageRoles, roleHandler.AssignRole)
+			admin.DELETE("/users/:id/roles/:role", manageRoles, roleHandler.RemoveRole)
+		}
 	}
 
-	// Internal routes (for service-to-service communication)
+	// Internal routes (for service-to-service communication, signed with
+	// per-service keys)
 	internal := router.Group("/internal")
+	internal.Use(internalAuth...)
+	internal.Use(limits.internal)
 	{
-		// In production, you might want to add IP restrictions or API key auth here
 		internal.GET("/users/telegram/:telegram_id", authHandler.GetUserByTelegramID)
+		internal.POST("/telegram/link", telegramBotAuth, telegramHandler.RedeemLinkCode)
+	}
+
+	// Token introspection for other services (RFC 7662, signed with
+	// per-service keys like the internal routes)
+	oauth := router.Group("/oauth")
+	oauth.Use(internalAuth...)
+	oauth.Use(limits.internal)
+	{
+		oauth.POST("/introspect", introspectionHandler.Introspect)
 	}
 
 	// Add a catch-all route for undefined endpoints
@@ -157,31 +570,19 @@ func setupRouter(authHandler *handlers.AuthHandler, jwtManager *jwt.JWTManager)
 	return router
 }
 
-// Example middleware for IP restriction (commented out for now)
-/*
-func ipRestrictionMiddleware(allowedIPs []string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-
-		allowed := false
-		for _, ip := range allowedIPs {
-			if ip == clientIP || ip == "0.0.0.0" { // 0.0.0.0 allows all IPs
-				allowed = true
-				break
-			}
-		}
-
-		if !allowed {
-			c.JSON(http.StatusForbidden, gin.H{
-				"success": false,
-				"error":   "Access denied from this IP",
-				"code":    "FORBIDDEN",
-			})
-			c.Abort()
-			return
-		}
+// loadMFAKey decodes MFA_ENCRYPTION_KEY. Outside production a key derived
+// from JWT_SECRET is used when none is configured.
+func loadMFAKey(cfg *config.Config) ([]byte, error) {
+	if cfg.MFA.EncryptionKey == "" {
+		log.Println("Warning: MFA_ENCRYPTION_KEY is not set, deriving it from JWT_SECRET")
+		key := sha256.Sum256([]byte("mfa:" + cfg.JWT.Secret))
+		return key[:], nil
+	}
 
-		c.Next()
+	key, err := base64.StdEncoding.DecodeString(cfg.MFA.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("MFA_ENCRYPTION_KEY must be base64: %w", err)
 	}
+
+	return key, nil
 }
-*/
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// Sink receives a copy of every audit event, in addition to the audit_events
+// table, for shipping to log collectors
+type Sink interface {
+	// Write delivers an event
+	Write(ctx context.Context, event *models.AuditEvent) error
+}
+
+// fileSink implements Sink interface by appending JSON lines to a file
+type fileSink struct {
+	path string
+
+	mu sync.Mutex
+}
+
+// NewFileSink creates a sink that appends every event to path as one line
+// of JSON. The file is reopened for each event, so it may be rotated by
+// moving it away.
+func NewFileSink(path string) (Sink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	return &fileSink{
+		path: path,
+	}, nil
+}
+
+// Write appends the event to the file
+func (s *fileSink) Write(ctx context.Context, event *models.AuditEvent) error {
+	line, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// encode renders an event as a single line of JSON
+func encode(event *models.AuditEvent) ([]byte, error) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	return line, nil
+}
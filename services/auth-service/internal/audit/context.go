@@ -0,0 +1,43 @@
+package audit
+
+import "context"
+
+// contextKey is the type of the context keys of this package
+type contextKey int
+
+const (
+	requestInfoKey contextKey = iota
+	actorKey
+)
+
+// RequestInfo describes the HTTP request an event was caused by
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+// WithRequestInfo returns a context carrying the request information that
+// is recorded with every event written under it
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey, info)
+}
+
+// RequestInfoFromContext returns the request information of ctx, or a zero
+// value outside of a request
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey).(RequestInfo)
+	return info
+}
+
+// WithActor returns a context whose events are attributed to actorID, such
+// as an admin acting on another user's account
+func WithActor(ctx context.Context, actorID int64) context.Context {
+	return context.WithValue(ctx, actorKey, actorID)
+}
+
+// ActorFromContext returns the actor set with WithActor
+func ActorFromContext(ctx context.Context) (int64, bool) {
+	actorID, ok := ctx.Value(actorKey).(int64)
+	return actorID, ok
+}
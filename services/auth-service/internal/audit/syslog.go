@@ -0,0 +1,43 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/syslog"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// syslogSink implements Sink interface by sending JSON messages to syslog
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink creates a sink that sends every event as a JSON message with
+// the auth facility. An empty network and addr use the local syslog daemon.
+func NewSyslogSink(network, addr, tag string) (Sink, error) {
+	writer, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	return &syslogSink{
+		writer: writer,
+	}, nil
+}
+
+// Write sends the event to syslog
+func (s *syslogSink) Write(ctx context.Context, event *models.AuditEvent) error {
+	line, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	if err := s.writer.Info(string(line)); err != nil {
+		return fmt.Errorf("failed to write to syslog: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package audit
+
+import "fmt"
+
+// NewSyslogSink is not available on this platform
+func NewSyslogSink(network, addr, tag string) (Sink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
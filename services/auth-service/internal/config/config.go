@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,16 +12,39 @@ import (
 
 // Config holds all configuration for the auth service
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	Mail      MailConfig
+	Account   AccountConfig
+	Export    ExportConfig
+	MFA       MFAConfig
+	WebAuthn  WebAuthnConfig
+	Telegram  TelegramConfig
+	Lockout   LockoutConfig
+	RateLimit RateLimitConfig
+	Password  PasswordConfig
+	RBAC      RBACConfig
+	Internal  InternalConfig
+	APIKey    APIKeyConfig
+	Audit     AuditConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Port string
 	Env  string
+
+	// PublicURL is the base URL of the client app used in emailed links
+	PublicURL string
+
+	// APIURL is the public base URL of this service, used in download links
+	APIURL string
+
+	// TrustedProxies are the proxies whose X-Forwarded-For headers are
+	// believed when determining client IPs. Empty trusts none.
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database configuration
@@ -35,8 +59,11 @@ type DatabaseConfig struct {
 
 // RedisConfig holds Redis configuration
 type RedisConfig struct {
-	Host string
-	Port string
+	Enabled  bool
+	Host     string
+	Port     string
+	Password string
+	DB       int
 }
 
 // JWTConfig holds JWT configuration
@@ -44,6 +71,188 @@ type JWTConfig struct {
 	Secret           string
 	AccessExpiration time.Duration
 	RefreshExpiration time.Duration
+
+	// PrivateKeyFile enables RS256/EdDSA signing with the given PEM key.
+	// PreviousKeyFiles are still accepted for verification during rotation.
+	PrivateKeyFile   string
+	PreviousKeyFiles []string
+
+	// Audience is put in the aud claim of access tokens and API key claims
+	Audience []string
+
+	// SessionCleanupInterval is how often expired sessions and refresh
+	// tokens are deleted
+	SessionCleanupInterval time.Duration
+}
+
+// MailConfig holds outgoing email configuration
+type MailConfig struct {
+	Driver       string // "smtp" or "file"
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutputDir    string
+}
+
+// AccountConfig holds account lifecycle configuration
+type AccountConfig struct {
+	EmailVerificationExpiration time.Duration
+	PasswordResetExpiration     time.Duration
+
+	// DeletionGracePeriod is how long a deleted account can be restored
+	DeletionGracePeriod time.Duration
+	PurgeInterval       time.Duration
+}
+
+// ExportConfig holds user data export configuration
+type ExportConfig struct {
+	Dir string
+
+	// Expiration is how long a built archive can be downloaded
+	Expiration      time.Duration
+	CleanupInterval time.Duration
+}
+
+// InternalConfig holds authentication of service-to-service calls to /internal
+type InternalConfig struct {
+	// Services maps each calling service to its signing keys. A service may
+	// have several keys while they are rotated.
+	Services map[string][]string
+
+	// MaxClockSkew is how far a request timestamp may be from the server clock
+	MaxClockSkew time.Duration
+
+	// AllowedCIDRs optionally restricts internal routes to these networks
+	AllowedCIDRs []string
+
+	// IntrospectionCacheTTL is how long services may cache a token
+	// introspection response. Zero disables caching.
+	IntrospectionCacheTTL time.Duration
+}
+
+// APIKeyConfig holds user API key configuration
+type APIKeyConfig struct {
+	// MaxPerUser is how many unrevoked keys a user may hold
+	MaxPerUser int
+}
+
+// AuditConfig holds audit log configuration. Events are always stored in
+// the database and additionally streamed to each of Sinks.
+type AuditConfig struct {
+	Sinks []string // "file" and/or "syslog"
+
+	// File is the JSON lines file of the file sink
+	File string
+
+	// SyslogNetwork and SyslogAddr select a remote syslog server; empty
+	// uses the local syslog daemon
+	SyslogNetwork string
+	SyslogAddr    string
+	SyslogTag     string
+}
+
+// RBACConfig holds role-based access control configuration
+type RBACConfig struct {
+	// BootstrapAdmins are granted the admin role at startup
+	BootstrapAdmins []string
+
+	// RefreshInterval is how often role permissions are reloaded
+	RefreshInterval time.Duration
+}
+
+// MFAConfig holds two-factor authentication configuration
+type MFAConfig struct {
+	// EncryptionKey is a base64-encoded 32-byte key for TOTP secrets at rest
+	EncryptionKey     string
+	Issuer            string
+	PendingExpiration time.Duration
+}
+
+// WebAuthnConfig holds passkey relying party configuration
+type WebAuthnConfig struct {
+	RPID               string
+	RPDisplayName      string
+	Origins            []string
+	CeremonyExpiration time.Duration
+}
+
+// TelegramConfig holds Telegram bot configuration
+type TelegramConfig struct {
+	BotToken    string
+	BotUsername string
+
+	// WebhookSecret is the secret token the bot sends with the updates it forwards
+	WebhookSecret string
+
+	// AuthMaxAge is how old a signed Telegram login payload may be
+	AuthMaxAge time.Duration
+
+	LinkCodeExpiration time.Duration
+	LinkCodesPerHour   int
+}
+
+// LockoutConfig holds brute-force protection configuration for logins
+type LockoutConfig struct {
+	MaxAttempts   int
+	MaxIPAttempts int
+	Window        time.Duration
+	Duration      time.Duration
+
+	// DelayBase is the delay after the first failure, doubled for every further one up to DelayMax
+	DelayBase time.Duration
+	DelayMax  time.Duration
+}
+
+// RateLimitConfig holds request rate limits per route group. Register,
+// Login and Refresh run before authentication and can only count by IP;
+// User and APIKey cover the routes behind a login or an API key.
+type RateLimitConfig struct {
+	Enabled  bool
+	Register RateLimitRule
+	Login    RateLimitRule
+	Refresh  RateLimitRule
+	User     RateLimitRule
+	APIKey   RateLimitRule
+	Internal RateLimitRule
+}
+
+// RateLimitRule is a token bucket refilling Requests tokens every Period
+// and holding up to Burst. Key is what requests are counted by: ip, user,
+// api_key or service.
+type RateLimitRule struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+	Key      string
+}
+
+// PasswordConfig holds the policy for new passwords
+type PasswordConfig struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+
+	// MinScore is the lowest accepted strength score from 0 to 4
+	MinScore int
+
+	// BreachedList is a SHA-1 hash list or prebuilt bloom filter of breached passwords
+	BreachedList              string
+	BreachedFalsePositiveRate float64
+
+	// HashAlgorithm is bcrypt or argon2id. Hashes made with another algorithm
+	// or other parameters are upgraded on the next login.
+	HashAlgorithm   string
+	BcryptCost      int
+	Argon2Memory    int
+	Argon2Time      int
+	Argon2Threads   int
+	Pepper          string
+	PreviousPeppers []string
+	HashWorkers     int
 }
 
 // Load loads configuration from environment variables
@@ -54,10 +263,19 @@ func Load() (*Config, error) {
 		fmt.Println("Warning: .env file not found, using environment variables")
 	}
 
+	// Durations that do not parse are rejected below rather than replaced
+	// by a default
+	durations := &envDurations{}
+
 	config := &Config{
 		Server: ServerConfig{
 			Port: getEnv("PORT", "8081"),
 			Env:  getEnv("ENV", "development"),
+
+			PublicURL: getEnv("APP_PUBLIC_URL", "http://localhost:3000"),
+			APIURL:    getEnv("API_PUBLIC_URL", "http://localhost:8081"),
+
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -68,16 +286,128 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
 		Redis: RedisConfig{
-			Host: getEnv("REDIS_HOST", "localhost"),
-			Port: getEnv("REDIS_PORT", "6379"),
+			Enabled:  getEnvAsBool("REDIS_ENABLED", true),
+			Host:     getEnv("REDIS_HOST", "localhost"),
+			Port:     getEnv("REDIS_PORT", "6379"),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
 		JWT: JWTConfig{
 			Secret:           getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
 			AccessExpiration:  parseDuration(getEnv("JWT_ACCESS_EXPIRE", "15m")),
 			RefreshExpiration: parseDuration(getEnv("JWT_REFRESH_EXPIRE", "7d")),
+			PrivateKeyFile:   getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			PreviousKeyFiles: getEnvAsList("JWT_PREVIOUS_KEY_FILES"),
+			Audience:         getEnvAsList("JWT_AUDIENCE"),
+
+			SessionCleanupInterval: durations.get("SESSION_CLEANUP_INTERVAL", time.Hour),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "file"),
+			From:         getEnv("MAIL_FROM", "Rhythmify <no-reply@rhythmify.local>"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			OutputDir:    getEnv("MAIL_OUTPUT_DIR", "./tmp/mail"),
+		},
+		Account: AccountConfig{
+			EmailVerificationExpiration: durations.get("EMAIL_VERIFICATION_EXPIRE", 24*time.Hour),
+			PasswordResetExpiration:     durations.get("PASSWORD_RESET_EXPIRE", 30*time.Minute),
+			DeletionGracePeriod:         durations.get("ACCOUNT_DELETION_GRACE_PERIOD", 720*time.Hour),
+			PurgeInterval:               durations.get("ACCOUNT_PURGE_INTERVAL", time.Hour),
+		},
+		Export: ExportConfig{
+			Dir:             getEnv("EXPORT_DIR", "./tmp/exports"),
+			Expiration:      durations.get("EXPORT_EXPIRE", 72*time.Hour),
+			CleanupInterval: durations.get("EXPORT_CLEANUP_INTERVAL", time.Hour),
+		},
+		Internal: InternalConfig{
+			Services:     getEnvAsServiceKeys("INTERNAL_SERVICE_KEYS"),
+			MaxClockSkew: durations.get("INTERNAL_MAX_CLOCK_SKEW", 5*time.Minute),
+			AllowedCIDRs: getEnvAsList("INTERNAL_ALLOWED_CIDRS"),
+
+			IntrospectionCacheTTL: durations.get("INTROSPECTION_CACHE_TTL", 30*time.Second),
+		},
+		APIKey: APIKeyConfig{
+			MaxPerUser: getEnvAsInt("API_KEYS_MAX_PER_USER", 25),
+		},
+		Audit: AuditConfig{
+			Sinks:         getEnvAsList("AUDIT_SINKS"),
+			File:          getEnv("AUDIT_FILE", "./tmp/audit.jsonl"),
+			SyslogNetwork: getEnv("AUDIT_SYSLOG_NETWORK", ""),
+			SyslogAddr:    getEnv("AUDIT_SYSLOG_ADDR", ""),
+			SyslogTag:     getEnv("AUDIT_SYSLOG_TAG", "rhythmify-auth"),
+		},
+		RBAC: RBACConfig{
+			BootstrapAdmins: getEnvAsList("ADMIN_BOOTSTRAP_EMAILS"),
+			RefreshInterval: durations.get("ROLE_REFRESH_INTERVAL", time.Minute),
+		},
+		MFA: MFAConfig{
+			EncryptionKey:     getEnv("MFA_ENCRYPTION_KEY", ""),
+			Issuer:            getEnv("MFA_ISSUER", "Rhythmify"),
+			PendingExpiration: durations.get("MFA_PENDING_EXPIRE", 5*time.Minute),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:               getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPDisplayName:      getEnv("WEBAUTHN_RP_NAME", "Rhythmify"),
+			Origins:            getEnvAsList("WEBAUTHN_ORIGINS"),
+			CeremonyExpiration: durations.get("WEBAUTHN_CEREMONY_EXPIRE", 5*time.Minute),
+		},
+		Telegram: TelegramConfig{
+			BotToken:           getEnv("TELEGRAM_BOT_TOKEN", ""),
+			BotUsername:        getEnv("TELEGRAM_BOT_USERNAME", ""),
+			WebhookSecret:      getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+			AuthMaxAge:         durations.get("TELEGRAM_AUTH_MAX_AGE", time.Hour),
+			LinkCodeExpiration: durations.get("TELEGRAM_LINK_CODE_EXPIRE", 10*time.Minute),
+			LinkCodesPerHour:   getEnvAsInt("TELEGRAM_LINK_CODES_PER_HOUR", 5),
+		},
+		Lockout: LockoutConfig{
+			MaxAttempts:   getEnvAsInt("LOGIN_MAX_ATTEMPTS", 5),
+			MaxIPAttempts: getEnvAsInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+			Window:        durations.get("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+			Duration:      durations.get("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			DelayBase:     durations.get("LOGIN_DELAY_BASE", 200*time.Millisecond),
+			DelayMax:      durations.get("LOGIN_DELAY_MAX", 5*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:  getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Register: getRateLimitRule("RATE_LIMIT_REGISTER", RateLimitRule{Requests: 5, Period: time.Hour, Burst: 5, Key: "ip"}, durations),
+			Login:    getRateLimitRule("RATE_LIMIT_LOGIN", RateLimitRule{Requests: 10, Period: time.Minute, Burst: 10, Key: "ip"}, durations),
+			Refresh:  getRateLimitRule("RATE_LIMIT_REFRESH", RateLimitRule{Requests: 30, Period: time.Minute, Burst: 30, Key: "ip"}, durations),
+			User:     getRateLimitRule("RATE_LIMIT_USER", RateLimitRule{Requests: 120, Period: time.Minute, Burst: 60, Key: "user"}, durations),
+			APIKey:   getRateLimitRule("RATE_LIMIT_API_KEY", RateLimitRule{Requests: 300, Period: time.Minute, Burst: 100, Key: "api_key"}, durations),
+			Internal: getRateLimitRule("RATE_LIMIT_INTERNAL", RateLimitRule{Requests: 1000, Period: time.Minute, Burst: 200, Key: "service"}, durations),
+		},
+		Password: PasswordConfig{
+			MinLength:                 getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			RequireUppercase:          getEnvAsBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase:          getEnvAsBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:              getEnvAsBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:             getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+			MinScore:                  getEnvAsInt("PASSWORD_MIN_SCORE", 2),
+			BreachedList:              getEnv("PASSWORD_BREACHED_LIST", ""),
+			BreachedFalsePositiveRate: getEnvAsFloat("PASSWORD_BREACHED_FP_RATE", 0.001),
+			HashAlgorithm:             getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:                getEnvAsInt("PASSWORD_BCRYPT_COST", 10),
+			Argon2Memory:              getEnvAsInt("PASSWORD_ARGON2_MEMORY_KIB", 19456),
+			Argon2Time:                getEnvAsInt("PASSWORD_ARGON2_TIME", 2),
+			Argon2Threads:             getEnvAsInt("PASSWORD_ARGON2_THREADS", 1),
+			Pepper:                    getEnv("PASSWORD_PEPPER", ""),
+			PreviousPeppers:           getEnvAsList("PASSWORD_PREVIOUS_PEPPERS"),
+			HashWorkers:               getEnvAsInt("PASSWORD_HASH_WORKERS", 0),
 		},
 	}
 
+	if len(durations.invalid) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %s must be durations such as 90s, 30m or 720h", strings.Join(durations.invalid, ", "))
+	}
+
+	// Passkeys are accepted from the client app unless origins are listed
+	if len(config.WebAuthn.Origins) == 0 {
+		config.WebAuthn.Origins = []string{config.Server.PublicURL}
+	}
+
 	// Validate required configuration
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -88,12 +418,117 @@ func Load() (*Config, error) {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.JWT.Secret == "" {
-		return fmt.Errorf("JWT_SECRET is required")
+	if !c.UsesAsymmetricJWT() {
+		if c.JWT.Secret == "" {
+			return fmt.Errorf("JWT_SECRET is required")
+		}
+
+		if c.JWT.Secret == "your-super-secret-jwt-key-change-in-production" && c.Server.Env == "production" {
+			return fmt.Errorf("JWT_SECRET must be changed in production")
+		}
+	}
+
+	if c.JWT.SessionCleanupInterval <= 0 {
+		return fmt.Errorf("SESSION_CLEANUP_INTERVAL must be positive")
+	}
+
+	if c.Mail.Driver != "smtp" && c.Mail.Driver != "file" {
+		return fmt.Errorf("MAIL_DRIVER must be either smtp or file")
+	}
+
+	if c.Mail.Driver == "file" && c.Server.Env == "production" {
+		return fmt.Errorf("MAIL_DRIVER=file is not allowed in production")
+	}
+
+	if c.MFA.EncryptionKey == "" && c.Server.Env == "production" {
+		return fmt.Errorf("MFA_ENCRYPTION_KEY is required in production")
+	}
+
+	if c.Password.MinScore < 0 || c.Password.MinScore > 4 {
+		return fmt.Errorf("PASSWORD_MIN_SCORE must be between 0 and 4")
+	}
+
+	if c.Password.HashAlgorithm != "bcrypt" && c.Password.HashAlgorithm != "argon2id" {
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be either bcrypt or argon2id")
+	}
+
+	if c.Password.BcryptCost < 4 || c.Password.BcryptCost > 31 {
+		return fmt.Errorf("PASSWORD_BCRYPT_COST must be between 4 and 31")
+	}
+
+	if c.Password.Argon2Memory < 8 || c.Password.Argon2Time < 1 || c.Password.Argon2Threads < 1 || c.Password.Argon2Threads > 255 {
+		return fmt.Errorf("PASSWORD_ARGON2_MEMORY_KIB, PASSWORD_ARGON2_TIME and PASSWORD_ARGON2_THREADS must be positive")
+	}
+
+	if c.Password.BreachedFalsePositiveRate <= 0 || c.Password.BreachedFalsePositiveRate >= 1 {
+		return fmt.Errorf("PASSWORD_BREACHED_FP_RATE must be between 0 and 1")
+	}
+
+	for name, keys := range c.Internal.Services {
+		if name == "" {
+			return fmt.Errorf("INTERNAL_SERVICE_KEYS entries must be name:key pairs")
+		}
+		for _, key := range keys {
+			if len(key) < 32 {
+				return fmt.Errorf("INTERNAL_SERVICE_KEYS key of %s must be at least 32 characters", name)
+			}
+		}
 	}
 
-	if c.JWT.Secret == "your-super-secret-jwt-key-change-in-production" && c.Server.Env == "production" {
-		return fmt.Errorf("JWT_SECRET must be changed in production")
+	if c.Internal.MaxClockSkew <= 0 {
+		return fmt.Errorf("INTERNAL_MAX_CLOCK_SKEW must be positive")
+	}
+
+	if c.Internal.IntrospectionCacheTTL < 0 {
+		return fmt.Errorf("INTROSPECTION_CACHE_TTL must not be negative")
+	}
+
+	if c.APIKey.MaxPerUser < 1 {
+		return fmt.Errorf("API_KEYS_MAX_PER_USER must be positive")
+	}
+
+	if c.Lockout.MaxAttempts < 1 {
+		return fmt.Errorf("LOGIN_MAX_ATTEMPTS must be positive")
+	}
+
+	if c.Lockout.MaxIPAttempts < 1 {
+		return fmt.Errorf("LOGIN_IP_MAX_ATTEMPTS must be positive")
+	}
+
+	for _, sink := range c.Audit.Sinks {
+		if sink != "file" && sink != "syslog" {
+			return fmt.Errorf("AUDIT_SINKS must only contain file or syslog")
+		}
+	}
+
+	if c.Audit.SyslogNetwork != "" && c.Audit.SyslogAddr == "" {
+		return fmt.Errorf("AUDIT_SYSLOG_ADDR is required with AUDIT_SYSLOG_NETWORK")
+	}
+
+	// A key is only usable where the identity it counts by is known when
+	// the limiter runs; anything else would quietly count by IP
+	for name, limit := range map[string]struct {
+		rule RateLimitRule
+		keys []string
+	}{
+		"RATE_LIMIT_REGISTER": {c.RateLimit.Register, []string{"ip"}},
+		"RATE_LIMIT_LOGIN":    {c.RateLimit.Login, []string{"ip"}},
+		"RATE_LIMIT_REFRESH":  {c.RateLimit.Refresh, []string{"ip"}},
+		"RATE_LIMIT_USER":     {c.RateLimit.User, []string{"ip", "user"}},
+		"RATE_LIMIT_API_KEY":  {c.RateLimit.APIKey, []string{"ip", "user", "api_key"}},
+		"RATE_LIMIT_INTERNAL": {c.RateLimit.Internal, []string{"ip", "service"}},
+	} {
+		rule := limit.rule
+		if rule.Requests <= 0 || rule.Period <= 0 || rule.Burst <= 0 {
+			return fmt.Errorf("%s_REQUESTS, %s_PERIOD and %s_BURST must be positive", name, name, name)
+		}
+		allowed := false
+		for _, key := range limit.keys {
+			allowed = allowed || rule.Key == key
+		}
+		if !allowed {
+			return fmt.Errorf("%s_KEY must be one of %s", name, strings.Join(limit.keys, ", "))
+		}
 	}
 
 	if c.Database.Host == "" {
@@ -133,6 +568,11 @@ func (c *Config) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%s", c.Redis.Host, c.Redis.Port)
 }
 
+// UsesAsymmetricJWT returns true if tokens are signed with a private key instead of JWT_SECRET
+func (c *Config) UsesAsymmetricJWT() bool {
+	return c.JWT.PrivateKeyFile != ""
+}
+
 // IsProduction returns true if running in production environment
 func (c *Config) IsProduction() bool {
 	return c.Server.Env == "production"
@@ -168,6 +608,27 @@ func parseDuration(durationStr string) time.Duration {
 	return duration
 }
 
+// envDurations reads duration settings and records the keys of values that
+// are not valid durations
+type envDurations struct {
+	invalid []string
+}
+
+// get gets an environment variable as a duration with fallback
+func (d *envDurations) get(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		d.invalid = append(d.invalid, key)
+		return fallback
+	}
+	return duration
+}
+
 // getEnvAsInt gets an environment variable as integer with fallback
 func getEnvAsInt(key string, fallback int) int {
 	if value := os.Getenv(key); value != "" {
@@ -176,4 +637,58 @@ func getEnvAsInt(key string, fallback int) int {
 		}
 	}
 	return fallback
+}
+
+// getEnvAsFloat gets an environment variable as float with fallback
+func getEnvAsFloat(key string, fallback float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return fallback
+}
+
+// getEnvAsBool gets an environment variable as boolean with fallback
+func getEnvAsBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+// getRateLimitRule reads a rate limit rule from PREFIX_REQUESTS, PREFIX_PERIOD,
+// PREFIX_BURST and PREFIX_KEY
+func getRateLimitRule(prefix string, fallback RateLimitRule, durations *envDurations) RateLimitRule {
+	return RateLimitRule{
+		Requests: getEnvAsInt(prefix+"_REQUESTS", fallback.Requests),
+		Period:   durations.get(prefix+"_PERIOD", fallback.Period),
+		Burst:    getEnvAsInt(prefix+"_BURST", fallback.Burst),
+		Key:      getEnv(prefix+"_KEY", fallback.Key),
+	}
+}
+
+// getEnvAsServiceKeys gets a comma-separated list of name:key pairs as a map
+// of service names to keys. A name may appear more than once during key rotation.
+func getEnvAsServiceKeys(key string) map[string][]string {
+	services := make(map[string][]string)
+	for _, entry := range getEnvAsList(key) {
+		name, secret, _ := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		services[name] = append(services[name], strings.TrimSpace(secret))
+	}
+	return services
+}
+
+// getEnvAsList gets a comma-separated environment variable as a list
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
 }
\ No newline at end of file
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadWithEnv loads the configuration with the given environment on top of
+// the defaults
+func loadWithEnv(t *testing.T, env map[string]string) (*Config, error) {
+	t.Helper()
+
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return Load()
+}
+
+func TestLoadDefaults(t *testing.T) {
+	if _, err := loadWithEnv(t, nil); err != nil {
+		t.Fatalf("Load with defaults failed: %v", err)
+	}
+}
+
+func TestValidateRateLimitKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "login by ip", env: map[string]string{"RATE_LIMIT_LOGIN_KEY": "ip"}},
+		{name: "login by user", env: map[string]string{"RATE_LIMIT_LOGIN_KEY": "user"}, wantErr: "RATE_LIMIT_LOGIN_KEY"},
+		{name: "register by api key", env: map[string]string{"RATE_LIMIT_REGISTER_KEY": "api_key"}, wantErr: "RATE_LIMIT_REGISTER_KEY"},
+		{name: "refresh by user", env: map[string]string{"RATE_LIMIT_REFRESH_KEY": "user"}, wantErr: "RATE_LIMIT_REFRESH_KEY"},
+		{name: "logged in routes by user", env: map[string]string{"RATE_LIMIT_USER_KEY": "user"}},
+		{name: "logged in routes by api key", env: map[string]string{"RATE_LIMIT_USER_KEY": "api_key"}, wantErr: "RATE_LIMIT_USER_KEY"},
+		{name: "api key routes by api key", env: map[string]string{"RATE_LIMIT_API_KEY_KEY": "api_key"}},
+		{name: "api key routes by service", env: map[string]string{"RATE_LIMIT_API_KEY_KEY": "service"}, wantErr: "RATE_LIMIT_API_KEY_KEY"},
+		{name: "internal by user", env: map[string]string{"RATE_LIMIT_INTERNAL_KEY": "user"}, wantErr: "RATE_LIMIT_INTERNAL_KEY"},
+		{name: "zero burst", env: map[string]string{"RATE_LIMIT_USER_BURST": "0"}, wantErr: "RATE_LIMIT_USER_BURST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadWithEnv(t, tt.env)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want one naming %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDurations(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    time.Duration
+		wantErr string
+	}{
+		{name: "default", want: 720 * time.Hour},
+		{name: "hours", env: map[string]string{"ACCOUNT_DELETION_GRACE_PERIOD": "168h"}, want: 168 * time.Hour},
+		{name: "days", env: map[string]string{"ACCOUNT_DELETION_GRACE_PERIOD": "30d"}, wantErr: "ACCOUNT_DELETION_GRACE_PERIOD"},
+		{name: "bare number", env: map[string]string{"ACCOUNT_DELETION_GRACE_PERIOD": "30"}, wantErr: "ACCOUNT_DELETION_GRACE_PERIOD"},
+		{name: "rate limit period", env: map[string]string{"RATE_LIMIT_LOGIN_PERIOD": "1 minute"}, wantErr: "RATE_LIMIT_LOGIN_PERIOD"},
+		{
+			name:    "every invalid setting is named",
+			env:     map[string]string{"EXPORT_EXPIRE": "3d", "LOGIN_DELAY_MAX": "5"},
+			wantErr: "EXPORT_EXPIRE, LOGIN_DELAY_MAX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWithEnv(t, tt.env)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load error = %v, want one naming %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if cfg.Account.DeletionGracePeriod != tt.want {
+				t.Errorf("DeletionGracePeriod = %v, want %v", cfg.Account.DeletionGracePeriod, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLockoutAttempts(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "one attempt", env: map[string]string{"LOGIN_MAX_ATTEMPTS": "1", "LOGIN_IP_MAX_ATTEMPTS": "1"}},
+		{name: "no attempts per account", env: map[string]string{"LOGIN_MAX_ATTEMPTS": "0"}, wantErr: "LOGIN_MAX_ATTEMPTS must be positive"},
+		{name: "negative attempts per account", env: map[string]string{"LOGIN_MAX_ATTEMPTS": "-1"}, wantErr: "LOGIN_MAX_ATTEMPTS must be positive"},
+		{name: "no attempts per IP", env: map[string]string{"LOGIN_IP_MAX_ATTEMPTS": "0"}, wantErr: "LOGIN_IP_MAX_ATTEMPTS must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadWithEnv(t, tt.env)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
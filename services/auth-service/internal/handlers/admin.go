@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// AdminHandler handles user administration HTTP requests
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// userIDParam is the user ID path parameter of admin routes
+type userIDParam struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+// ListUsers handles searching users
+// @Summary List users
+// @Description Search users by email or username prefix, Telegram ID and creation time, newest first. Pass next_cursor as cursor to get the next page.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param email query string false "Email prefix"
+// @Param username query string false "Username prefix"
+// @Param telegram_id query int false "Telegram ID"
+// @Param created_after query string false "Created at or after (RFC 3339)"
+// @Param created_before query string false "Created before (RFC 3339)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 200)"
+// @Success 200 {object} response.Response{data=models.UserListResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var filter models.UserFilter
+
+	// Bind and validate query
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query: "+err.Error())
+		return
+	}
+
+	users, err := h.adminService.ListUsers(c.Request.Context(), &filter)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			response.BadRequest(c, "Invalid cursor")
+			return
+		}
+		response.InternalServerError(c, "Failed to list users")
+		return
+	}
+
+	response.OK(c, "Users retrieved successfully", users)
+}
+
+// GetUser handles fetching a user
+// @Summary Get user
+// @Description Get a user by ID
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=models.AdminUserResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	var uri userIDParam
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	user, err := h.adminService.GetUser(c.Request.Context(), uri.ID)
+	if err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	response.OK(c, "User retrieved successfully", user)
+}
+
+// UpdateUser handles editing a user's profile
+// @Summary Update user
+// @Description Edit the email address and username of a user. A changed email address has to be verified again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.UpdateUserRequest true "Profile fields"
+// @Success 200 {object} response.Response{data=models.AdminUserResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id} [put]
+func (h *AdminHandler) UpdateUser(c *gin.Context) {
+	adminID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var uri userIDParam
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateUserRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	user, err := h.adminService.UpdateUser(c.Request.Context(), adminID, uri.ID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			response.NotFound(c, "User not found")
+		case "email already exists", "username already exists":
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to update user")
+		}
+		return
+	}
+
+	response.OK(c, "User updated successfully", user)
+}
+
+// SuspendUser handles suspending a user
+// @Summary Suspend user
+// @Description Block a user from logging in and end all of their sessions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.SuspendUserRequest true "Reason"
+// @Success 200 {object} response.Response{data=models.AdminUserResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/suspend [post]
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	adminID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var uri userIDParam
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req models.SuspendUserRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	user, err := h.adminService.SuspendUser(c.Request.Context(), adminID, uri.ID, req.Reason)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			response.NotFound(c, "User not found")
+		case "cannot suspend own account":
+			response.BadRequest(c, "You cannot suspend your own account")
+		default:
+			response.InternalServerError(c, "Failed to suspend user")
+		}
+		return
+	}
+
+	response.OK(c, "User suspended successfully", user)
+}
+
+// UnsuspendUser handles lifting the suspension of a user
+// @Summary Unsuspend user
+// @Description Allow a suspended user to log in again
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=models.AdminUserResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/unsuspend [post]
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	adminID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var uri userIDParam
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	user, err := h.adminService.UnsuspendUser(c.Request.Context(), adminID, uri.ID)
+	if err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	response.OK(c, "User unsuspended successfully", user)
+}
+
+// ForcePasswordReset handles requiring a user to change their password
+// @Summary Force password reset
+// @Description End all sessions of a user and require a new password at their next login
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/password-reset [post]
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	adminID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var uri userIDParam
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.adminService.ForcePasswordReset(c.Request.Context(), adminID, uri.ID); err != nil {
+		if err.Error() == "user not found" {
+			response.NotFound(c, "User not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to force password reset")
+		return
+	}
+
+	response.OK(c, "Password reset required", nil)
+}
+
+// RevokeSessions handles ending all sessions of a user
+// @Summary Revoke user sessions
+// @Description End every session of a user and revoke their tokens
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/sessions [delete]
+func (h *AdminHandler) RevokeSessions(c *gin.Context) {
+	adminID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var uri userIDParam
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.adminService.RevokeSessions(c.Request.Context(), adminID, uri.ID); err != nil {
+		if err.Error() == "user not found" {
+			response.NotFound(c, "User not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+
+	response.OK(c, "Sessions revoked successfully", nil)
+}
+
+// ClearLockout handles lifting the login lockout of a user
+// @Summary Clear user lockout
+// @Description Clear failed login attempts and the lockout of a user's account
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/lockout [delete]
+func (h *AdminHandler) ClearLockout(c *gin.Context) {
+	adminID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var uri userIDParam
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.adminService.ClearLockout(c.Request.Context(), adminID, uri.ID); err != nil {
+		switch err.Error() {
+		case "user not found":
+			response.NotFound(c, "User not found")
+		case "user has no email address":
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to clear lockout")
+		}
+		return
+	}
+
+	response.OK(c, "Lockout cleared successfully", nil)
+}
+
+// ListActions handles listing the admin action log
+// @Summary List admin actions
+// @Description List recorded admin actions, newest first, optionally by admin or target user
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param admin_id query int false "Acting admin ID"
+// @Param target_user_id query int false "Target user ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 200)"
+// @Success 200 {object} response.Response{data=models.AdminActionListResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/actions [get]
+func (h *AdminHandler) ListActions(c *gin.Context) {
+	var filter models.AdminActionFilter
+
+	// Bind and validate query
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query: "+err.Error())
+		return
+	}
+
+	actions, err := h.adminService.ListActions(c.Request.Context(), &filter)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			response.BadRequest(c, "Invalid cursor")
+			return
+		}
+		response.InternalServerError(c, "Failed to list admin actions")
+		return
+	}
+
+	response.OK(c, "Admin actions retrieved successfully", actions)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// APIKeyHandler handles API key HTTP requests
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey handles creating an API key for the current user
+// @Summary Create API key
+// @Description Create a named API key with scopes and an optional expiry. The key is only returned in this response; send it as "Authorization: Bearer rhy_...".
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateAPIKeyRequest true "Name, scopes and expiry"
+// @Success 201 {object} response.Response{data=models.CreateAPIKeyResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "expiry must be in the future":
+			response.BadRequest(c, "Expiry must be in the future")
+		case "too many api keys":
+			response.ErrorResponseWithCode(c, http.StatusConflict, "API key limit reached, revoke an unused key first", "API_KEY_LIMIT_REACHED")
+		default:
+			response.InternalServerError(c, "Failed to create API key")
+		}
+		return
+	}
+
+	response.Created(c, "API key created successfully", key)
+}
+
+// ListAPIKeys handles listing the current user's API keys
+// @Summary List API keys
+// @Description List the unrevoked API keys of the current user with their prefix, scopes and last use
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to list API keys")
+		return
+	}
+
+	response.OK(c, "API keys retrieved successfully", gin.H{"api_keys": keys})
+}
+
+// RevokeAPIKey handles revoking one of the current user's API keys
+// @Summary Revoke API key
+// @Description Revoke an API key so it can no longer be used
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), userID, req.ID); err != nil {
+		response.NotFound(c, "API key not found")
+		return
+	}
+
+	response.OK(c, "API key revoked successfully", nil)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListActivity handles listing the current user's account activity
+// @Summary List account activity
+// @Description List the audit events on the current user's account, such as logins, failed logins, password and profile changes, newest first. Pass next_cursor as cursor to get the next page.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param event_type query string false "Event type, e.g. login.failed"
+// @Param created_after query string false "Created at or after (RFC 3339)"
+// @Param created_before query string false "Created before (RFC 3339)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 200)"
+// @Success 200 {object} response.Response{data=models.AuditEventListResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/activity [get]
+func (h *AuditHandler) ListActivity(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var filter models.ActivityFilter
+
+	// Bind and validate query
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query: "+err.Error())
+		return
+	}
+
+	events, err := h.auditService.ListActivity(c.Request.Context(), userID, &filter)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			response.BadRequest(c, "Invalid cursor")
+			return
+		}
+		response.InternalServerError(c, "Failed to list activity")
+		return
+	}
+
+	response.OK(c, "Activity retrieved successfully", events)
+}
+
+// ListEvents handles listing the audit log
+// @Summary List audit events
+// @Description List audit events of all users, newest first, filtered by actor, target user, event type, IP address, request ID and time
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query int false "ID of the user or admin who caused the event"
+// @Param target_user_id query int false "ID of the affected user"
+// @Param event_type query string false "Event type, e.g. login.failed"
+// @Param ip_address query string false "Client IP address"
+// @Param request_id query string false "Request ID (X-Request-ID)"
+// @Param created_after query string false "Created at or after (RFC 3339)"
+// @Param created_before query string false "Created before (RFC 3339)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 200)"
+// @Success 200 {object} response.Response{data=models.AuditEventListResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/audit-events [get]
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	var filter models.AuditEventFilter
+
+	// Bind and validate query
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query: "+err.Error())
+		return
+	}
+
+	events, err := h.auditService.ListEvents(c.Request.Context(), &filter)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			response.BadRequest(c, "Invalid cursor")
+			return
+		}
+		response.InternalServerError(c, "Failed to list audit events")
+		return
+	}
+
+	response.OK(c, "Audit events retrieved successfully", events)
+}
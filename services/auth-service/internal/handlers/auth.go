@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 
 	"rhythmify/services/auth-service/internal/middleware"
 	"rhythmify/services/auth-service/internal/models"
 	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/jwt"
 	"rhythmify/shared/response"
 )
 
@@ -43,12 +49,15 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Register user
-	user, tokens, err := h.authService.Register(c.Request.Context(), &req)
+	user, tokens, err := h.authService.Register(c.Request.Context(), &req, clientInfo(c, req.DeviceName))
 	if err != nil {
 		if err.Error() == "email already exists" || err.Error() == "username already exists" {
 			response.Conflict(c, err.Error())
 			return
 		}
+		if respondWeakPassword(c, err) {
+			return
+		}
 		response.InternalServerError(c, "Failed to register user")
 		return
 	}
@@ -72,6 +81,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -84,23 +94,24 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Authenticate user
-	user, tokens, err := h.authService.Login(c.Request.Context(), &req)
+	result, err := h.authService.Login(c.Request.Context(), &req, clientInfo(c, req.DeviceName))
 	if err != nil {
+		if respondLocked(c, err) || respondSuspended(c, err) {
+			return
+		}
 		if err.Error() == "invalid credentials" {
 			response.Unauthorized(c, "Invalid email or password")
 			return
 		}
+		if err.Error() == "password change required" {
+			response.ErrorResponseWithCode(c, http.StatusForbidden, "Password must be changed before logging in", "PASSWORD_CHANGE_REQUIRED")
+			return
+		}
 		response.InternalServerError(c, "Failed to login")
 		return
 	}
 
-	// Return success response
-	responseData := gin.H{
-		"user":   user,
-		"tokens": tokens,
-	}
-
-	response.OK(c, "Login successful", responseData)
+	respondLogin(c, result, "Login successful")
 }
 
 // RefreshToken handles token refresh
@@ -129,6 +140,9 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// Refresh tokens
 	tokens, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
+		if respondSuspended(c, err) {
+			return
+		}
 		response.Unauthorized(c, "Invalid or expired refresh token")
 		return
 	}
@@ -137,52 +151,112 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	response.OK(c, "Token refreshed successfully", gin.H{"tokens": tokens})
 }
 
-// GetProfile handles getting user profile
-// @Summary Get user profile
-// @Description Get current user profile information
+// Logout handles logging out of the current session
+// @Summary Logout
+// @Description Revoke the current access token and its refresh token
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} response.Response
 // @Failure 401 {object} response.ErrorResponse
-// @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/v1/auth/profile [get]
-func (h *AuthHandler) GetProfile(c *gin.Context) {
-	// Get user ID from context (set by JWT middleware)
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	// Get token claims from context (set by JWT middleware)
+	claims, exists := middleware.GetUserClaimsFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Revoke tokens
+	if err := h.authService.Logout(c.Request.Context(), claims); err != nil {
+		response.InternalServerError(c, "Failed to logout")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Logged out successfully", nil)
+}
+
+// LogoutAll handles logging out of every session
+// @Summary Logout everywhere
+// @Description Revoke every access and refresh token of the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	// Get user ID from context
 	userID, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
 		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	// Get user profile
-	user, err := h.authService.GetProfile(c.Request.Context(), userID)
+	// Revoke all tokens
+	if err := h.authService.LogoutAll(c.Request.Context(), userID); err != nil {
+		response.InternalServerError(c, "Failed to logout")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Logged out from all sessions successfully", nil)
+}
+
+// VerifyEmail handles confirming an email address
+// @Summary Verify email
+// @Description Confirm the email address using the emailed verification token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	// Verify email
+	user, err := h.authService.VerifyEmail(c.Request.Context(), req.Token)
 	if err != nil {
-		response.NotFound(c, "User not found")
+		if err.Error() == "invalid or expired token" {
+			response.BadRequest(c, "Invalid or expired verification token")
+			return
+		}
+		response.InternalServerError(c, "Failed to verify email")
 		return
 	}
 
 	// Return success response
-	response.OK(c, "Profile retrieved successfully", gin.H{"user": user})
+	response.OK(c, "Email verified successfully", gin.H{"user": user})
 }
 
-// UpdateProfile handles updating user profile
-// @Summary Update user profile
-// @Description Update current user profile information
+// ResendVerificationEmail handles sending a new verification email
+// @Summary Resend verification email
+// @Description Send a new email verification link to the current user
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body models.UpdateUserRequest true "User update data"
 // @Success 200 {object} response.Response
-// @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/v1/auth/profile [put]
-func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+// @Router /api/v1/auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
@@ -190,7 +264,37 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	var req models.UpdateUserRequest
+	// Send verification email
+	err := h.authService.ResendVerificationEmail(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "email already verified" {
+			response.Conflict(c, err.Error())
+			return
+		}
+		if err.Error() == "no email address set" {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "Failed to send verification email")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Verification email sent", nil)
+}
+
+// ForgotPassword handles requesting a password reset email
+// @Summary Forgot password
+// @Description Send a password reset link if the email is registered
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/v1/auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -198,36 +302,117 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Update user profile
-	user, err := h.authService.UpdateProfile(c.Request.Context(), userID, &req)
+	// Request reset; the response never reveals whether the email exists
+	h.authService.ForgotPassword(c.Request.Context(), req.Email)
+
+	// Return success response
+	response.OK(c, "If an account with that email exists, a password reset link has been sent", nil)
+}
+
+// ResetPassword handles setting a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password using the emailed reset token and log out all sessions
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	// Reset password
+	err := h.authService.ResetPassword(c.Request.Context(), &req)
 	if err != nil {
-		if err.Error() == "email already exists" || err.Error() == "username already exists" {
-			response.Conflict(c, err.Error())
+		if err.Error() == "invalid or expired token" {
+			response.BadRequest(c, "Invalid or expired reset token")
 			return
 		}
-		response.InternalServerError(c, "Failed to update profile")
+		if respondWeakPassword(c, err) {
+			return
+		}
+		response.InternalServerError(c, "Failed to reset password")
 		return
 	}
 
 	// Return success response
-	response.OK(c, "Profile updated successfully", gin.H{"user": user})
+	response.OK(c, "Password reset successfully", nil)
+}
+
+// ChangePassword handles changing the current user's password
+// @Summary Change password
+// @Description Change the password, log out every other session and return new tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	// Get token claims from context
+	claims, exists := middleware.GetUserClaimsFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.ChangePasswordRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	// Change password
+	tokens, err := h.authService.ChangePassword(c.Request.Context(), claims, &req)
+	if err != nil {
+		if err.Error() == "current password is incorrect" {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		if respondWeakPassword(c, err) {
+			return
+		}
+		if err.Error() == "new password must differ from the current password" {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "Failed to change password")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Password changed successfully", gin.H{"tokens": tokens})
 }
 
-// LinkTelegram handles linking Telegram account
-// @Summary Link Telegram account
-// @Description Link a Telegram account to the current user
+// SetPassword handles adding a password to an account that has none
+// @Summary Set password
+// @Description Add a password to an account created without one, such as through Telegram
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body models.LinkTelegramRequest true "Telegram link data"
+// @Param request body models.SetPasswordRequest true "New password"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/v1/auth/telegram [post]
-func (h *AuthHandler) LinkTelegram(c *gin.Context) {
+// @Router /api/v1/auth/password [post]
+func (h *AuthHandler) SetPassword(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
@@ -235,7 +420,7 @@ func (h *AuthHandler) LinkTelegram(c *gin.Context) {
 		return
 	}
 
-	var req models.LinkTelegramRequest
+	var req models.SetPasswordRequest
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -243,19 +428,304 @@ func (h *AuthHandler) LinkTelegram(c *gin.Context) {
 		return
 	}
 
-	// Link Telegram account
-	err := h.authService.LinkTelegram(c.Request.Context(), userID, &req)
+	if err := h.authService.SetPassword(c.Request.Context(), userID, &req); err != nil {
+		if err.Error() == "password already set" {
+			response.Conflict(c, "Password is already set, use change password instead")
+			return
+		}
+		if respondWeakPassword(c, err) {
+			return
+		}
+		response.InternalServerError(c, "Failed to set password")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Password set successfully", nil)
+}
+
+// ChangeExpiredPassword handles the forced password change of a flagged account
+// @Summary Change expired password
+// @Description Change a password that must be changed before logging in, and log in
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangeExpiredPasswordRequest true "Credentials and new password"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/password/expired [post]
+func (h *AuthHandler) ChangeExpiredPassword(c *gin.Context) {
+	var req models.ChangeExpiredPasswordRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	// Change password and log in
+	result, err := h.authService.ChangeExpiredPassword(c.Request.Context(), &req, clientInfo(c, req.DeviceName))
 	if err != nil {
-		if err.Error() == "telegram account already linked to another user" {
+		if respondLocked(c, err) || respondSuspended(c, err) {
+			return
+		}
+		if err.Error() == "invalid credentials" {
+			response.Unauthorized(c, "Invalid email or password")
+			return
+		}
+		if respondWeakPassword(c, err) {
+			return
+		}
+		if err.Error() == "new password must differ from the current password" {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if err.Error() == "password change not required" {
+			response.ErrorResponseWithCode(c, http.StatusForbidden, "Password does not need to be changed, log in instead", "PASSWORD_CHANGE_NOT_REQUIRED")
+			return
+		}
+		response.InternalServerError(c, "Failed to change password")
+		return
+	}
+
+	respondLogin(c, result, "Password changed successfully")
+}
+
+// DeleteAccount handles deleting the current user's account
+// @Summary Delete account
+// @Description Delete the current user's account after re-authentication. The account can be restored during a grace period, after which it is purged.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.DeleteAccountRequest true "Password and two-factor code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/account [delete]
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	claims, exists := middleware.GetUserClaimsFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.DeleteAccountRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	deletion, err := h.authService.DeleteAccount(c.Request.Context(), claims, &req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid credentials":
+			response.Unauthorized(c, "Invalid password")
+		case "invalid code":
+			response.BadRequest(c, err.Error())
+		case "recent login required":
+			response.ErrorResponseWithCode(c, http.StatusForbidden, "Log in again to delete your account", "REAUTHENTICATION_REQUIRED")
+		default:
+			response.InternalServerError(c, "Failed to delete account")
+		}
+		return
+	}
+
+	response.OK(c, "Account deleted", deletion)
+}
+
+// RestoreAccount handles undoing an account deletion
+// @Summary Restore account
+// @Description Restore a deleted account with the restore token before the grace period ends
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RestoreAccountRequest true "Restore token"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/account/restore [post]
+func (h *AuthHandler) RestoreAccount(c *gin.Context) {
+	var req models.RestoreAccountRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	if err := h.authService.RestoreAccount(c.Request.Context(), &req); err != nil {
+		switch err.Error() {
+		case "invalid or expired token", "account can no longer be restored":
+			response.BadRequest(c, err.Error())
+		case "telegram account already linked to another user":
+			response.Conflict(c, "Telegram account already linked to another user, unlink it there before restoring")
+		default:
+			response.InternalServerError(c, "Failed to restore account")
+		}
+		return
+	}
+
+	response.OK(c, "Account restored, you can log in again", nil)
+}
+
+// ListSessions handles listing the current user's sessions
+// @Summary List sessions
+// @Description List the devices the current user is logged in on
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	// Get token claims from context
+	claims, exists := middleware.GetUserClaimsFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// List sessions
+	sessions, err := h.authService.ListSessions(c.Request.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to list sessions")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Sessions retrieved successfully", gin.H{"sessions": sessions})
+}
+
+// DeleteSession handles ending one of the current user's sessions
+// @Summary Delete session
+// @Description Log out a single device of the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) DeleteSession(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Delete session
+	err := h.authService.DeleteSession(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		if err.Error() == "session not found" {
+			response.NotFound(c, "Session not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to delete session")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Session deleted successfully", nil)
+}
+
+// GetProfile handles getting user profile
+// @Summary Get user profile
+// @Description Get current user profile information
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/profile [get]
+func (h *AuthHandler) GetProfile(c *gin.Context) {
+	// Get user ID from context (set by JWT middleware)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Get user profile
+	user, err := h.authService.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Profile retrieved successfully", gin.H{"user": user})
+}
+
+// UpdateProfile handles updating user profile
+// @Summary Update user profile
+// @Description Update current user profile information
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateUserRequest true "User update data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/profile [put]
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	// Get user claims from context
+	claims, exists := middleware.GetUserClaimsFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.UpdateUserRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	// The email address is where password resets go, so a leaked API key
+	// must not be able to change it
+	if req.Email != nil && claims.Type == jwt.APIKeyToken {
+		response.ErrorResponseWithCode(c, http.StatusForbidden, "API keys cannot change the email address", "API_KEY_NOT_ALLOWED")
+		return
+	}
+
+	// Update user profile
+	user, err := h.authService.UpdateProfile(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		if err.Error() == "email already exists" || err.Error() == "username already exists" {
 			response.Conflict(c, err.Error())
 			return
 		}
-		response.InternalServerError(c, "Failed to link Telegram account")
+		response.InternalServerError(c, "Failed to update profile")
 		return
 	}
 
 	// Return success response
-	response.OK(c, "Telegram account linked successfully", nil)
+	response.OK(c, "Profile updated successfully", gin.H{"user": user})
 }
 
 // HealthCheck handles health check requests
@@ -274,6 +744,19 @@ func (h *AuthHandler) HealthCheck(c *gin.Context) {
 	})
 }
 
+// JWKS handles publishing the token verification keys
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying tokens issued by the auth service
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwt.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	// Served as a bare key set (not wrapped in response.Response) as clients expect
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
 // GetUserByTelegramID handles getting user by Telegram ID (internal endpoint)
 // @Summary Get user by Telegram ID
 // @Description Get user information by Telegram ID (for internal service communication)
@@ -286,8 +769,8 @@ func (h *AuthHandler) HealthCheck(c *gin.Context) {
 // @Failure 500 {object} response.ErrorResponse
 // @Router /internal/users/telegram/{telegram_id} [get]
 func (h *AuthHandler) GetUserByTelegramID(c *gin.Context) {
-	// This endpoint is for internal service communication
-	// In production, you might want to add IP restrictions or API key authentication
+	// This endpoint is for internal service communication; callers are
+	// authenticated by ServiceAuthMiddleware
 
 	var req struct {
 		TelegramID int64 `uri:"telegram_id" binding:"required"`
@@ -309,3 +792,70 @@ func (h *AuthHandler) GetUserByTelegramID(c *gin.Context) {
 	// Return success response
 	response.OK(c, "User found", gin.H{"user": user})
 }
+
+// respondLocked writes a 429 with Retry-After if err is a login lockout
+func respondLocked(c *gin.Context, err error) bool {
+	var locked *service.AccountLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+
+	seconds := int(math.Ceil(locked.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	response.ErrorResponseWithCode(c, http.StatusTooManyRequests, "Too many failed login attempts, try again later", "ACCOUNT_LOCKED")
+	return true
+}
+
+// respondSuspended writes a 403 if err is a refused login of a suspended account
+func respondSuspended(c *gin.Context, err error) bool {
+	var suspended *service.AccountSuspendedError
+	if !errors.As(err, &suspended) {
+		return false
+	}
+
+	response.ErrorResponseWithCode(c, http.StatusForbidden, "Account has been suspended", "ACCOUNT_SUSPENDED")
+	return true
+}
+
+// clientInfo collects the client details a new session is created with
+func clientInfo(c *gin.Context, deviceName string) *models.ClientInfo {
+	return &models.ClientInfo{
+		DeviceName: deviceName,
+		UserAgent:  c.Request.UserAgent(),
+		IPAddress:  c.ClientIP(),
+	}
+}
+
+// respondLogin writes the tokens of a completed login, or the mfa_pending
+// token when a second factor is still required
+func respondLogin(c *gin.Context, result *service.LoginResult, message string) {
+	if result.MFARequired() {
+		response.OK(c, "Two-factor authentication required", gin.H{
+			"mfa_required": true,
+			"mfa_token":    result.MFAToken,
+		})
+		return
+	}
+
+	// Return success response
+	responseData := gin.H{
+		"user":   result.User,
+		"tokens": result.Tokens,
+	}
+
+	response.OK(c, message, responseData)
+}
+
+// respondWeakPassword writes a 400 listing the violated password rules if err
+// is a password policy error
+func respondWeakPassword(c *gin.Context, err error) bool {
+	var weak *service.WeakPasswordError
+	if !errors.As(err, &weak) {
+		return false
+	}
+
+	response.ErrorResponseWithDetails(c, http.StatusBadRequest, "Password does not meet the requirements", "WEAK_PASSWORD", gin.H{
+		"reasons": weak.Violations,
+	})
+	return true
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// ExportHandler handles user data export HTTP requests
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport handles requesting an archive of the current user's data
+// @Summary Request data export
+// @Description Start building a zip archive with everything stored about the current user. The download link is emailed once it is ready and can also be fetched with the export ID.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} response.Response{data=models.DataExport}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/account/export [post]
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	export, err := h.exportService.RequestExport(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "export already requested" {
+			response.Conflict(c, "A data export is already in progress or ready to download")
+			return
+		}
+		response.InternalServerError(c, "Failed to request data export")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusAccepted, "Data export requested", export)
+}
+
+// GetExport handles checking the status of a data export
+// @Summary Get data export
+// @Description Get the status of a data export. A ready export includes a single-use download link; fetching it again invalidates links issued before.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} response.Response{data=models.DataExportResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/account/export/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	export, err := h.exportService.GetExport(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		if err.Error() == "export not found" {
+			response.NotFound(c, "Data export not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to get data export")
+		return
+	}
+
+	response.OK(c, "Data export retrieved successfully", export)
+}
+
+// DownloadExport handles downloading a data export through its link
+// @Summary Download data export
+// @Description Download the zip archive of a data export. The link works once.
+// @Tags auth
+// @Produce application/zip
+// @Param token query string true "Download token"
+// @Success 200 {file} file
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/account/export/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.BadRequest(c, "Download token is required")
+		return
+	}
+
+	export, err := h.exportService.Download(c.Request.Context(), token)
+	if err != nil {
+		if err.Error() == "invalid or expired token" {
+			response.BadRequest(c, "Invalid or expired download link")
+			return
+		}
+		response.InternalServerError(c, "Failed to download data export")
+		return
+	}
+	defer h.exportService.Discard(export)
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(export.FilePath, "rhythmify-data-"+export.CreatedAt.UTC().Format("2006-01-02")+".zip")
+}
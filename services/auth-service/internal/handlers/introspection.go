@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// IntrospectionHandler handles token introspection HTTP requests
+type IntrospectionHandler struct {
+	introspectionService *service.IntrospectionService
+}
+
+// NewIntrospectionHandler creates a new introspection handler
+func NewIntrospectionHandler(introspectionService *service.IntrospectionService) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		introspectionService: introspectionService,
+	}
+}
+
+// Introspect handles an RFC 7662 token introspection request
+// @Summary Introspect token
+// @Description Check whether an access token or API key is active, taking revocations and account status into account (for internal service communication). The response may be cached for the duration given in Cache-Control.
+// @Tags internal
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Access token or API key"
+// @Param token_type_hint formData string false "access_token or api_key"
+// @Success 200 {object} models.IntrospectionResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /oauth/introspect [post]
+func (h *IntrospectionHandler) Introspect(c *gin.Context) {
+	// This endpoint is for internal service communication; callers are
+	// authenticated by ServiceAuthMiddleware
+
+	var req models.IntrospectionRequest
+
+	// Bind and validate request
+	if err := c.ShouldBind(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	// The hint only speeds up lookups, and tokens are told apart by their
+	// prefix anyway
+	resp, err := h.introspectionService.Introspect(c.Request.Context(), req.Token)
+	if err != nil {
+		response.InternalServerError(c, "Failed to introspect token")
+		return
+	}
+
+	// Served as a bare introspection response (not wrapped in
+	// response.Response) as RFC 7662 requires
+	if maxAge := int(h.introspectionService.CacheDuration(resp).Seconds()); maxAge > 0 {
+		c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	} else {
+		c.Header("Cache-Control", "no-store")
+	}
+	c.JSON(http.StatusOK, resp)
+}
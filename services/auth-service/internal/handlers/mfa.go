@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// MFAHandler handles two-factor authentication HTTP requests
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// EnrollTOTP handles starting authenticator app enrollment
+// @Summary Enroll TOTP
+// @Description Generate a TOTP secret and otpauth URI for an authenticator app
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.TOTPEnrollmentResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/mfa/totp/enroll [post]
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	enrollment, err := h.mfaService.EnrollTOTP(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "two-factor authentication already enabled" {
+			response.Conflict(c, "Two-factor authentication is already enabled")
+			return
+		}
+		response.InternalServerError(c, "Failed to start two-factor enrollment")
+		return
+	}
+
+	response.OK(c, "Scan the code with your authenticator app and confirm it", enrollment)
+}
+
+// ConfirmTOTP handles finishing authenticator app enrollment
+// @Summary Confirm TOTP
+// @Description Confirm enrollment with a code and receive recovery codes
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.MFACodeRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		switch err.Error() {
+		case "invalid code", "no pending two-factor enrollment":
+			response.BadRequest(c, err.Error())
+		case "two-factor authentication already enabled":
+			response.Conflict(c, "Two-factor authentication is already enabled")
+		default:
+			response.InternalServerError(c, "Failed to confirm two-factor enrollment")
+		}
+		return
+	}
+
+	response.OK(c, "Two-factor authentication enabled", gin.H{"recovery_codes": codes})
+}
+
+// DisableTOTP handles turning off two-factor authentication
+// @Summary Disable TOTP
+// @Description Turn off two-factor authentication with the password and a code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.DisableTOTPRequest true "Password and code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/mfa/totp [delete]
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.DisableTOTPRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(c.Request.Context(), userID, &req); err != nil {
+		switch err.Error() {
+		case "invalid credentials":
+			response.Unauthorized(c, "Invalid password")
+		case "invalid code", "two-factor authentication not enabled":
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to disable two-factor authentication")
+		}
+		return
+	}
+
+	response.OK(c, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after checking a code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/mfa/recovery-codes/regenerate [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.MFACodeRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		if err.Error() == "invalid code" || err.Error() == "two-factor authentication not enabled" {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "Failed to regenerate recovery codes")
+		return
+	}
+
+	response.OK(c, "Recovery codes regenerated", gin.H{"recovery_codes": codes})
+}
+
+// LoginMFA handles the second step of a login with two-factor authentication
+// @Summary Complete login with a second factor
+// @Description Exchange an mfa_pending token and a TOTP or recovery code for tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.LoginMFARequest true "MFA token and code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/login/mfa [post]
+func (h *MFAHandler) LoginMFA(c *gin.Context) {
+	var req models.LoginMFARequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	user, tokens, err := h.mfaService.CompleteLogin(c.Request.Context(), &req, clientInfo(c, req.DeviceName))
+	if err != nil {
+		if respondLocked(c, err) || respondSuspended(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "invalid code":
+			response.Unauthorized(c, "Invalid two-factor code")
+		case "invalid or expired token", "two-factor authentication not enabled":
+			response.Unauthorized(c, "Invalid or expired MFA token")
+		default:
+			response.InternalServerError(c, "Failed to login")
+		}
+		return
+	}
+
+	// Return success response
+	responseData := gin.H{
+		"user":   user,
+		"tokens": tokens,
+	}
+
+	response.OK(c, "Login successful", responseData)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// PasskeyHandler handles WebAuthn passkey HTTP requests
+type PasskeyHandler struct {
+	passkeyService *service.PasskeyService
+}
+
+// NewPasskeyHandler creates a new passkey handler
+func NewPasskeyHandler(passkeyService *service.PasskeyService) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+	}
+}
+
+// BeginRegistration handles starting a passkey registration
+// @Summary Begin passkey registration
+// @Description Get the options for navigator.credentials.create()
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.BeginPasskeyResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkeys/register/begin [post]
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	options, err := h.passkeyService.BeginRegistration(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to start passkey registration")
+		return
+	}
+
+	response.OK(c, "Passkey registration started", options)
+}
+
+// FinishRegistration handles the authenticator's response to a registration
+// @Summary Finish passkey registration
+// @Description Verify the new credential and store the passkey
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.FinishPasskeyRegistrationRequest true "Ceremony ID and credential"
+// @Success 201 {object} response.Response{data=models.Credential}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkeys/register/finish [post]
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.FinishPasskeyRegistrationRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	credential, err := h.passkeyService.FinishRegistration(c.Request.Context(), userID, &req)
+	if err != nil {
+		if err.Error() == "invalid passkey" || err.Error() == "invalid or expired ceremony" {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "Failed to register passkey")
+		return
+	}
+
+	response.Created(c, "Passkey registered successfully", credential)
+}
+
+// BeginLogin handles starting a passwordless login
+// @Summary Begin passkey login
+// @Description Get the options for navigator.credentials.get()
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=models.BeginPasskeyResponse}
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkeys/login/begin [post]
+func (h *PasskeyHandler) BeginLogin(c *gin.Context) {
+	options, err := h.passkeyService.BeginLogin(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to start passkey login")
+		return
+	}
+
+	response.OK(c, "Passkey login started", options)
+}
+
+// FinishLogin handles the authenticator's response to a login
+// @Summary Finish passkey login
+// @Description Verify the assertion and return tokens
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Param request body models.FinishPasskeyLoginRequest true "Ceremony ID and assertion"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkeys/login/finish [post]
+func (h *PasskeyHandler) FinishLogin(c *gin.Context) {
+	var req models.FinishPasskeyLoginRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	user, tokens, err := h.passkeyService.FinishLogin(c.Request.Context(), &req, clientInfo(c, req.DeviceName))
+	if err != nil {
+		if respondSuspended(c, err) {
+			return
+		}
+		if err.Error() == "invalid passkey" || err.Error() == "invalid or expired ceremony" {
+			response.Unauthorized(c, "Invalid passkey")
+			return
+		}
+		response.InternalServerError(c, "Failed to login")
+		return
+	}
+
+	// Return success response
+	responseData := gin.H{
+		"user":   user,
+		"tokens": tokens,
+	}
+
+	response.OK(c, "Login successful", responseData)
+}
+
+// ListPasskeys handles listing the current user's passkeys
+// @Summary List passkeys
+// @Description List the passkeys registered by the current user
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkeys [get]
+func (h *PasskeyHandler) ListPasskeys(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	passkeys, err := h.passkeyService.ListPasskeys(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to list passkeys")
+		return
+	}
+
+	response.OK(c, "Passkeys retrieved successfully", gin.H{"passkeys": passkeys})
+}
+
+// DeletePasskey handles deleting one of the current user's passkeys
+// @Summary Delete passkey
+// @Description Remove a passkey so it can no longer be used to log in
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Passkey ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/auth/passkeys/{id} [delete]
+func (h *PasskeyHandler) DeletePasskey(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.BadRequest(c, "Invalid passkey ID")
+		return
+	}
+
+	if err := h.passkeyService.DeletePasskey(c.Request.Context(), userID, req.ID); err != nil {
+		response.NotFound(c, "Passkey not found")
+		return
+	}
+
+	response.OK(c, "Passkey deleted successfully", nil)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// RoleHandler handles role administration HTTP requests
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// ListRoles handles listing the available roles
+// @Summary List roles
+// @Description List every role with the permissions it grants
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.Role}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to list roles")
+		return
+	}
+
+	response.OK(c, "Roles retrieved successfully", roles)
+}
+
+// GetUserRoles handles listing the roles of a user
+// @Summary Get user roles
+// @Description List the roles granted to a user
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=models.UserRolesResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	var req struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	roles, err := h.roleService.GetUserRoles(c.Request.Context(), req.ID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response.NotFound(c, "User not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to get user roles")
+		return
+	}
+
+	response.OK(c, "User roles retrieved successfully", roles)
+}
+
+// AssignRole handles granting a role to a user
+// @Summary Assign role
+// @Description Grant a role to a user. It is included in the user's tokens from their next refresh.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.AssignRoleRequest true "Role to grant"
+// @Success 200 {object} response.Response{data=models.UserRolesResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/roles [post]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	actorID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	// Bind URI parameter
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req models.AssignRoleRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	roles, err := h.roleService.AssignRole(c.Request.Context(), actorID, uri.ID, req.Role)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			response.NotFound(c, "User not found")
+		case "role not found":
+			response.BadRequest(c, "Unknown role")
+		default:
+			response.InternalServerError(c, "Failed to assign role")
+		}
+		return
+	}
+
+	response.OK(c, "Role assigned successfully", roles)
+}
+
+// RemoveRole handles removing a role from a user
+// @Summary Remove role
+// @Description Remove a role from a user. Tokens already issued keep it until they are refreshed.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/admin/users/{id}/roles/{role} [delete]
+func (h *RoleHandler) RemoveRole(c *gin.Context) {
+	actorID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		ID   int64  `uri:"id" binding:"required"`
+		Role string `uri:"role" binding:"required"`
+	}
+
+	// Bind URI parameters
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.BadRequest(c, "Invalid user ID or role")
+		return
+	}
+
+	if err := h.roleService.RevokeRole(c.Request.Context(), actorID, req.ID, req.Role); err != nil {
+		switch err.Error() {
+		case "cannot remove own admin role":
+			response.BadRequest(c, "You cannot remove your own admin role")
+		case "role not granted":
+			response.NotFound(c, "User does not have this role")
+		default:
+			response.InternalServerError(c, "Failed to remove role")
+		}
+		return
+	}
+
+	response.OK(c, "Role removed successfully", nil)
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/middleware"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/service"
+	"rhythmify/shared/response"
+)
+
+// TelegramHandler handles Telegram account HTTP requests
+type TelegramHandler struct {
+	telegramService *service.TelegramService
+}
+
+// NewTelegramHandler creates a new Telegram handler
+func NewTelegramHandler(telegramService *service.TelegramService) *TelegramHandler {
+	return &TelegramHandler{
+		telegramService: telegramService,
+	}
+}
+
+// LinkTelegram handles linking Telegram account
+// @Summary Link Telegram account
+// @Description Link a Telegram account to the current user with a Telegram Login Widget payload
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.LinkTelegramRequest true "Telegram Login Widget data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/auth/telegram [post]
+func (h *TelegramHandler) LinkTelegram(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.LinkTelegramRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	// Link Telegram account
+	err := h.telegramService.LinkTelegram(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid telegram login data", "telegram login data expired":
+			response.BadRequest(c, err.Error())
+		case "telegram account already linked to another user":
+			response.Conflict(c, err.Error())
+		case "telegram login not configured":
+			response.ErrorResponseWithCode(c, http.StatusServiceUnavailable, "Telegram login is not available", "TELEGRAM_NOT_CONFIGURED")
+		default:
+			response.InternalServerError(c, "Failed to link Telegram account")
+		}
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Telegram account linked successfully", nil)
+}
+
+// WebAppLogin handles logging in from a Telegram Mini App
+// @Summary Log in with Telegram Mini App
+// @Description Verify Mini App initData and log in, creating an account for new Telegram users
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TelegramWebAppLoginRequest true "Mini App init data"
+// @Success 200 {object} response.Response
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/auth/telegram/webapp [post]
+func (h *TelegramHandler) WebAppLogin(c *gin.Context) {
+	var req models.TelegramWebAppLoginRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	result, created, err := h.telegramService.LoginWithWebApp(c.Request.Context(), &req, clientInfo(c, req.DeviceName))
+	if err != nil {
+		if respondSuspended(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "invalid telegram login data", "telegram login data expired":
+			response.Unauthorized(c, err.Error())
+		case "telegram login not configured":
+			response.ErrorResponseWithCode(c, http.StatusServiceUnavailable, "Telegram login is not available", "TELEGRAM_NOT_CONFIGURED")
+		default:
+			response.InternalServerError(c, "Failed to login")
+		}
+		return
+	}
+
+	if created && !result.MFARequired() {
+		response.Created(c, "User registered successfully", gin.H{
+			"user":   result.User,
+			"tokens": result.Tokens,
+		})
+		return
+	}
+
+	respondLogin(c, result, "Login successful")
+}
+
+// UnlinkTelegram handles unlinking Telegram account
+// @Summary Unlink Telegram account
+// @Description Remove the Telegram account from the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/auth/telegram [delete]
+func (h *TelegramHandler) UnlinkTelegram(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.telegramService.UnlinkTelegram(c.Request.Context(), userID); err != nil {
+		switch err.Error() {
+		case "telegram account not linked":
+			response.NotFound(c, "Telegram account is not linked")
+		case "add an email and password before unlinking telegram":
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to unlink Telegram account")
+		}
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Telegram account unlinked successfully", nil)
+}
+
+// IssueLinkCode handles creating a bot deep-link code
+// @Summary Create Telegram link code
+// @Description Create a one-time code and a t.me link that links the account through the bot
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.TelegramLinkCodeResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/auth/telegram/link-code [post]
+func (h *TelegramHandler) IssueLinkCode(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	linkCode, err := h.telegramService.IssueLinkCode(c.Request.Context(), userID)
+	if err != nil {
+		switch err.Error() {
+		case "too many link codes requested":
+			response.ErrorResponseWithCode(c, http.StatusTooManyRequests, "Too many link codes requested, try again later", "TOO_MANY_REQUESTS")
+		case "telegram bot not configured":
+			response.ErrorResponseWithCode(c, http.StatusServiceUnavailable, "Telegram linking is not available", "TELEGRAM_NOT_CONFIGURED")
+		default:
+			response.InternalServerError(c, "Failed to create link code")
+		}
+		return
+	}
+
+	response.OK(c, "Open the link in Telegram to finish linking", linkCode)
+}
+
+// RedeemLinkCode handles a "/start <code>" update forwarded by the bot
+// @Summary Redeem Telegram link code
+// @Description Link the sender of a /start message to the account the code was issued for
+// @Tags internal
+// @Accept json
+// @Produce json
+// @Param X-Telegram-Bot-Api-Secret-Token header string true "Secret token of the bot"
+// @Param request body models.TelegramUpdate true "Bot API update"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /internal/telegram/link [post]
+func (h *TelegramHandler) RedeemLinkCode(c *gin.Context) {
+	var update models.TelegramUpdate
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&update); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	user, err := h.telegramService.RedeemLinkCode(c.Request.Context(), &update)
+	if err != nil {
+		switch err.Error() {
+		case "not a link command":
+			response.BadRequest(c, err.Error())
+		case "invalid or expired link code":
+			response.NotFound(c, "Link code is invalid or expired")
+		case "telegram account already linked to another user":
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to link Telegram account")
+		}
+		return
+	}
+
+	// Return success response
+	response.OK(c, "Telegram account linked successfully", gin.H{"user": user})
+}
@@ -1,17 +1,24 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"rhythmify/services/auth-service/internal/models"
 	"rhythmify/shared/jwt"
 	"rhythmify/shared/response"
 )
 
+// RevocationChecker reports whether a validated token has been revoked
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, claims *jwt.Claims) (bool, error)
+}
+
 // JWTMiddleware creates a JWT authentication middleware
-func JWTMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
+func JWTMiddleware(jwtManager *jwt.JWTManager, revocations RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -36,6 +43,13 @@ func JWTMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// API keys are only accepted where JWTOrAPIKeyMiddleware is used
+		if strings.HasPrefix(tokenString, models.APIKeyPrefix) {
+			response.ErrorResponseWithCode(c, http.StatusForbidden, "API keys cannot be used for this endpoint", "API_KEY_NOT_ALLOWED")
+			c.Abort()
+			return
+		}
+
 		// Validate token
 		claims, err := jwtManager.ValidateToken(tokenString)
 		if err != nil {
@@ -51,6 +65,63 @@ func JWTMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// Check if the token has been revoked
+		revoked, err := revocations.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			response.InternalServerError(c, "Failed to verify token")
+			c.Abort()
+			return
+		}
+		if revoked {
+			response.Unauthorized(c, "Token has been revoked")
+			c.Abort()
+			return
+		}
+
+		// Store user information in context
+		c.Set("user_id", claims.UserID)
+		c.Set("user_email", claims.Email)
+		c.Set("user_username", claims.Username)
+		c.Set("user_claims", claims)
+
+		// Continue to next handler
+		c.Next()
+	}
+}
+
+// APIKeyAuthenticator resolves API keys to the claims of their owner
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string, ip string) (*jwt.Claims, error)
+}
+
+// JWTOrAPIKeyMiddleware creates an authentication middleware that accepts
+// API keys (Authorization: Bearer rhy_...) as well as access tokens. Routes
+// behind it should limit API keys with RequireScope.
+func JWTOrAPIKeyMiddleware(jwtManager *jwt.JWTManager, revocations RevocationChecker, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
+	jwtAuth := JWTMiddleware(jwtManager, revocations)
+
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer "+models.APIKeyPrefix) {
+			jwtAuth(c)
+			return
+		}
+
+		// Authenticate API key
+		claims, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), strings.TrimPrefix(authHeader, "Bearer "), c.ClientIP())
+		if err != nil {
+			switch err.Error() {
+			case "account suspended":
+				response.ErrorResponseWithCode(c, http.StatusForbidden, "Account has been suspended", "ACCOUNT_SUSPENDED")
+			case "api key expired":
+				response.ErrorResponseWithCode(c, http.StatusUnauthorized, "API key has expired", "API_KEY_EXPIRED")
+			default:
+				response.Unauthorized(c, "Invalid API key")
+			}
+			c.Abort()
+			return
+		}
+
 		// Store user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
@@ -63,7 +134,7 @@ func JWTMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 }
 
 // OptionalJWTMiddleware creates an optional JWT middleware (doesn't fail if no token)
-func OptionalJWTMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
+func OptionalJWTMiddleware(jwtManager *jwt.JWTManager, revocations RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -103,6 +174,13 @@ func OptionalJWTMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// Check if the token has been revoked
+		if revoked, err := revocations.IsRevoked(c.Request.Context(), claims); err != nil || revoked {
+			// Revoked or unverifiable token, continue without authentication
+			c.Next()
+			return
+		}
+
 		// Store user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
@@ -172,13 +250,110 @@ func RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// RequireVerifiedEmail is a middleware that only lets users with a verified
+// email address through. It must run after JWTMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := GetUserClaimsFromContext(c)
+		if !exists {
+			response.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		if !claims.EmailVerified {
+			response.ErrorResponseWithCode(c, http.StatusForbidden, "Email address is not verified", "EMAIL_NOT_VERIFIED")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PermissionResolver maps the roles claim to permissions without a database lookup
+type PermissionResolver interface {
+	HasPermission(roles []string, permission string) bool
+}
+
+// RequireRole is a middleware that only lets users with at least one of the
+// given roles through. It must run after JWTMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := GetUserClaimsFromContext(c)
+		if !exists {
+			response.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+
+		response.ErrorResponseWithCode(c, http.StatusForbidden, "Insufficient permissions", "FORBIDDEN")
+		c.Abort()
+	}
+}
+
+// RequirePermission is a middleware that only lets users whose roles grant
+// every given permission through. It must run after JWTMiddleware.
+func RequirePermission(resolver PermissionResolver, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := GetUserClaimsFromContext(c)
+		if !exists {
+			response.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			if !resolver.HasPermission(claims.Roles, permission) {
+				response.ErrorResponseWithCode(c, http.StatusForbidden, "Insufficient permissions", "FORBIDDEN")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope is a middleware that only lets API keys with every given scope
+// through. Access tokens are not limited by scopes. It must run after
+// JWTOrAPIKeyMiddleware.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := GetUserClaimsFromContext(c)
+		if !exists {
+			response.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if !claims.HasScope(scope) {
+				response.ErrorResponseWithCode(c, http.StatusForbidden, "API key lacks the "+scope+" scope", "INSUFFICIENT_SCOPE")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
 // CORSMiddleware adds CORS headers
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
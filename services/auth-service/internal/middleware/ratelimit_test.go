@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+func TestKeyByAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		claims *jwt.Claims
+		want   string
+	}{
+		{name: "api key", claims: jwt.NewAPIKeyClaims(1, "user@example.com", "user", "42", nil), want: "apikey:42"},
+		{name: "access token", claims: &jwt.Claims{UserID: 1, Type: jwt.AccessToken}, want: "user:1"},
+		{name: "unauthenticated", want: "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/profile", nil)
+			c.Request.RemoteAddr = "192.0.2.1:1234"
+			c.Request.Header.Set("Authorization", "Bearer "+models.APIKeyPrefix+"made-up")
+			if tt.claims != nil {
+				c.Set("user_id", tt.claims.UserID)
+				c.Set("user_claims", tt.claims)
+			}
+
+			if got := KeyByAPIKey(c); got != tt.want {
+				t.Errorf("KeyByAPIKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareIgnoresUnauthenticatedKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	limit := models.RateLimit{Requests: 2, Period: time.Minute, Burst: 2}
+	router.GET("/limited", RateLimitMiddleware(repository.NewMemoryRateLimitStore(), "test", limit, KeyByAPIKey), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// A new made-up key per request must not get a new bucket
+	for i := 1; i <= 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %sguess-%d", models.APIKeyPrefix, i))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		want := http.StatusOK
+		if i == 3 {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Fatalf("request %d: status = %d, want %d", i, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/audit"
+	"rhythmify/shared/jwt"
+)
+
+// RequestIDHeader carries the ID that ties a request to its audit events
+// and to the logs of the proxies and services it passed through
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs accepted from clients
+const maxRequestIDLength = 64
+
+// RequestContextMiddleware assigns every request an ID, reusing a well-formed
+// X-Request-ID from the client, echoes it in the response and stores the
+// client IP, user agent and request ID for the audit log in the request
+// context. It must be registered before the routes.
+func RequestContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			generated, err := jwt.NewTokenID()
+			if err != nil {
+				log.Printf("Failed to generate request ID: %v", err)
+			}
+			requestID = generated
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := audit.WithRequestInfo(c.Request.Context(), audit.RequestInfo{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID,
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// GetRequestIDFromContext extracts the request ID from Gin context
+func GetRequestIDFromContext(c *gin.Context) (string, bool) {
+	requestID, exists := c.Get("request_id")
+	if !exists {
+		return "", false
+	}
+
+	id, ok := requestID.(string)
+	return id, ok
+}
+
+// validRequestID checks that a client-supplied request ID is safe to log and
+// store: 1 to 64 letters, digits, dashes, underscores, dots or colons
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/serviceauth"
+)
+
+// signedRequest builds a request signed by hand, so tests can pick the
+// timestamp and nonce and tamper with what was signed
+type signedRequest struct {
+	service   string
+	key       string
+	method    string
+	target    string
+	body      string
+	timestamp time.Time
+	nonce     string
+
+	// sent overrides what is sent after signing
+	sentTarget string
+	sentBody   *string
+}
+
+func (r signedRequest) build() *http.Request {
+	signed := httptest.NewRequest(r.method, r.target, nil)
+	stringToSign := serviceauth.StringToSign(r.method, signed.URL.EscapedPath(), signed.URL.RawQuery, r.timestamp.Unix(), r.nonce, serviceauth.HashBody([]byte(r.body)))
+
+	target, body := r.target, r.body
+	if r.sentTarget != "" {
+		target = r.sentTarget
+	}
+	if r.sentBody != nil {
+		body = *r.sentBody
+	}
+
+	req := httptest.NewRequest(r.method, target, strings.NewReader(body))
+	req.Header.Set(serviceauth.HeaderService, r.service)
+	req.Header.Set(serviceauth.HeaderTimestamp, strconv.FormatInt(r.timestamp.Unix(), 10))
+	req.Header.Set(serviceauth.HeaderNonce, r.nonce)
+	req.Header.Set(serviceauth.HeaderSignature, serviceauth.Signature([]byte(r.key), stringToSign))
+	return req
+}
+
+func newServiceAuthRouter(nonces repository.NonceStore) (*gin.Engine, *string) {
+	gin.SetMode(gin.TestMode)
+
+	var caller string
+	router := gin.New()
+	services := map[string][]string{"playlist": {"old-key", "new-key"}}
+	router.Any("/internal/*path", ServiceAuthMiddleware(services, nonces, 5*time.Minute), func(c *gin.Context) {
+		caller, _ = GetServiceNameFromContext(c)
+		c.Status(http.StatusOK)
+	})
+	return router, &caller
+}
+
+func TestServiceAuthMiddleware(t *testing.T) {
+	tampered := `{"telegram_id":43}`
+	valid := signedRequest{
+		service:   "playlist",
+		key:       "new-key",
+		method:    http.MethodPost,
+		target:    "/internal/telegram/link?source=bot",
+		body:      `{"telegram_id":42}`,
+		timestamp: time.Now(),
+		nonce:     "0123456789abcdef0123456789abcdef",
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(r *signedRequest)
+		wantStatus int
+	}{
+		{name: "valid", modify: func(r *signedRequest) {}, wantStatus: http.StatusOK},
+		{name: "previous key", modify: func(r *signedRequest) { r.key = "old-key" }, wantStatus: http.StatusOK},
+		{name: "slightly ahead clock", modify: func(r *signedRequest) { r.timestamp = time.Now().Add(4 * time.Minute) }, wantStatus: http.StatusOK},
+		{name: "tampered body", modify: func(r *signedRequest) { r.sentBody = &tampered }, wantStatus: http.StatusUnauthorized},
+		{name: "tampered path", modify: func(r *signedRequest) { r.sentTarget = "/internal/users/telegram/42?source=bot" }, wantStatus: http.StatusUnauthorized},
+		{name: "tampered query", modify: func(r *signedRequest) { r.sentTarget = "/internal/telegram/link?source=app" }, wantStatus: http.StatusUnauthorized},
+		{name: "dropped query", modify: func(r *signedRequest) { r.sentTarget = "/internal/telegram/link" }, wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", modify: func(r *signedRequest) { r.key = "guessed-key" }, wantStatus: http.StatusUnauthorized},
+		{name: "stale timestamp", modify: func(r *signedRequest) { r.timestamp = time.Now().Add(-6 * time.Minute) }, wantStatus: http.StatusUnauthorized},
+		{name: "future timestamp", modify: func(r *signedRequest) { r.timestamp = time.Now().Add(6 * time.Minute) }, wantStatus: http.StatusUnauthorized},
+		{name: "unknown service", modify: func(r *signedRequest) { r.service = "billing" }, wantStatus: http.StatusUnauthorized},
+		{name: "short nonce", modify: func(r *signedRequest) { r.nonce = "abc" }, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, caller := newServiceAuthRouter(repository.NewMemoryNonceStore())
+			r := valid
+			tt.modify(&r)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r.build())
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && *caller != "playlist" {
+				t.Errorf("service name in context = %q, want playlist", *caller)
+			}
+		})
+	}
+}
+
+func TestServiceAuthMiddlewareMissingHeaders(t *testing.T) {
+	router, _ := newServiceAuthRouter(repository.NewMemoryNonceStore())
+
+	for _, header := range []string{serviceauth.HeaderService, serviceauth.HeaderTimestamp, serviceauth.HeaderNonce, serviceauth.HeaderSignature} {
+		req := signedRequest{
+			service: "playlist", key: "new-key", method: http.MethodGet, target: "/internal/users/telegram/42",
+			timestamp: time.Now(), nonce: "0123456789abcdef",
+		}.build()
+		req.Header.Del(header)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("without %s: status = %d, want %d", header, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestServiceAuthMiddlewareRejectsReplays(t *testing.T) {
+	router, _ := newServiceAuthRouter(repository.NewMemoryNonceStore())
+	signed := signedRequest{
+		service:   "playlist",
+		key:       "new-key",
+		method:    http.MethodGet,
+		target:    "/internal/users/telegram/42",
+		timestamp: time.Now(),
+		nonce:     "fedcba9876543210",
+	}
+
+	// A forged request with the nonce must not use it up
+	forged := signed
+	forged.key = "guessed-key"
+
+	steps := []struct {
+		name       string
+		request    signedRequest
+		wantStatus int
+	}{
+		{name: "forged request", request: forged, wantStatus: http.StatusUnauthorized},
+		{name: "signed request", request: signed, wantStatus: http.StatusOK},
+		{name: "replayed request", request: signed, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, step := range steps {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, step.request.build())
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", step.name, rec.Code, step.wantStatus)
+		}
+		if step.name == "replayed request" && !strings.Contains(rec.Body.String(), "REPLAYED_REQUEST") {
+			t.Errorf("replay response = %s, want REPLAYED_REQUEST", rec.Body.String())
+		}
+	}
+}
+
+func TestIPAllowlistMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	networks, err := ParseCIDRs([]string{"10.1.0.0/16", "192.0.2.7"})
+	if err != nil {
+		t.Fatalf("ParseCIDRs failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		peer         string
+		forwardedFor string
+		wantStatus   int
+	}{
+		{name: "peer in network", peer: "10.1.2.3", wantStatus: http.StatusOK},
+		{name: "single host", peer: "192.0.2.7", wantStatus: http.StatusOK},
+		{name: "peer outside networks", peer: "192.0.2.8", wantStatus: http.StatusForbidden},
+		{name: "spoofed forwarded for from untrusted peer", peer: "203.0.113.9", forwardedFor: "10.1.2.3", wantStatus: http.StatusForbidden},
+		{name: "forwarded for from trusted proxy", peer: "10.9.0.1", forwardedFor: "10.1.2.3", wantStatus: http.StatusOK},
+		{name: "outside address from trusted proxy", peer: "10.9.0.1", forwardedFor: "203.0.113.9", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if err := router.SetTrustedProxies([]string{"10.9.0.1"}); err != nil {
+				t.Fatalf("SetTrustedProxies failed: %v", err)
+			}
+			router.GET("/internal/users/telegram/:telegram_id", IPAllowlistMiddleware(networks), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/users/telegram/42", nil)
+			req.RemoteAddr = tt.peer + ":1234"
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "10.0.0.0/8", want: "10.0.0.0/8"},
+		{value: "192.0.2.7", want: "192.0.2.7/32"},
+		{value: "2001:db8::1", want: "2001:db8::1/128"},
+		{value: "10.0.0.0/33", wantErr: true},
+		{value: "not-an-ip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		networks, err := ParseCIDRs([]string{tt.value})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseCIDRs(%q) succeeded, want an error", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCIDRs(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if got := networks[0].String(); got != tt.want {
+			t.Errorf("ParseCIDRs(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/shared/response"
+)
+
+// TelegramSecretTokenHeader carries the secret token Telegram sends with
+// every webhook update (the secret_token of setWebhook). The bot passes it on
+// with the updates it forwards.
+const TelegramSecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramBotMiddleware only lets requests carrying the bot's secret token
+// through, so updates cannot be forged by anyone who merely reaches the
+// route. Every request is rejected while secret is empty.
+func TelegramBotMiddleware(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(TelegramSecretTokenHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			response.ErrorResponseWithCode(c, http.StatusUnauthorized, "Invalid bot secret token", "INVALID_BOT_TOKEN")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startUpdate is a stubbed Bot API update with a /start command
+const startUpdate = `{"update_id":10000,"message":{"message_id":1365,"from":{"id":424242,"is_bot":false,"first_name":"Ada"},"chat":{"id":424242,"type":"private"},"text":"/start code"}}`
+
+func TestTelegramBotMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		secret     string
+		header     string
+		wantStatus int
+	}{
+		{name: "matching secret token", secret: "bot-secret", header: "bot-secret", wantStatus: http.StatusOK},
+		{name: "missing secret token", secret: "bot-secret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong secret token", secret: "bot-secret", header: "bot-secreT", wantStatus: http.StatusUnauthorized},
+		{name: "secret not configured", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			router := gin.New()
+			router.POST("/internal/telegram/link", TelegramBotMiddleware(tt.secret), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/internal/telegram/link", strings.NewReader(startUpdate))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set(TelegramSecretTokenHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if reached != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler reached = %v", reached)
+			}
+		})
+	}
+}
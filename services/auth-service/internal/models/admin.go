@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Admin actions recorded in the admin action log
+const (
+	AdminActionUpdateUser         = "user.update"
+	AdminActionSuspendUser        = "user.suspend"
+	AdminActionUnsuspendUser      = "user.unsuspend"
+	AdminActionForcePasswordReset = "user.force_password_reset"
+	AdminActionRevokeSessions     = "user.revoke_sessions"
+	AdminActionClearLockout       = "user.clear_lockout"
+	AdminActionAssignRole         = "role.assign"
+	AdminActionRevokeRole         = "role.revoke"
+)
+
+// DefaultAdminPageSize is the page size of admin listings without a limit
+const DefaultAdminPageSize = 50
+
+// UserFilter selects users in the admin user search. Results are ordered by
+// ID, newest first; Cursor is the NextCursor of the previous page.
+type UserFilter struct {
+	Email         string    `form:"email" binding:"omitempty,max=255"`
+	Username      string    `form:"username" binding:"omitempty,max=50"`
+	TelegramID    int64     `form:"telegram_id"`
+	CreatedAfter  time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor        string    `form:"cursor"`
+	Limit         int       `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// AdminUserResponse represents a user as seen by admins
+type AdminUserResponse struct {
+	*UserResponse
+	MustChangePassword bool       `json:"must_change_password"`
+	SuspendedAt        *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason   string     `json:"suspension_reason,omitempty"`
+}
+
+// ToAdminResponse converts User to AdminUserResponse
+func (u *User) ToAdminResponse() *AdminUserResponse {
+	return &AdminUserResponse{
+		UserResponse:       u.ToResponse(),
+		MustChangePassword: u.MustChangePassword,
+		SuspendedAt:        u.SuspendedAt,
+		SuspensionReason:   u.SuspensionReason,
+	}
+}
+
+// UserListResponse represents a page of the admin user search
+type UserListResponse struct {
+	Users      []*AdminUserResponse `json:"users"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// SuspendUserRequest represents request to suspend a user
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// AdminAction is an entry of the admin action log
+type AdminAction struct {
+	ID           int64                  `json:"id" db:"id"`
+	AdminID      *int64                 `json:"admin_id" db:"admin_id"`
+	TargetUserID *int64                 `json:"target_user_id" db:"target_user_id"`
+	Action       string                 `json:"action" db:"action"`
+	Details      map[string]interface{} `json:"details" db:"details"`
+	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+}
+
+// AdminActionFilter selects entries of the admin action log, newest first
+type AdminActionFilter struct {
+	AdminID      int64  `form:"admin_id"`
+	TargetUserID int64  `form:"target_user_id"`
+	Cursor       string `form:"cursor"`
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// AdminActionListResponse represents a page of the admin action log
+type AdminActionListResponse struct {
+	Actions    []*AdminAction `json:"actions"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
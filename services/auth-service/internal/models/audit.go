@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Audit event types
+const (
+	AuditEventUserRegistered           = "user.registered"
+	AuditEventLoginSucceeded           = "login.succeeded"
+	AuditEventLoginFailed              = "login.failed"
+	AuditEventLoginMFARequired         = "login.mfa_required"
+	AuditEventRefreshFailed            = "token.refresh_failed"
+	AuditEventLogout                   = "session.logout"
+	AuditEventLogoutAll                = "session.logout_all"
+	AuditEventSessionRevoked           = "session.revoked"
+	AuditEventProfileUpdated           = "profile.updated"
+	AuditEventEmailVerified            = "email.verified"
+	AuditEventVerificationSent         = "email.verification_sent"
+	AuditEventPasswordResetRequested   = "password.reset_requested"
+	AuditEventPasswordReset            = "password.reset"
+	AuditEventPasswordChanged          = "password.changed"
+	AuditEventPasswordSet              = "password.set"
+	AuditEventPasswordChangeRequired   = "password.change_required"
+	AuditEventAccountDeleted           = "account.deleted"
+	AuditEventAccountRestored          = "account.restored"
+	AuditEventAccountsPurged           = "account.purged"
+	AuditEventLockoutCleared           = "lockout.cleared"
+	AuditEventTelegramLinked           = "telegram.linked"
+	AuditEventTelegramUnlinked         = "telegram.unlinked"
+	AuditEventMFAEnabled               = "mfa.enabled"
+	AuditEventMFADisabled              = "mfa.disabled"
+	AuditEventRecoveryCodesRegenerated = "mfa.recovery_codes_regenerated"
+	AuditEventPasskeyAdded             = "passkey.added"
+	AuditEventPasskeyRemoved           = "passkey.removed"
+	AuditEventAPIKeyCreated            = "api_key.created"
+	AuditEventAPIKeyRevoked            = "api_key.revoked"
+)
+
+// Login methods recorded in the metadata of login events
+const (
+	LoginMethodPassword = "password"
+	LoginMethodTOTP     = "totp"
+	LoginMethodPasskey  = "passkey"
+	LoginMethodTelegram = "telegram"
+)
+
+// AuditEvent is an entry of the audit log. ActorID is who caused the event
+// and TargetUserID whose account it concerns; either is nil when unknown,
+// such as for a login attempt with an unregistered email.
+type AuditEvent struct {
+	ID           int64                  `json:"id" db:"id"`
+	ActorID      *int64                 `json:"actor_id" db:"actor_id"`
+	TargetUserID *int64                 `json:"target_user_id" db:"target_user_id"`
+	EventType    string                 `json:"event_type" db:"event_type"`
+	IPAddress    string                 `json:"ip_address" db:"ip_address"`
+	UserAgent    string                 `json:"user_agent" db:"user_agent"`
+	RequestID    string                 `json:"request_id,omitempty" db:"request_id"`
+	Metadata     map[string]interface{} `json:"metadata" db:"metadata"`
+	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+}
+
+// AuditEventFilter selects entries of the audit log, newest first. Cursor is
+// the NextCursor of the previous page.
+type AuditEventFilter struct {
+	ActorID       int64     `form:"actor_id"`
+	TargetUserID  int64     `form:"target_user_id"`
+	EventType     string    `form:"event_type" binding:"omitempty,max=50"`
+	IPAddress     string    `form:"ip_address" binding:"omitempty,ip"`
+	RequestID     string    `form:"request_id" binding:"omitempty,max=64"`
+	CreatedAfter  time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor        string    `form:"cursor"`
+	Limit         int       `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// ActivityFilter selects the audit events of the current user, newest first
+type ActivityFilter struct {
+	EventType     string    `form:"event_type" binding:"omitempty,max=50"`
+	CreatedAfter  time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor        string    `form:"cursor"`
+	Limit         int       `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// AuditEventListResponse represents a page of the audit log
+type AuditEventListResponse struct {
+	Events     []*AuditEvent `json:"events"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebAuthn ceremony kinds
+const (
+	CeremonyRegistration = "registration"
+	CeremonyLogin        = "login"
+)
+
+// Credential represents a WebAuthn passkey registered by a user
+type Credential struct {
+	ID              int64      `json:"id" db:"id"`
+	UserID          int64      `json:"-" db:"user_id"`
+	CredentialID    []byte     `json:"-" db:"credential_id"`
+	PublicKey       []byte     `json:"-" db:"public_key"`
+	AttestationType string     `json:"-" db:"attestation_type"`
+	Transports      []string   `json:"transports" db:"transports"`
+	Flags           uint8      `json:"-" db:"flags"`
+	AAGUID          []byte     `json:"-" db:"aaguid"`
+	SignCount       uint32     `json:"-" db:"sign_count"`
+	CloneWarning    bool       `json:"-" db:"clone_warning"`
+	Name            string     `json:"name" db:"name"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// WebAuthnCeremony holds the challenge state between the begin and finish
+// steps of a registration or login
+type WebAuthnCeremony struct {
+	ID          string          `db:"id"`
+	UserID      *int64          `db:"user_id"`
+	Kind        string          `db:"kind"`
+	SessionData json.RawMessage `db:"session_data"`
+	ExpiresAt   time.Time       `db:"expires_at"`
+	CreatedAt   time.Time       `db:"created_at"`
+}
+
+// BeginPasskeyResponse represents the options passed to navigator.credentials
+type BeginPasskeyResponse struct {
+	CeremonyID string      `json:"ceremony_id"`
+	Options    interface{} `json:"options"`
+}
+
+// FinishPasskeyRegistrationRequest represents the authenticator's response to a registration
+type FinishPasskeyRegistrationRequest struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Name       string          `json:"name" binding:"omitempty,max=100"`
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+// FinishPasskeyLoginRequest represents the authenticator's response to a login
+type FinishPasskeyLoginRequest struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Credential json.RawMessage `json:"credential" binding:"required"`
+	DeviceName string          `json:"device_name,omitempty" binding:"omitempty,max=100"`
+}
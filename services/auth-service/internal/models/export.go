@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Data export statuses
+const (
+	ExportPending    = "pending"
+	ExportReady      = "ready"
+	ExportFailed     = "failed"
+	ExportDownloaded = "downloaded"
+	ExportExpired    = "expired"
+)
+
+// DataExport is an archive of everything stored about a user
+type DataExport struct {
+	ID           string     `json:"id" db:"id"`
+	UserID       int64      `json:"-" db:"user_id"`
+	Status       string     `json:"status" db:"status"`
+	FilePath     string     `json:"-" db:"file_path"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	DownloadedAt *time.Time `json:"downloaded_at,omitempty" db:"downloaded_at"`
+}
+
+// DataExportResponse represents an export with its download link once it is ready
+type DataExportResponse struct {
+	*DataExport
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// DataExportManifest describes the contents of an export archive
+type DataExportManifest struct {
+	UserID      int64     `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Sections    []string  `json:"sections"`
+}
+
+// TelegramExport is the telegram section of a data export
+type TelegramExport struct {
+	TelegramID *int64 `json:"telegram_id"`
+}
+
+// LoginRecord is an entry of the login_history section of a data export
+type LoginRecord struct {
+	LoggedInAt time.Time `json:"logged_in_at"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+}
+
+// TwoFactorExport is the two_factor section of a data export
+type TwoFactorExport struct {
+	Enabled bool `json:"enabled"`
+}
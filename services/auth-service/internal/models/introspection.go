@@ -0,0 +1,31 @@
+package models
+
+// Token types reported by token introspection
+const (
+	IntrospectedAccessToken = "access_token"
+	IntrospectedAPIKey      = "api_key"
+)
+
+// IntrospectionRequest represents an RFC 7662 introspection request
+type IntrospectionRequest struct {
+	Token         string `form:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"`
+}
+
+// IntrospectionResponse represents an RFC 7662 introspection response.
+// Inactive tokens only carry Active.
+type IntrospectionResponse struct {
+	Active        bool     `json:"active"`
+	Scope         string   `json:"scope,omitempty"`
+	Username      string   `json:"username,omitempty"`
+	TokenType     string   `json:"token_type,omitempty"`
+	ExpiresAt     int64    `json:"exp,omitempty"`
+	IssuedAt      int64    `json:"iat,omitempty"`
+	Subject       string   `json:"sub,omitempty"`
+	Issuer        string   `json:"iss,omitempty"`
+	Audience      []string `json:"aud,omitempty"`
+	TokenID       string   `json:"jti,omitempty"`
+	SessionID     string   `json:"sid,omitempty"`
+	EmailVerified bool     `json:"email_verified,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+}
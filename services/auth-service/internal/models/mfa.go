@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// TOTPCredential represents a user's authenticator app enrollment
+type TOTPCredential struct {
+	UserID          int64      `json:"user_id" db:"user_id"`
+	SecretEncrypted []byte     `json:"-" db:"secret_encrypted"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	LastUsedStep    int64      `json:"-" db:"last_used_step"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsConfirmed returns true if the enrollment has been confirmed with a valid code
+func (c *TOTPCredential) IsConfirmed() bool {
+	return c.ConfirmedAt != nil
+}
+
+// TOTPEnrollmentResponse represents the data needed to set up an authenticator app
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest represents a request carrying a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTOTPRequest represents request to turn off two-factor authentication
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// LoginMFARequest represents the second step of a login with two-factor authentication
+type LoginMFARequest struct {
+	MFAToken   string `json:"mfa_token" binding:"required"`
+	Code       string `json:"code" binding:"required"`
+	DeviceName string `json:"device_name,omitempty" binding:"omitempty,max=100"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// RateLimit is a token bucket: it holds up to Burst tokens and refills
+// Requests tokens every Period
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Capacity returns the bucket size
+func (l RateLimit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Interval returns the time it takes to refill a single token
+func (l RateLimit) Interval() time.Duration {
+	if l.Requests <= 0 {
+		return l.Period
+	}
+	return l.Period / time.Duration(l.Requests)
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+
+	// RetryAfter is the time until the next token is available when not allowed
+	RetryAfter time.Duration
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Built-in roles
+const (
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+)
+
+// Built-in permissions
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersWrite  = "users:write"
+	PermissionRolesManage = "roles:manage"
+)
+
+// Role is a named set of permissions that can be granted to users
+type Role struct {
+	ID          int64     `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Permissions []string  `json:"permissions" db:"-"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// AssignRoleRequest represents request to grant a role to a user
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,max=50"`
+}
+
+// UserRolesResponse represents the roles granted to a user
+type UserRolesResponse struct {
+	UserID int64    `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Session represents a logged-in device. Every refresh token belongs to
+// exactly one session, and rotation stays within it.
+type Session struct {
+	ID         string    `json:"id" db:"id"`
+	UserID     int64     `json:"-" db:"user_id"`
+	DeviceName string    `json:"device_name" db:"device_name"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	Current    bool      `json:"current" db:"-"`
+}
+
+// ClientInfo describes the client a session is created for
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
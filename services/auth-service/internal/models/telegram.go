@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// TelegramLinkCodePurpose is the one-time token purpose of bot deep-link codes
+const TelegramLinkCodePurpose = "telegram_link"
+
+// TelegramLinkCodeResponse represents a deep-link code for linking through the bot
+type TelegramLinkCodeResponse struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TelegramWebAppLoginRequest represents a login from a Telegram Mini App
+type TelegramWebAppLoginRequest struct {
+	InitData   string `json:"init_data" binding:"required"`
+	DeviceName string `json:"device_name,omitempty" binding:"omitempty,max=100"`
+}
+
+// TelegramUpdate represents the parts of a Bot API update the auth service reads
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message,omitempty"`
+}
+
+// TelegramMessage represents a Bot API message
+type TelegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	From      *TelegramUser `json:"from,omitempty"`
+	Chat      TelegramChat  `json:"chat"`
+	Text      string        `json:"text,omitempty"`
+}
+
+// TelegramUser represents a Bot API user
+type TelegramUser struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username,omitempty"`
+}
+
+// TelegramChat represents a Bot API chat
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
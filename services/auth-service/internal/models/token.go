@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RefreshToken represents a persisted refresh token
+type RefreshToken struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     int64      `json:"user_id" db:"user_id"`
+	SessionID  string     `json:"session_id" db:"session_id"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt     *time.Time `json:"used_at,omitempty" db:"used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	ReplacedBy *string    `json:"replaced_by,omitempty" db:"replaced_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OneTimeToken represents a single-use emailed token
+type OneTimeToken struct {
+	ID        string     `json:"id" db:"id"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	Purpose   string     `json:"purpose" db:"purpose"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
@@ -1,9 +1,8 @@
 package models
 
 import (
+	"strconv"
 	"time"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 // User represents a user in the system
@@ -15,30 +14,129 @@ type User struct {
 	TelegramID *int64    `json:"telegram_id,omitempty" db:"telegram_id"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	MustChangePassword bool       `json:"must_change_password" db:"must_change_password"`
+
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty" db:"suspended_at"`
+	SuspensionReason string     `json:"suspension_reason,omitempty" db:"suspension_reason"`
 }
 
 // CreateUserRequest represents request to create a new user
 type CreateUserRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Username string `json:"username" binding:"required,min=3,max=50"`
-	Password string `json:"password" binding:"required,min=6"`
+	Email      string `json:"email" binding:"required,email"`
+	Username   string `json:"username" binding:"required,min=3,max=50"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name,omitempty" binding:"omitempty,max=100"`
 }
 
 // LoginRequest represents login request
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name,omitempty" binding:"omitempty,max=100"`
 }
 
 // UpdateUserRequest represents request to update user profile
 type UpdateUserRequest struct {
 	Username *string `json:"username,omitempty" binding:"omitempty,min=3,max=50"`
-	Email    *string `json:"email,omitempty" binding:"omitempty,email"`
+	Email    *string `json:"email,omitempty" binding:"omitnil,email"`
 }
 
-// LinkTelegramRequest represents request to link Telegram account
+// LinkTelegramRequest represents request to link Telegram account with a
+// Telegram Login Widget payload
 type LinkTelegramRequest struct {
-	TelegramID int64 `json:"telegram_id" binding:"required"`
+	ID        int64  `json:"id" binding:"required"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+	PhotoURL  string `json:"photo_url,omitempty"`
+	AuthDate  int64  `json:"auth_date" binding:"required"`
+	Hash      string `json:"hash" binding:"required"`
+}
+
+// SignedFields returns the widget fields covered by the hash, as Telegram sent them
+func (r *LinkTelegramRequest) SignedFields() map[string]string {
+	fields := map[string]string{
+		"id":        strconv.FormatInt(r.ID, 10),
+		"auth_date": strconv.FormatInt(r.AuthDate, 10),
+		"hash":      r.Hash,
+	}
+
+	optional := map[string]string{
+		"first_name": r.FirstName,
+		"last_name":  r.LastName,
+		"username":   r.Username,
+		"photo_url":  r.PhotoURL,
+	}
+	for key, value := range optional {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
+
+// VerifyEmailRequest represents request to verify an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest represents request to send a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents request to set a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ChangePasswordRequest represents request to change the password of the current user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// SetPasswordRequest represents request to add a password to an account that has none
+type SetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ChangeExpiredPasswordRequest represents request to change a password that
+// must be changed before the next login
+type ChangeExpiredPasswordRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	DeviceName      string `json:"device_name,omitempty" binding:"omitempty,max=100"`
+}
+
+// DeleteAccountRequest represents request to delete the current user's
+// account. Password is required if the account has one, and Code if
+// two-factor authentication is enabled.
+type DeleteAccountRequest struct {
+	Password string `json:"password,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+// RestoreAccountRequest represents request to undo an account deletion
+type RestoreAccountRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AccountDeletionResponse tells the user how to undo a deletion
+type AccountDeletionResponse struct {
+	RestoreToken  string    `json:"restore_token"`
+	RestoreBefore time.Time `json:"restore_before"`
+}
+
+// ClearLockoutRequest represents request to lift a login lockout
+type ClearLockoutRequest struct {
+	Email     string `json:"email,omitempty" binding:"omitempty,email"`
+	IPAddress string `json:"ip_address,omitempty" binding:"omitempty,ip"`
 }
 
 // UserResponse represents user data in responses (without sensitive info)
@@ -49,22 +147,10 @@ type UserResponse struct {
 	TelegramID *int64    `json:"telegram_id,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
-}
 
-// HashPassword hashes the user's password using bcrypt
-func (u *User) HashPassword() error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return err
-	}
-	u.Password = string(hashedPassword)
-	return nil
-}
-
-// CheckPassword compares the provided password with the user's hashed password
-func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
-	return err == nil
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	HasPassword     bool       `json:"has_password"`
 }
 
 // ToResponse converts User to UserResponse (removes sensitive data)
@@ -76,5 +162,25 @@ func (u *User) ToResponse() *UserResponse {
 		TelegramID: u.TelegramID,
 		CreatedAt:  u.CreatedAt,
 		UpdatedAt:  u.UpdatedAt,
+
+		EmailVerified:   u.IsEmailVerified(),
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		HasPassword:     u.HasPassword(),
 	}
+}
+
+// HasPassword returns false for accounts created through Telegram that have
+// not set a password yet
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
+// IsEmailVerified returns true if the current email address has been verified
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsSuspended returns true if an admin has suspended the account
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
 }
\ No newline at end of file
@@ -0,0 +1,131 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// argon2idPrefix starts every Argon2id hash in PHC string format
+const argon2idPrefix = "$argon2id$"
+
+// Argon2Params are the Argon2id cost parameters
+type Argon2Params struct {
+	// Memory is in KiB
+	Memory     uint32
+	Time       uint32
+	Threads    uint8
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// bcryptHasher hashes with bcrypt at a fixed cost
+type bcryptHasher struct {
+	cost int
+}
+
+func (b *bcryptHasher) hash(secret []byte) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(secret, b.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b *bcryptHasher) verify(secret []byte, encoded string) (bool, bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), secret)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, false, err
+	}
+
+	return true, cost != b.cost, nil
+}
+
+func (b *bcryptHasher) owns(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// argon2idHasher hashes with Argon2id and encodes hashes in PHC string format:
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+type argon2idHasher struct {
+	params Argon2Params
+}
+
+func (a *argon2idHasher) hash(secret []byte) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey(secret, salt, a.params.Time, a.params.Memory, a.params.Threads, a.params.KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		a.params.Memory, a.params.Time, a.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *argon2idHasher) verify(secret []byte, encoded string) (bool, bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	candidate := argon2.IDKey(secret, salt, params.Time, params.Memory, params.Threads, params.KeyLength)
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return false, false, nil
+	}
+
+	outdated := params.Memory != a.params.Memory || params.Time != a.params.Time ||
+		params.Threads != a.params.Threads || params.KeyLength != a.params.KeyLength
+	return true, outdated, nil
+}
+
+func (a *argon2idHasher) owns(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+// decodeArgon2id parses an Argon2id hash in PHC string format
+func decodeArgon2id(encoded string) (*Argon2Params, []byte, []byte, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 6 {
+		return nil, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2id version")
+	}
+
+	params := &Argon2Params{}
+	if _, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return nil, nil, nil, fmt.Errorf("malformed argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("malformed argon2id salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
@@ -0,0 +1,188 @@
+package passwords
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// bloomMagic starts a serialized bloom filter file
+var bloomMagic = []byte("RHYBLOOM")
+
+// BloomFilter is a compact set of breached password SHA-1 hashes. It can
+// report false positives at the rate it was built for, but never false negatives.
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint32
+}
+
+// NewBloomFilter creates an empty filter sized for n entries at the given false positive rate
+func NewBloomFilter(n int, falsePositiveRate float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+
+	size := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint32(math.Max(1, math.Round(float64(size)/float64(n)*math.Ln2)))
+
+	return &BloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// AddHash adds a SHA-1 digest to the filter
+func (f *BloomFilter) AddHash(digest [sha1.Size]byte) {
+	h1, h2 := splitDigest(digest)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.size
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// Contains reports whether the password may be in the filter
+func (f *BloomFilter) Contains(password string) bool {
+	h1, h2 := splitDigest(sha1.Sum([]byte(password)))
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.size
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo serializes the filter so it can be loaded without the hash list
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, len(bloomMagic)+12)
+	copy(header, bloomMagic)
+	binary.BigEndian.PutUint64(header[len(bloomMagic):], f.size)
+	binary.BigEndian.PutUint32(header[len(bloomMagic)+8:], f.hashes)
+
+	written, err := w.Write(header)
+	if err != nil {
+		return int64(written), err
+	}
+	if err := binary.Write(w, binary.BigEndian, f.bits); err != nil {
+		return int64(written), err
+	}
+
+	return int64(written + len(f.bits)*8), nil
+}
+
+// LoadBreachedFilter loads a serialized bloom filter, or builds one from a
+// list of SHA-1 hashes with one hash per line, optionally followed by
+// ":count" as in the Have I Been Pwned downloads
+func LoadBreachedFilter(path string, falsePositiveRate float64) (*BloomFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 1<<20)
+	magic, err := reader.Peek(len(bloomMagic))
+	if err == nil && bytes.Equal(magic, bloomMagic) {
+		return readBloomFilter(reader)
+	}
+
+	// Count the entries first so the filter is sized for the list
+	lines, err := countLines(reader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	filter := NewBloomFilter(lines, falsePositiveRate)
+	if err := filter.addHashList(file); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// readBloomFilter reads a filter written by WriteTo
+func readBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, len(bloomMagic)+12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("invalid bloom filter header: %w", err)
+	}
+
+	size := binary.BigEndian.Uint64(header[len(bloomMagic):])
+	hashes := binary.BigEndian.Uint32(header[len(bloomMagic)+8:])
+	if size == 0 || hashes == 0 {
+		return nil, fmt.Errorf("invalid bloom filter header")
+	}
+
+	filter := &BloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+	if err := binary.Read(r, binary.BigEndian, filter.bits); err != nil {
+		return nil, fmt.Errorf("invalid bloom filter data: %w", err)
+	}
+
+	return filter, nil
+}
+
+// addHashList adds every hash of a hash list to the filter
+func (f *BloomFilter) addHashList(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		var digest [sha1.Size]byte
+		if len(hash) != hex.EncodedLen(sha1.Size) {
+			return fmt.Errorf("line %d: not a SHA-1 hash", lineNumber)
+		}
+		if _, err := hex.Decode(digest[:], []byte(hash)); err != nil {
+			return fmt.Errorf("line %d: not a SHA-1 hash", lineNumber)
+		}
+
+		f.AddHash(digest)
+	}
+
+	return scanner.Err()
+}
+
+// countLines counts the lines of a reader
+func countLines(r io.Reader) (int, error) {
+	buf := make([]byte, 1<<16)
+	count := 0
+	for {
+		n, err := r.Read(buf)
+		count += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			return count + 1, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// splitDigest derives the two hashes used for double hashing from a digest,
+// which is already uniformly distributed
+func splitDigest(digest [sha1.Size]byte) (uint64, uint64) {
+	h1 := binary.BigEndian.Uint64(digest[0:8])
+	h2 := binary.BigEndian.Uint64(digest[8:16]) | 1
+	return h1, h2
+}
@@ -0,0 +1,160 @@
+package passwords
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sha1Hex returns the hex SHA-1 of a password as in breach hash lists
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeFile writes a test file and returns its path
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestBloomFilterContains(t *testing.T) {
+	breached := []string{"password", "123456", "correct horse battery staple"}
+
+	filter := NewBloomFilter(len(breached), 0.001)
+	for _, password := range breached {
+		filter.AddHash(sha1.Sum([]byte(password)))
+	}
+
+	for _, password := range breached {
+		if !filter.Contains(password) {
+			t.Errorf("Contains(%q) = false, want true", password)
+		}
+	}
+	if filter.Contains("zebra quasar mellow tundra") {
+		t.Error("Contains of a password that was not added = true")
+	}
+}
+
+func TestBloomFilterRoundTrip(t *testing.T) {
+	filter := NewBloomFilter(100, 0.01)
+	for _, password := range []string{"password", "letmein", "trustno1"} {
+		filter.AddHash(sha1.Sum([]byte(password)))
+	}
+
+	var buf bytes.Buffer
+	written, err := filter.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if written != int64(buf.Len()) {
+		t.Errorf("WriteTo = %d bytes, wrote %d", written, buf.Len())
+	}
+
+	read, err := readBloomFilter(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("readBloomFilter failed: %v", err)
+	}
+	if !reflect.DeepEqual(read, filter) {
+		t.Error("filter read back differs from the written one")
+	}
+
+	// A serialized filter is recognized by its magic and not sized again
+	loaded, err := LoadBreachedFilter(writeFile(t, "breached.bloom", buf.Bytes()), 0.5)
+	if err != nil {
+		t.Fatalf("LoadBreachedFilter failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, filter) {
+		t.Error("loaded filter differs from the written one")
+	}
+}
+
+func TestReadBloomFilterErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewBloomFilter(10, 0.01).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated header", data: data[:len(bloomMagic)+4]},
+		{name: "truncated bits", data: data[:len(data)-1]},
+		{name: "zero size", data: append(append([]byte{}, bloomMagic...), make([]byte, 12)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readBloomFilter(bytes.NewReader(tt.data)); err == nil {
+				t.Error("readBloomFilter succeeded")
+			}
+		})
+	}
+}
+
+func TestLoadBreachedFilterHashList(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		wantErr string
+	}{
+		{
+			name: "hashes with counts",
+			list: sha1Hex("password") + ":3861493\n" + sha1Hex("123456") + ":37359195\n",
+		},
+		{
+			name: "lowercase hashes without counts",
+			list: strings.ToLower(sha1Hex("password")) + "\n" + strings.ToLower(sha1Hex("123456")),
+		},
+		{
+			name: "CRLF line endings and blank lines",
+			list: sha1Hex("password") + ":1\r\n\r\n" + sha1Hex("123456") + ":2\r\n",
+		},
+		{
+			name:    "short hash",
+			list:    sha1Hex("password") + ":1\n" + sha1Hex("123456")[:39] + ":2\n",
+			wantErr: "line 2: not a SHA-1 hash",
+		},
+		{
+			name:    "not hex",
+			list:    strings.Repeat("Z", 40) + ":1\n",
+			wantErr: "line 1: not a SHA-1 hash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := LoadBreachedFilter(writeFile(t, "pwned.txt", []byte(tt.list)), 0.001)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("LoadBreachedFilter error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadBreachedFilter failed: %v", err)
+			}
+
+			for _, password := range []string{"password", "123456"} {
+				if !filter.Contains(password) {
+					t.Errorf("Contains(%q) = false, want true", password)
+				}
+			}
+			if filter.Contains("zebra quasar mellow tundra") {
+				t.Error("Contains of a password that is not listed = true")
+			}
+		})
+	}
+}
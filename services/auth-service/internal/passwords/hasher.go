@@ -0,0 +1,209 @@
+package passwords
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Supported hashing algorithms
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+// pepperedBcryptPrefix marks bcrypt hashes of peppered passwords, which have
+// no parameter field of their own to carry the pepper id
+const pepperedBcryptPrefix = "$bcrypt-hmac$"
+
+// PasswordHasher hashes and verifies passwords
+type PasswordHasher interface {
+	// Hash returns the encoded hash of a password including its parameters
+	Hash(ctx context.Context, password string) (string, error)
+
+	// Verify checks a password against an encoded hash. needsRehash is true
+	// when the hash was made with other than the current parameters.
+	Verify(ctx context.Context, password string, encoded string) (ok bool, needsRehash bool, err error)
+}
+
+// HasherConfig selects the algorithm and parameters of new hashes
+type HasherConfig struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+
+	// Pepper is a server-side secret mixed into every password before
+	// hashing. PreviousPeppers keep hashes made before a rotation verifiable.
+	Pepper          string
+	PreviousPeppers []string
+
+	// Workers bounds the number of concurrent hash operations, defaulting to the number of CPUs
+	Workers int
+}
+
+// algorithm is a single hashing scheme
+type algorithm interface {
+	hash(secret []byte) (string, error)
+
+	// verify returns whether the secret matches and whether the hash parameters
+	// differ from the current ones
+	verify(secret []byte, encoded string) (bool, bool, error)
+
+	// owns reports whether an encoded hash was made by the algorithm
+	owns(encoded string) bool
+}
+
+// hasher implements PasswordHasher on top of the configured algorithm and a worker pool
+type hasher struct {
+	current    algorithm
+	algorithms []algorithm
+	pepperID   string
+	peppers    map[string][]byte
+	pool       *workerPool
+}
+
+// NewPasswordHasher creates a password hasher from its configuration
+func NewPasswordHasher(config HasherConfig) (PasswordHasher, error) {
+	bcryptAlgorithm := &bcryptHasher{cost: config.BcryptCost}
+	argon2Algorithm := &argon2idHasher{params: config.Argon2}
+
+	h := &hasher{
+		algorithms: []algorithm{bcryptAlgorithm, argon2Algorithm},
+		peppers:    make(map[string][]byte),
+	}
+
+	switch config.Algorithm {
+	case Bcrypt:
+		h.current = bcryptAlgorithm
+	case Argon2id:
+		h.current = argon2Algorithm
+	default:
+		return nil, fmt.Errorf("unsupported password hashing algorithm %q", config.Algorithm)
+	}
+
+	for _, pepper := range config.PreviousPeppers {
+		h.peppers[pepperID(pepper)] = []byte(pepper)
+	}
+	if config.Pepper != "" {
+		h.pepperID = pepperID(config.Pepper)
+		h.peppers[h.pepperID] = []byte(config.Pepper)
+	}
+
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	h.pool = newWorkerPool(workers)
+
+	return h, nil
+}
+
+// Hash returns the encoded hash of a password including its parameters
+func (h *hasher) Hash(ctx context.Context, password string) (string, error) {
+	secret := h.applyPepper(password, h.pepperID)
+
+	var encoded string
+	err := h.pool.run(ctx, func() error {
+		var err error
+		encoded, err = h.current.hash(secret)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return h.encodePepper(encoded), nil
+}
+
+// Verify checks a password against an encoded hash
+func (h *hasher) Verify(ctx context.Context, password string, encoded string) (bool, bool, error) {
+	encoded, id, err := h.decodePepper(encoded)
+	if err != nil {
+		return false, false, err
+	}
+	if _, ok := h.peppers[id]; id != "" && !ok {
+		return false, false, fmt.Errorf("password hash uses an unknown pepper")
+	}
+
+	var alg algorithm
+	for _, candidate := range h.algorithms {
+		if candidate.owns(encoded) {
+			alg = candidate
+			break
+		}
+	}
+	if alg == nil {
+		return false, false, fmt.Errorf("unrecognized password hash format")
+	}
+
+	var ok, outdated bool
+	err = h.pool.run(ctx, func() error {
+		var err error
+		ok, outdated, err = alg.verify(h.applyPepper(password, id), encoded)
+		return err
+	})
+	if err != nil || !ok {
+		return false, false, err
+	}
+
+	needsRehash := alg != h.current || outdated || id != h.pepperID
+	return true, needsRehash, nil
+}
+
+// applyPepper keys the password with the pepper of the given id. The
+// HMAC output also keeps long passwords within bcrypt's 72 byte limit.
+func (h *hasher) applyPepper(password string, id string) []byte {
+	if id == "" {
+		return []byte(password)
+	}
+
+	mac := hmac.New(sha256.New, h.peppers[id])
+	mac.Write([]byte(password))
+	return []byte(base64.RawStdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// encodePepper records the pepper id in a new hash
+func (h *hasher) encodePepper(encoded string) string {
+	if h.pepperID == "" {
+		return encoded
+	}
+
+	if fields := strings.Split(encoded, "$"); strings.HasPrefix(encoded, argon2idPrefix) && len(fields) == 6 {
+		// The PHC string format has a keyid parameter for exactly this
+		fields[3] += ",keyid=" + h.pepperID
+		return strings.Join(fields, "$")
+	}
+
+	return pepperedBcryptPrefix + "keyid=" + h.pepperID + encoded
+}
+
+// decodePepper splits an encoded hash into the hash without the pepper id and the pepper id
+func (h *hasher) decodePepper(encoded string) (string, string, error) {
+	if rest, ok := strings.CutPrefix(encoded, pepperedBcryptPrefix+"keyid="); ok {
+		id, hash, found := strings.Cut(rest, "$")
+		if !found {
+			return "", "", fmt.Errorf("malformed password hash")
+		}
+		return "$" + hash, id, nil
+	}
+
+	if fields := strings.Split(encoded, "$"); strings.HasPrefix(encoded, argon2idPrefix) && len(fields) == 6 {
+		params, id, found := strings.Cut(fields[3], ",keyid=")
+		if found {
+			fields[3] = params
+			return strings.Join(fields, "$"), id, nil
+		}
+	}
+
+	return encoded, "", nil
+}
+
+// pepperID derives a short public identifier of a pepper
+func pepperID(pepper string) string {
+	sum := sha256.Sum256([]byte("pepper:" + pepper))
+	return base64.RawURLEncoding.EncodeToString(sum[:6])
+}
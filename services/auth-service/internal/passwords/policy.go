@@ -0,0 +1,114 @@
+package passwords
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation codes returned to clients
+const (
+	TooShort         = "TOO_SHORT"
+	TooLong          = "TOO_LONG"
+	MissingUppercase = "MISSING_UPPERCASE"
+	MissingLowercase = "MISSING_LOWERCASE"
+	MissingDigit     = "MISSING_DIGIT"
+	MissingSymbol    = "MISSING_SYMBOL"
+	TooWeak          = "TOO_WEAK"
+	ContainsEmail    = "CONTAINS_EMAIL"
+	ContainsUsername = "CONTAINS_USERNAME"
+	Breached         = "BREACHED"
+)
+
+// MaxLength is the longest accepted password. It is also the most bcrypt
+// hashes without truncating when no pepper is configured.
+const MaxLength = 72
+
+// minPersonalInputLength is the shortest username or email local part that
+// is searched for in passwords
+const minPersonalInputLength = 3
+
+// Violation is a single reason a password was rejected
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BreachedChecker reports whether a password is part of a known breach
+type BreachedChecker interface {
+	Contains(password string) bool
+}
+
+// Policy describes the requirements for new passwords
+type Policy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+
+	// MinScore is the lowest accepted strength score from 0 to 4
+	MinScore int
+
+	// Breached rejects known breached passwords when set
+	Breached BreachedChecker
+}
+
+// Check returns every requirement the password does not meet. Email and
+// username of the account are used to reject passwords derived from them.
+func (p *Policy) Check(password string, email string, username string) []Violation {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, Violation{TooShort, fmt.Sprintf("Must be at least %d characters", p.MinLength)})
+	}
+	if len(password) > MaxLength {
+		violations = append(violations, Violation{TooLong, fmt.Sprintf("Must be at most %d bytes", MaxLength)})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, Violation{MissingUppercase, "Must contain an uppercase letter"})
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, Violation{MissingLowercase, "Must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{MissingDigit, "Must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{MissingSymbol, "Must contain a symbol"})
+	}
+
+	lower := strings.ToLower(password)
+	localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+	if len(localPart) >= minPersonalInputLength && strings.Contains(lower, localPart) {
+		violations = append(violations, Violation{ContainsEmail, "Must not contain your email address"})
+	}
+	if len(username) >= minPersonalInputLength && strings.Contains(lower, strings.ToLower(username)) {
+		violations = append(violations, Violation{ContainsUsername, "Must not contain your username"})
+	}
+
+	if p.MinScore > 0 && Score(password, email, localPart, username) < p.MinScore {
+		violations = append(violations, Violation{TooWeak, "Is too easy to guess, try a longer password or an uncommon phrase"})
+	}
+
+	if p.Breached != nil && p.Breached.Contains(password) {
+		violations = append(violations, Violation{Breached, "Has appeared in a data breach, choose a different password"})
+	}
+
+	return violations
+}
@@ -0,0 +1,148 @@
+package passwords
+
+import (
+	"testing"
+)
+
+// breachedSet is a BreachedChecker over a fixed set of passwords
+type breachedSet map[string]bool
+
+func (s breachedSet) Contains(password string) bool {
+	return s[password]
+}
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		email    string
+		username string
+		want     []string
+	}{
+		{
+			name:     "accepted",
+			policy:   Policy{MinLength: 8},
+			password: "long enough",
+		},
+		{
+			name:     "too short",
+			policy:   Policy{MinLength: 8},
+			password: "short",
+			want:     []string{TooShort},
+		},
+		{
+			name:     "length counts characters, not bytes",
+			policy:   Policy{MinLength: 8},
+			password: "ääääääää",
+		},
+		{
+			name:     "too long",
+			policy:   Policy{MinLength: 8},
+			password: "a very long passphrase that goes on and on and on well past the bcrypt limit",
+			want:     []string{TooLong},
+		},
+		{
+			name:     "every character class missing",
+			policy:   Policy{RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true},
+			password: "        ",
+			want:     []string{MissingUppercase, MissingLowercase, MissingDigit, MissingSymbol},
+		},
+		{
+			name:     "every character class present",
+			policy:   Policy{RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true},
+			password: "Abc1 def!",
+		},
+		{
+			name:     "non-ASCII letters count as upper and lower case",
+			policy:   Policy{RequireUppercase: true, RequireLowercase: true},
+			password: "Ärger über",
+		},
+		{
+			name:     "space is not a symbol",
+			policy:   Policy{RequireSymbol: true},
+			password: "two words",
+			want:     []string{MissingSymbol},
+		},
+		{
+			name:     "contains the email local part",
+			policy:   Policy{},
+			password: "my Ada.Lovelace password",
+			email:    "ada.lovelace@example.com",
+			want:     []string{ContainsEmail},
+		},
+		{
+			name:     "email domain alone is fine",
+			policy:   Policy{},
+			password: "example.com rocks",
+			email:    "ada.lovelace@example.com",
+		},
+		{
+			name:     "short email local part is not searched",
+			policy:   Policy{},
+			password: "jo jo jo jo",
+			email:    "jo@example.com",
+		},
+		{
+			name:     "contains the username",
+			policy:   Policy{},
+			password: "ilikeADAbytes",
+			username: "ada",
+			want:     []string{ContainsUsername},
+		},
+		{
+			name:     "short username is not searched",
+			policy:   Policy{},
+			password: "go go go go",
+			username: "go",
+		},
+		{
+			name:     "too weak",
+			policy:   Policy{MinScore: 2},
+			password: "password1",
+			want:     []string{TooWeak},
+		},
+		{
+			name:     "strong enough",
+			policy:   Policy{MinScore: 2},
+			password: "zebra quasar mellow tundra",
+		},
+		{
+			name:     "breached",
+			policy:   Policy{Breached: breachedSet{"zebra quasar mellow tundra": true}},
+			password: "zebra quasar mellow tundra",
+			want:     []string{Breached},
+		},
+		{
+			name:     "violations are reported together",
+			policy:   Policy{MinLength: 12, RequireDigit: true, MinScore: 3, Breached: breachedSet{"adalove": true}},
+			password: "adalove",
+			email:    "ada@example.com",
+			username: "adalove",
+			want:     []string{TooShort, MissingDigit, ContainsEmail, ContainsUsername, TooWeak, Breached},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.policy.Check(tt.password, tt.email, tt.username)
+
+			codes := make([]string, len(violations))
+			for i, violation := range violations {
+				codes[i] = violation.Code
+				if violation.Message == "" {
+					t.Errorf("violation %s has no message", violation.Code)
+				}
+			}
+
+			if len(codes) != len(tt.want) {
+				t.Fatalf("Check = %v, want %v", codes, tt.want)
+			}
+			for i := range codes {
+				if codes[i] != tt.want[i] {
+					t.Fatalf("Check = %v, want %v", codes, tt.want)
+				}
+			}
+		})
+	}
+}
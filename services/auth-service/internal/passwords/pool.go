@@ -0,0 +1,48 @@
+package passwords
+
+import "context"
+
+// workerPool runs CPU-heavy hash operations on a fixed number of goroutines
+// so a burst of logins queues up instead of starving request handling
+type workerPool struct {
+	jobs chan func()
+}
+
+// newWorkerPool starts a pool with the given number of workers
+func newWorkerPool(workers int) *workerPool {
+	p := &workerPool{
+		jobs: make(chan func()),
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+
+	return p
+}
+
+// run executes fn on a worker and waits for it. It gives up when ctx is
+// done before a worker is free or before fn finishes.
+func (p *workerPool) run(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	job := func() {
+		done <- fn()
+	}
+
+	select {
+	case p.jobs <- job:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
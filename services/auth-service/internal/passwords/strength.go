@@ -0,0 +1,254 @@
+package passwords
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"time"
+)
+
+// Score estimates how hard a password is to guess on the zxcvbn scale:
+// 0 too guessable, 1 very guessable, 2 somewhat guessable, 3 safely
+// unguessable, 4 very unguessable. userInputs such as the email or username
+// are treated as the most likely dictionary words.
+func Score(password string, userInputs ...string) int {
+	guesses := estimateGuesses(password, userInputs)
+
+	switch {
+	case guesses < 1e3+5:
+		return 0
+	case guesses < 1e6+5:
+		return 1
+	case guesses < 1e8+5:
+		return 2
+	case guesses < 1e10+5:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Estimator tunables, following zxcvbn
+const (
+	bruteforceCardinality = 10
+	minSubmatchGuesses    = 50
+	minYearSpace          = 20
+	minMatchLength        = 3
+)
+
+//go:embed common.txt
+var commonList string
+
+// commonRanks maps common passwords and words to their frequency rank
+var commonRanks = rankWords(strings.Fields(commonList))
+
+// keyboardRows are sequences of adjacent keys on a QWERTY keyboard
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm", "qazwsxedc"}
+
+// leetReplacer undoes common character substitutions
+var leetReplacer = strings.NewReplacer("@", "a", "4", "a", "3", "e", "1", "i", "!", "i", "0", "o", "$", "s", "5", "s", "7", "t", "+", "t")
+
+// match is a guessable pattern covering runes [start, end) of the password
+type match struct {
+	start, end int
+	guesses    float64
+}
+
+// estimateGuesses finds the sequence of patterns and brute-forced runes that
+// is cheapest to guess and returns its number of guesses
+func estimateGuesses(password string, userInputs []string) float64 {
+	runes := []rune(password)
+	n := len(runes)
+	if n == 0 {
+		return 1
+	}
+
+	userRanks := rankWords(userInputs)
+	matches := findMatches(runes, userRanks)
+
+	// best[j] is the log10 of the guesses needed for the first j runes
+	best := make([]float64, n+1)
+	for j := 1; j <= n; j++ {
+		best[j] = best[j-1] + math.Log10(bruteforceCardinality)
+		for _, m := range matches {
+			if m.end != j {
+				continue
+			}
+			guesses := m.guesses
+			if m.end-m.start < n {
+				guesses = math.Max(guesses, minSubmatchGuesses)
+			}
+			if cost := best[m.start] + math.Log10(guesses); cost < best[j] {
+				best[j] = cost
+			}
+		}
+	}
+
+	return math.Pow(10, best[n])
+}
+
+// findMatches returns every dictionary, keyboard, sequence, repeat and year
+// pattern in the password
+func findMatches(runes []rune, userRanks map[string]int) []match {
+	var matches []match
+	n := len(runes)
+	lower := []rune(strings.ToLower(string(runes)))
+
+	for i := 0; i < n; i++ {
+		for j := i + minMatchLength; j <= n; j++ {
+			token := string(lower[i:j])
+			original := string(runes[i:j])
+
+			if guesses, ok := dictionaryGuesses(token, original, userRanks); ok {
+				matches = append(matches, match{i, j, guesses})
+			}
+			if guesses, ok := keyboardGuesses(token); ok {
+				matches = append(matches, match{i, j, guesses})
+			}
+			if guesses, ok := yearGuesses(token); ok {
+				matches = append(matches, match{i, j, guesses})
+			}
+		}
+	}
+
+	matches = append(matches, runMatches(lower)...)
+	return matches
+}
+
+// dictionaryGuesses looks a token up in the user inputs and the common word
+// list, directly, reversed and with substitutions undone
+func dictionaryGuesses(token string, original string, userRanks map[string]int) (float64, bool) {
+	rank, variations := 0, 1.0
+
+	lookup := func(word string) int {
+		if r, ok := userRanks[word]; ok {
+			return r
+		}
+		return commonRanks[word]
+	}
+
+	if r := lookup(token); r > 0 {
+		rank = r
+	} else if r := lookup(reverse(token)); r > 0 {
+		rank, variations = r, 2
+	} else if unleeted := leetReplacer.Replace(token); unleeted != token {
+		if r := lookup(unleeted); r > 0 {
+			rank, variations = r, 2
+		}
+	}
+	if rank == 0 {
+		return 0, false
+	}
+
+	// Capitalizing only the first letter is the most common variation
+	if original != token {
+		if strings.ToUpper(original[:1])+token[1:] == original {
+			variations *= 2
+		} else {
+			variations *= 8
+		}
+	}
+
+	return float64(rank) * variations, true
+}
+
+// keyboardGuesses matches runs of adjacent keys such as "qwerty" or "4321"
+func keyboardGuesses(token string) (float64, bool) {
+	if len(token) < 4 {
+		return 0, false
+	}
+
+	for _, row := range keyboardRows {
+		if strings.Contains(row, token) || strings.Contains(row, reverse(token)) {
+			return float64(len(row) * len(token) * 2), true
+		}
+	}
+
+	return 0, false
+}
+
+// yearGuesses matches recent and near-future years
+func yearGuesses(token string) (float64, bool) {
+	if len(token) != 4 || strings.Trim(token, "0123456789") != "" {
+		return 0, false
+	}
+
+	year := int(token[0]-'0')*1000 + int(token[1]-'0')*100 + int(token[2]-'0')*10 + int(token[3]-'0')
+	if year < 1900 || year > 2099 {
+		return 0, false
+	}
+
+	space := math.Abs(float64(year - time.Now().Year()))
+	return math.Max(space, minYearSpace), true
+}
+
+// runMatches finds repeated characters ("aaaa") and sequences ("abcd", "9876")
+func runMatches(lower []rune) []match {
+	var matches []match
+	n := len(lower)
+
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && lower[j] == lower[i] {
+			j++
+		}
+		if j-i >= minMatchLength {
+			matches = append(matches, match{i, j, float64(charsetSize(lower[i]) * (j - i))})
+		}
+		i = j
+	}
+
+	for i := 0; i+1 < n; {
+		delta := lower[i+1] - lower[i]
+		j := i + 1
+		for j+1 < n && lower[j+1]-lower[j] == delta {
+			j++
+		}
+		if (delta == 1 || delta == -1) && j-i+1 >= minMatchLength {
+			guesses := float64(charsetSize(lower[i]) * (j - i + 1))
+			if delta < 0 {
+				guesses *= 2
+			}
+			matches = append(matches, match{i, j + 1, guesses})
+		}
+		i = j
+	}
+
+	return matches
+}
+
+// charsetSize returns the size of the character class a rune belongs to
+func charsetSize(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return 10
+	case r >= 'a' && r <= 'z':
+		return 26
+	default:
+		return 33
+	}
+}
+
+// rankWords ranks words by their position in the list, ignoring short ones
+func rankWords(words []string) map[string]int {
+	ranks := make(map[string]int, len(words))
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if len(word) < minMatchLength {
+			continue
+		}
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = i + 1
+		}
+	}
+	return ranks
+}
+
+// reverse reverses a string rune by rune
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
@@ -0,0 +1,47 @@
+package passwords
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		want       int
+	}{
+		{name: "empty", password: "", want: 0},
+		{name: "common password", password: "password", want: 0},
+		{name: "capitalized common password with digit", password: "Password1", want: 0},
+		{name: "substitutions", password: "p@ssw0rd", want: 0},
+		{name: "reversed", password: "drowssap", want: 0},
+		{name: "keyboard row", password: "zxcvbnm", want: 0},
+		{name: "repeated character", password: "aaaaaaaa", want: 0},
+		{name: "sequence", password: "abcdefgh", want: 0},
+		{name: "descending digits", password: "98765432", want: 0},
+		{name: "user input", password: "adalovelace", userInputs: []string{"ada@example.com", "adalovelace"}, want: 0},
+
+		// Random characters cost 10 guesses each
+		{name: "4 random characters", password: "xK9#", want: 1},
+		{name: "8 random characters", password: "xK9#mQ2v", want: 2},
+		{name: "10 random characters", password: "xK9#mQ2vLp", want: 3},
+		{name: "12 random characters", password: "xK9#mQ2vLp7$", want: 4},
+		{name: "passphrase", password: "correct horse battery staple", want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Score(tt.password, tt.userInputs...); got != tt.want {
+				t.Errorf("Score(%q) = %d, want %d", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreUserInputs(t *testing.T) {
+	// The same password is only guessable once it is known to be personal
+	without := Score("adalovelace")
+	with := Score("adalovelace", "adalovelace")
+	if with >= without {
+		t.Errorf("Score with the username as input = %d, want less than %d", with, without)
+	}
+}
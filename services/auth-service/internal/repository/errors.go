@@ -0,0 +1,11 @@
+package repository
+
+import "errors"
+
+// ErrNotFound is wrapped by repository errors for rows that do not exist, so
+// callers can tell them apart from database failures with errors.Is
+var ErrNotFound = errors.New("not found")
+
+// ErrTelegramIDTaken is wrapped by errors restoring a user whose Telegram
+// account has been linked to another user in the meantime
+var ErrTelegramIDTaken = errors.New("telegram id is taken")
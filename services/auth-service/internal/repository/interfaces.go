@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"rhythmify/services/auth-service/internal/models"
 )
@@ -28,13 +29,305 @@ type UserRepository interface {
 	
 	// LinkTelegram links a Telegram ID to a user
 	LinkTelegram(ctx context.Context, userID int64, telegramID int64) error
+
+	// UnlinkTelegram removes the Telegram ID from a user
+	UnlinkTelegram(ctx context.Context, userID int64) error
+
+	// UpdatePassword replaces the user's password hash and clears the forced change flag
+	UpdatePassword(ctx context.Context, userID int64, passwordHash string) error
+
+	// RehashPassword replaces a password hash with an upgraded hash of the same
+	// password, unless the password has been changed in the meantime
+	RehashPassword(ctx context.Context, userID int64, oldHash string, newHash string) error
+
+	// SetMustChangePassword sets or clears the forced password change flag
+	SetMustChangePassword(ctx context.Context, userID int64, mustChange bool) error
+
+	// MarkEmailVerified marks the user's email as verified if it still matches email
+	MarkEmailVerified(ctx context.Context, userID int64, email string) error
 	
-	// Delete soft deletes a user (if needed in future)
+	// Delete soft deletes a user and frees its Telegram link. Soft deleted
+	// users are ignored by every lookup and uniqueness check.
 	Delete(ctx context.Context, id int64) error
+
+	// Restore undoes the soft deletion of a user deleted after deletedAfter
+	// and links its Telegram account again. It fails with ErrTelegramIDTaken
+	// if that account has been linked to another user in the meantime.
+	Restore(ctx context.Context, id int64, deletedAfter time.Time) error
+
+	// PurgeDeleted permanently removes users soft deleted before deletedBefore
+	// and returns how many were removed
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
 	
 	// CheckEmailExists checks if email already exists
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
 	
 	// CheckUsernameExists checks if username already exists
 	CheckUsernameExists(ctx context.Context, username string) (bool, error)
-}
\ No newline at end of file
+
+	// Search retrieves up to limit users matching the filter with an ID
+	// below beforeID (0 for the first page), newest first
+	Search(ctx context.Context, filter *models.UserFilter, beforeID int64, limit int) ([]*models.User, error)
+
+	// SetSuspended suspends a user with a reason, or lifts the suspension
+	// when suspended is false
+	SetSuspended(ctx context.Context, userID int64, suspended bool, reason string) error
+}
+
+// RefreshTokenRepository defines the interface for refresh token storage
+type RefreshTokenRepository interface {
+	// Create stores a newly issued refresh token
+	Create(ctx context.Context, token *models.RefreshToken) error
+
+	// GetByID retrieves a refresh token by its jti
+	GetByID(ctx context.Context, id string) (*models.RefreshToken, error)
+
+	// MarkUsed atomically marks a token as rotated. It returns false if the
+	// token was already used or revoked.
+	MarkUsed(ctx context.Context, id string, replacedBy string) (bool, error)
+
+	// RevokeBySession revokes every unused token of a session
+	RevokeBySession(ctx context.Context, sessionID string) error
+
+	// DeleteExpired deletes tokens that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SessionRepository defines the interface for session storage
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *models.Session) error
+
+	// ListByUser returns the unexpired sessions of a user, most recently used first
+	ListByUser(ctx context.Context, userID int64) ([]*models.Session, error)
+
+	// Touch updates last_used_at of a session and extends it until expiresAt,
+	// the expiry of its newest refresh token. It fails if the session no
+	// longer exists.
+	Touch(ctx context.Context, id string, expiresAt time.Time) error
+
+	// DeleteForUser deletes a session of the given user together with its refresh tokens
+	DeleteForUser(ctx context.Context, id string, userID int64) error
+
+	// DeleteAllForUser deletes every session of a user except exceptID (may be empty)
+	// and returns the IDs of the deleted sessions
+	DeleteAllForUser(ctx context.Context, userID int64, exceptID string) ([]string, error)
+
+	// DeleteExpired deletes sessions that expired before the given time
+	// together with their refresh tokens
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TokenDenylist defines the interface for revoking tokens before they expire
+type TokenDenylist interface {
+	// RevokeToken denies a single token by its jti until it expires
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// IsTokenRevoked checks if a token jti has been denied
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+
+	// RevokeUserTokens denies every token of a user issued at or before the given time.
+	// The entry is kept for ttl, which should cover the longest access token lifetime.
+	RevokeUserTokens(ctx context.Context, userID int64, issuedBefore time.Time, ttl time.Duration) error
+
+	// UserTokensRevokedAt returns the cutoff set by RevokeUserTokens, or zero time if none
+	UserTokensRevokedAt(ctx context.Context, userID int64) (time.Time, error)
+
+	// RevokeSession denies every token of a session for ttl
+	RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error
+
+	// IsSessionRevoked checks if a session has been denied
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// OneTimeTokenRepository defines the interface for single-use token storage
+type OneTimeTokenRepository interface {
+	// Create stores a newly issued token
+	Create(ctx context.Context, token *models.OneTimeToken) error
+
+	// Consume atomically marks an unused, unexpired token of the given purpose
+	// as used and returns it
+	Consume(ctx context.Context, id string, purpose string) (*models.OneTimeToken, error)
+
+	// InvalidateForUser marks every outstanding token of a user and purpose as used
+	InvalidateForUser(ctx context.Context, userID int64, purpose string) error
+
+	// GetActive retrieves an unused, unexpired token of the given purpose
+	GetActive(ctx context.Context, id string, purpose string) (*models.OneTimeToken, error)
+
+	// RecordFailedAttempt increments the failed attempt counter of a token and returns it
+	RecordFailedAttempt(ctx context.Context, id string) (int, error)
+
+	// CountCreatedSince counts the tokens of a user and purpose issued after since
+	CountCreatedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int, error)
+}
+
+// MFARepository defines the interface for two-factor authentication storage
+type MFARepository interface {
+	// SaveTOTP stores a new, unconfirmed TOTP enrollment, replacing a previous unconfirmed one
+	SaveTOTP(ctx context.Context, userID int64, secretEncrypted []byte) error
+
+	// IsTOTPEnabled checks if the user has a confirmed TOTP enrollment
+	IsTOTPEnabled(ctx context.Context, userID int64) (bool, error)
+
+	// GetTOTP retrieves the TOTP enrollment of a user
+	GetTOTP(ctx context.Context, userID int64) (*models.TOTPCredential, error)
+
+	// ConfirmTOTP marks the TOTP enrollment as confirmed and records the step used to confirm it
+	ConfirmTOTP(ctx context.Context, userID int64, step int64) error
+
+	// UseTOTPStep atomically records a used time step. It returns false if the
+	// step, or a later one, was already used.
+	UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+
+	// DeleteTOTP removes the TOTP enrollment and all recovery codes of a user
+	DeleteTOTP(ctx context.Context, userID int64) error
+
+	// ReplaceRecoveryCodes replaces all recovery codes of a user with the given hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+
+	// UseRecoveryCode atomically marks an unused recovery code as used. It
+	// returns false if no such code exists.
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
+
+// CredentialRepository defines the interface for WebAuthn passkey storage
+type CredentialRepository interface {
+	// GetUserHandle returns the WebAuthn user handle of a user, creating it on first use
+	GetUserHandle(ctx context.Context, userID int64) ([]byte, error)
+
+	// GetUserIDByHandle resolves a WebAuthn user handle to a user ID
+	GetUserIDByHandle(ctx context.Context, handle []byte) (int64, error)
+
+	// Create stores a newly registered credential
+	Create(ctx context.Context, credential *models.Credential) error
+
+	// ListByUser retrieves every credential of a user, oldest first
+	ListByUser(ctx context.Context, userID int64) ([]*models.Credential, error)
+
+	// UpdateAfterLogin records a successful assertion with the credential
+	UpdateAfterLogin(ctx context.Context, id int64, signCount uint32, flags uint8, cloneWarning bool) error
+
+	// DeleteForUser deletes a credential if it belongs to the user
+	DeleteForUser(ctx context.Context, id int64, userID int64) error
+
+	// SaveCeremony stores the challenge state of a started ceremony
+	SaveCeremony(ctx context.Context, ceremony *models.WebAuthnCeremony) error
+
+	// ConsumeCeremony atomically removes and returns an unexpired ceremony of the given kind
+	ConsumeCeremony(ctx context.Context, id string, kind string) (*models.WebAuthnCeremony, error)
+}
+
+// LoginAttemptTracker defines the interface for counting failed logins and
+// holding temporary lockouts. Keys identify an account or a client IP.
+type LoginAttemptTracker interface {
+	// RecordFailure counts a failed attempt and returns the number of failures
+	// since the first one in the current window
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+
+	// Lock locks the key out for the given duration
+	Lock(ctx context.Context, key string, duration time.Duration) error
+
+	// LockedFor returns how long the key stays locked, or zero if it is not locked
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+
+	// Reset clears the failure count and any lockout of the key
+	Reset(ctx context.Context, key string) error
+}
+
+// RateLimitStore defines the interface for rate limit token buckets
+type RateLimitStore interface {
+	// Take removes a token from the bucket identified by key
+	Take(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error)
+}
+
+// DataExportRepository defines the interface for user data export storage
+type DataExportRepository interface {
+	// Create stores a new pending export
+	Create(ctx context.Context, export *models.DataExport) error
+
+	// GetForUser retrieves an export if it belongs to the user
+	GetForUser(ctx context.Context, id string, userID int64) (*models.DataExport, error)
+
+	// GetActiveForUser retrieves the pending or ready export of a user
+	GetActiveForUser(ctx context.Context, userID int64) (*models.DataExport, error)
+
+	// MarkReady records the archive of a built export and when it expires
+	MarkReady(ctx context.Context, id string, filePath string, expiresAt time.Time) error
+
+	// MarkFailed records that building an export failed
+	MarkFailed(ctx context.Context, id string) error
+
+	// ConsumeReady atomically marks a ready, unexpired export of a user as
+	// downloaded and returns it
+	ConsumeReady(ctx context.Context, id string, userID int64) (*models.DataExport, error)
+
+	// ExpireBefore marks ready exports that expired before the given time as
+	// expired and returns them so their archives can be removed
+	ExpireBefore(ctx context.Context, before time.Time) ([]*models.DataExport, error)
+
+	// FailStale marks exports still pending since before the given time as
+	// failed, e.g. after a restart interrupted the build
+	FailStale(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+// RoleRepository defines the interface for role and permission storage
+type RoleRepository interface {
+	// List retrieves every role together with its permissions
+	List(ctx context.Context) ([]*models.Role, error)
+
+	// ListForUser retrieves the names of the roles granted to a user
+	ListForUser(ctx context.Context, userID int64) ([]string, error)
+
+	// Assign grants a role to a user. Granting a role twice is a no-op.
+	Assign(ctx context.Context, userID int64, role string, grantedBy *int64) error
+
+	// Revoke removes a role from a user
+	Revoke(ctx context.Context, userID int64, role string) error
+}
+
+// AdminActionRepository defines the interface for the admin action log
+type AdminActionRepository interface {
+	// Create records an admin action
+	Create(ctx context.Context, action *models.AdminAction) error
+
+	// List retrieves up to limit actions matching the filter with an ID
+	// below beforeID (0 for the first page), newest first
+	List(ctx context.Context, filter *models.AdminActionFilter, beforeID int64, limit int) ([]*models.AdminAction, error)
+}
+
+// NonceStore remembers request nonces to reject replayed requests
+type NonceStore interface {
+	// Use records a nonce for ttl. It returns false if the nonce has
+	// already been used within its ttl.
+	Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// APIKeyRepository defines the interface for API key storage
+type APIKeyRepository interface {
+	// Create stores a new API key
+	Create(ctx context.Context, key *models.APIKey) error
+
+	// GetByHash retrieves an unrevoked API key by the hash of the key
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+
+	// ListByUser retrieves the unrevoked API keys of a user, oldest first
+	ListByUser(ctx context.Context, userID int64) ([]*models.APIKey, error)
+
+	// Touch records a use of the key from ip, which may be empty if unknown.
+	// Uses within a minute of the last recorded one are not written.
+	Touch(ctx context.Context, id int64, ip string) error
+
+	// RevokeForUser revokes an API key if it belongs to the user
+	RevokeForUser(ctx context.Context, id int64, userID int64) error
+}
+
+// AuditEventRepository defines the interface for the audit log
+type AuditEventRepository interface {
+	// Create records an audit event
+	Create(ctx context.Context, event *models.AuditEvent) error
+
+	// List retrieves up to limit events matching the filter with an ID
+	// below beforeID (0 for the first page), newest first
+	List(ctx context.Context, filter *models.AuditEventFilter, beforeID int64, limit int) ([]*models.AuditEvent, error)
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// memoryCredentialRepository implements CredentialRepository in memory.
+// It is intended for tests and local development.
+type memoryCredentialRepository struct {
+	mu          sync.Mutex
+	nextID      int64
+	handles     map[int64][]byte
+	credentials map[int64]*models.Credential
+	ceremonies  map[string]*models.WebAuthnCeremony
+}
+
+// NewMemoryCredentialRepository creates a new in-memory credential repository
+func NewMemoryCredentialRepository() CredentialRepository {
+	return &memoryCredentialRepository{
+		handles:     make(map[int64][]byte),
+		credentials: make(map[int64]*models.Credential),
+		ceremonies:  make(map[string]*models.WebAuthnCeremony),
+	}
+}
+
+// GetUserHandle returns the WebAuthn user handle of a user, creating it on first use
+func (r *memoryCredentialRepository) GetUserHandle(ctx context.Context, userID int64) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if handle, ok := r.handles[userID]; ok {
+		return handle, nil
+	}
+
+	handle, err := newUserHandle()
+	if err != nil {
+		return nil, err
+	}
+	r.handles[userID] = handle
+
+	return handle, nil
+}
+
+// GetUserIDByHandle resolves a WebAuthn user handle to a user ID
+func (r *memoryCredentialRepository) GetUserIDByHandle(ctx context.Context, handle []byte) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for userID, stored := range r.handles {
+		if bytes.Equal(stored, handle) {
+			return userID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("user handle not found")
+}
+
+// Create stores a newly registered credential
+func (r *memoryCredentialRepository) Create(ctx context.Context, credential *models.Credential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.credentials {
+		if bytes.Equal(stored.CredentialID, credential.CredentialID) {
+			return fmt.Errorf("failed to create credential: duplicate credential id")
+		}
+	}
+
+	r.nextID++
+	credential.ID = r.nextID
+	credential.CreatedAt = time.Now()
+	stored := *credential
+	r.credentials[credential.ID] = &stored
+
+	return nil
+}
+
+// ListByUser retrieves every credential of a user, oldest first
+func (r *memoryCredentialRepository) ListByUser(ctx context.Context, userID int64) ([]*models.Credential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	credentials := []*models.Credential{}
+	for id := int64(1); id <= r.nextID; id++ {
+		if stored, ok := r.credentials[id]; ok && stored.UserID == userID {
+			credential := *stored
+			credentials = append(credentials, &credential)
+		}
+	}
+
+	return credentials, nil
+}
+
+// UpdateAfterLogin records a successful assertion with the credential
+func (r *memoryCredentialRepository) UpdateAfterLogin(ctx context.Context, id int64, signCount uint32, flags uint8, cloneWarning bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	credential, ok := r.credentials[id]
+	if !ok {
+		return fmt.Errorf("credential with id %d not found", id)
+	}
+
+	now := time.Now()
+	credential.SignCount = signCount
+	credential.Flags = flags
+	credential.CloneWarning = credential.CloneWarning || cloneWarning
+	credential.LastUsedAt = &now
+
+	return nil
+}
+
+// DeleteForUser deletes a credential if it belongs to the user
+func (r *memoryCredentialRepository) DeleteForUser(ctx context.Context, id int64, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	credential, ok := r.credentials[id]
+	if !ok || credential.UserID != userID {
+		return fmt.Errorf("credential with id %d not found", id)
+	}
+
+	delete(r.credentials, id)
+	return nil
+}
+
+// SaveCeremony stores the challenge state of a started ceremony
+func (r *memoryCredentialRepository) SaveCeremony(ctx context.Context, ceremony *models.WebAuthnCeremony) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, stored := range r.ceremonies {
+		if stored.ExpiresAt.Before(now) {
+			delete(r.ceremonies, id)
+		}
+	}
+
+	ceremony.CreatedAt = now
+	stored := *ceremony
+	r.ceremonies[ceremony.ID] = &stored
+
+	return nil
+}
+
+// ConsumeCeremony atomically removes and returns an unexpired ceremony of the given kind
+func (r *memoryCredentialRepository) ConsumeCeremony(ctx context.Context, id string, kind string) (*models.WebAuthnCeremony, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ceremony, ok := r.ceremonies[id]
+	if !ok || ceremony.Kind != kind || !ceremony.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("ceremony %s not found or expired", id)
+	}
+
+	delete(r.ceremonies, id)
+	return ceremony, nil
+}
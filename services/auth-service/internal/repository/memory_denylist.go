@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryTokenDenylist implements TokenDenylist in memory.
+// It is intended for tests and single-node setups without Redis.
+type memoryTokenDenylist struct {
+	mu       sync.Mutex
+	tokens   map[string]time.Time
+	sessions map[string]time.Time
+	users    map[int64]userRevocation
+}
+
+// userRevocation is a per-user cutoff with its own expiry
+type userRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+// NewMemoryTokenDenylist creates a new in-memory token denylist
+func NewMemoryTokenDenylist() TokenDenylist {
+	return &memoryTokenDenylist{
+		tokens:   make(map[string]time.Time),
+		sessions: make(map[string]time.Time),
+		users:    make(map[int64]userRevocation),
+	}
+}
+
+// RevokeToken denies a single token by its jti until it expires
+func (d *memoryTokenDenylist) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.purgeExpired(time.Now())
+	d.tokens[tokenID] = expiresAt
+
+	return nil
+}
+
+// IsTokenRevoked checks if a token jti has been denied
+func (d *memoryTokenDenylist) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.tokens[tokenID]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+// RevokeUserTokens denies every token of a user issued at or before the given time
+func (d *memoryTokenDenylist) RevokeUserTokens(ctx context.Context, userID int64, issuedBefore time.Time, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.purgeExpired(now)
+	d.users[userID] = userRevocation{
+		issuedBefore: issuedBefore.Truncate(time.Second),
+		expiresAt:    now.Add(ttl),
+	}
+
+	return nil
+}
+
+// UserTokensRevokedAt returns the cutoff set by RevokeUserTokens, or zero time if none
+func (d *memoryTokenDenylist) UserTokensRevokedAt(ctx context.Context, userID int64) (time.Time, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	revocation, ok := d.users[userID]
+	if !ok || !time.Now().Before(revocation.expiresAt) {
+		return time.Time{}, nil
+	}
+
+	return revocation.issuedBefore, nil
+}
+
+// RevokeSession denies every token of a session for ttl
+func (d *memoryTokenDenylist) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.purgeExpired(now)
+	d.sessions[sessionID] = now.Add(ttl)
+
+	return nil
+}
+
+// IsSessionRevoked checks if a session has been denied
+func (d *memoryTokenDenylist) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.sessions[sessionID]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+// purgeExpired drops entries that no longer deny anything. Callers must hold d.mu.
+func (d *memoryTokenDenylist) purgeExpired(now time.Time) {
+	for id, expiresAt := range d.tokens {
+		if !now.Before(expiresAt) {
+			delete(d.tokens, id)
+		}
+	}
+	for id, expiresAt := range d.sessions {
+		if !now.Before(expiresAt) {
+			delete(d.sessions, id)
+		}
+	}
+	for id, revocation := range d.users {
+		if !now.Before(revocation.expiresAt) {
+			delete(d.users, id)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryLoginAttemptTracker implements LoginAttemptTracker in memory.
+// It is intended for tests and single-node setups without Redis.
+type memoryLoginAttemptTracker struct {
+	mu       sync.Mutex
+	failures map[string]failureWindow
+	locks    map[string]time.Time
+}
+
+// failureWindow is a failure count that expires as a whole
+type failureWindow struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewMemoryLoginAttemptTracker creates a new in-memory login attempt tracker
+func NewMemoryLoginAttemptTracker() LoginAttemptTracker {
+	return &memoryLoginAttemptTracker{
+		failures: make(map[string]failureWindow),
+		locks:    make(map[string]time.Time),
+	}
+}
+
+// RecordFailure counts a failed attempt and returns the number of failures in the window
+func (t *memoryLoginAttemptTracker) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.purgeExpired(now)
+
+	current, ok := t.failures[key]
+	if !ok {
+		current = failureWindow{expiresAt: now.Add(window)}
+	}
+	current.count++
+	t.failures[key] = current
+
+	return current.count, nil
+}
+
+// Lock locks the key out for the given duration
+func (t *memoryLoginAttemptTracker) Lock(ctx context.Context, key string, duration time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.locks[key] = time.Now().Add(duration)
+	return nil
+}
+
+// LockedFor returns how long the key stays locked, or zero if it is not locked
+func (t *memoryLoginAttemptTracker) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until, ok := t.locks[key]
+	if !ok {
+		return 0, nil
+	}
+
+	remaining := time.Until(until)
+	if remaining <= 0 {
+		delete(t.locks, key)
+		return 0, nil
+	}
+
+	return remaining, nil
+}
+
+// Reset clears the failure count and any lockout of the key
+func (t *memoryLoginAttemptTracker) Reset(ctx context.Context, key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, key)
+	delete(t.locks, key)
+	return nil
+}
+
+// purgeExpired drops failure windows that have ended
+func (t *memoryLoginAttemptTracker) purgeExpired(now time.Time) {
+	for key, window := range t.failures {
+		if now.After(window.expiresAt) {
+			delete(t.failures, key)
+		}
+	}
+	for key, until := range t.locks {
+		if now.After(until) {
+			delete(t.locks, key)
+		}
+	}
+}
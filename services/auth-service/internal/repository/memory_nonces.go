@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryNonceStore implements NonceStore in memory.
+// It is intended for tests and single-node setups without Redis.
+type memoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// NewMemoryNonceStore creates a new in-memory nonce store
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Use records a nonce for ttl and reports whether it was unused
+func (s *memoryNonceStore) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, expiresAt := range s.nonces {
+		if !now.Before(expiresAt) {
+			delete(s.nonces, key)
+		}
+	}
+
+	if _, used := s.nonces[nonce]; used {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+
+	return true, nil
+}
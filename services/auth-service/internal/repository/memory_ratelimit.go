@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// rateLimitSweepInterval is how often full buckets are dropped from memory
+const rateLimitSweepInterval = time.Minute
+
+// memoryRateLimitStore implements RateLimitStore in memory.
+// It is intended for tests and single-node setups without Redis.
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket is the state of one bucket
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// NewMemoryRateLimitStore creates a new in-memory rate limit store
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take removes a token from the bucket identified by key
+func (s *memoryRateLimitStore) Take(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	capacity := float64(limit.Capacity())
+	interval := float64(limit.Interval())
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	// Refill for the time passed since the last request
+	bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updatedAt))/interval)
+	bucket.updatedAt = now
+
+	result := &models.RateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - bucket.tokens) * interval))
+	}
+
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration(math.Ceil((capacity - bucket.tokens) * interval))
+	bucket.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, since they are
+// indistinguishable from new ones. Callers must hold s.mu.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
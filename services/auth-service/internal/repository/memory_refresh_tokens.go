@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// memoryRefreshTokenRepository implements RefreshTokenRepository in memory.
+// It is intended for tests and local development.
+type memoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*models.RefreshToken
+}
+
+// NewMemoryRefreshTokenRepository creates a new in-memory refresh token repository
+func NewMemoryRefreshTokenRepository() RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{
+		tokens: make(map[string]*models.RefreshToken),
+	}
+}
+
+// Create stores a newly issued refresh token
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.ID]; exists {
+		return fmt.Errorf("failed to create refresh token: duplicate id %s", token.ID)
+	}
+
+	token.CreatedAt = time.Now()
+	stored := *token
+	r.tokens[token.ID] = &stored
+
+	return nil
+}
+
+// GetByID retrieves a refresh token by its jti
+func (r *memoryRefreshTokenRepository) GetByID(ctx context.Context, id string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil, fmt.Errorf("refresh token %s not found", id)
+	}
+
+	result := *token
+	return &result, nil
+}
+
+// MarkUsed atomically marks a token as rotated
+func (r *memoryRefreshTokenRepository) MarkUsed(ctx context.Context, id string, replacedBy string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	token.UsedAt = &now
+	token.ReplacedBy = &replacedBy
+
+	return true, nil
+}
+
+// RevokeBySession revokes every unused token of a session
+func (r *memoryRefreshTokenRepository) RevokeBySession(ctx context.Context, sessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.SessionID == sessionID && token.UsedAt == nil && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes tokens that expired before the given time
+func (r *memoryRefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -2,14 +2,24 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"rhythmify/services/auth-service/internal/models"
 )
 
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// telegramIDConstraint is the unique constraint on users.telegram_id
+const telegramIDConstraint = "users_telegram_id_key"
+
 // postgresUserRepository implements UserRepository interface
 type postgresUserRepository struct {
 	db *pgxpool.Pool
@@ -26,7 +36,7 @@ func NewPostgresUserRepository(db *pgxpool.Pool) UserRepository {
 func (r *postgresUserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (email, username, password_hash, telegram_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		VALUES (NULLIF($1, ''), $2, NULLIF($3, ''), $4, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
 	row := r.db.QueryRow(ctx, query, user.Email, user.Username, user.Password, user.TelegramID)
@@ -43,12 +53,12 @@ func (r *postgresUserRepository) Create(ctx context.Context, user *models.User)
 func (r *postgresUserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, username, password_hash, telegram_id, created_at, updated_at
+		SELECT id, COALESCE(email, ''), username, COALESCE(password_hash, ''), telegram_id, created_at, updated_at, email_verified_at, must_change_password, suspended_at, suspension_reason
 		FROM users 
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	row := r.db.QueryRow(ctx, query, id)
-	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt, &user.MustChangePassword, &user.SuspendedAt, &user.SuspensionReason)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -64,12 +74,12 @@ func (r *postgresUserRepository) GetByID(ctx context.Context, id int64) (*models
 func (r *postgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, username, password_hash, telegram_id, created_at, updated_at
+		SELECT id, COALESCE(email, ''), username, COALESCE(password_hash, ''), telegram_id, created_at, updated_at, email_verified_at, must_change_password, suspended_at, suspension_reason
 		FROM users 
-		WHERE email = $1`
+		WHERE email = $1 AND deleted_at IS NULL`
 
 	row := r.db.QueryRow(ctx, query, email)
-	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt, &user.MustChangePassword, &user.SuspendedAt, &user.SuspensionReason)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -85,12 +95,12 @@ func (r *postgresUserRepository) GetByEmail(ctx context.Context, email string) (
 func (r *postgresUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, username, password_hash, telegram_id, created_at, updated_at
+		SELECT id, COALESCE(email, ''), username, COALESCE(password_hash, ''), telegram_id, created_at, updated_at, email_verified_at, must_change_password, suspended_at, suspension_reason
 		FROM users 
-		WHERE username = $1`
+		WHERE username = $1 AND deleted_at IS NULL`
 
 	row := r.db.QueryRow(ctx, query, username)
-	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt, &user.MustChangePassword, &user.SuspendedAt, &user.SuspensionReason)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -106,12 +116,12 @@ func (r *postgresUserRepository) GetByUsername(ctx context.Context, username str
 func (r *postgresUserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, username, password_hash, telegram_id, created_at, updated_at
+		SELECT id, COALESCE(email, ''), username, COALESCE(password_hash, ''), telegram_id, created_at, updated_at, email_verified_at, must_change_password, suspended_at, suspension_reason
 		FROM users 
-		WHERE telegram_id = $1`
+		WHERE telegram_id = $1 AND deleted_at IS NULL`
 
 	row := r.db.QueryRow(ctx, query, telegramID)
-	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt, &user.MustChangePassword, &user.SuspendedAt, &user.SuspensionReason)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -125,14 +135,17 @@ func (r *postgresUserRepository) GetByTelegramID(ctx context.Context, telegramID
 
 // Update updates user information
 func (r *postgresUserRepository) Update(ctx context.Context, user *models.User) error {
+	// Changing the email address resets its verification. Accounts created
+	// through Telegram may not have an email address yet.
 	query := `
 		UPDATE users 
-		SET email = $2, username = $3, telegram_id = $4, updated_at = NOW()
-		WHERE id = $1
-		RETURNING updated_at`
+		SET email = NULLIF($2, ''), username = $3, telegram_id = $4, updated_at = NOW(),
+			email_verified_at = CASE WHEN email IS NOT DISTINCT FROM NULLIF($2, '') THEN email_verified_at ELSE NULL END
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at, email_verified_at`
 
 	row := r.db.QueryRow(ctx, query, user.ID, user.Email, user.Username, user.TelegramID)
-	err := row.Scan(&user.UpdatedAt)
+	err := row.Scan(&user.UpdatedAt, &user.EmailVerifiedAt)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -149,7 +162,7 @@ func (r *postgresUserRepository) LinkTelegram(ctx context.Context, userID int64,
 	query := `
 		UPDATE users 
 		SET telegram_id = $2, updated_at = NOW()
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.Exec(ctx, query, userID, telegramID)
 	if err != nil {
@@ -164,17 +177,165 @@ func (r *postgresUserRepository) LinkTelegram(ctx context.Context, userID int64,
 	return nil
 }
 
-// Delete soft deletes a user (placeholder for future implementation)
+// UnlinkTelegram removes the Telegram ID from a user
+func (r *postgresUserRepository) UnlinkTelegram(ctx context.Context, userID int64) error {
+	query := `
+		UPDATE users 
+		SET telegram_id = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink telegram: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with id %d not found", userID)
+	}
+
+	return nil
+}
+
+// UpdatePassword replaces the user's password hash
+func (r *postgresUserRepository) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	query := `
+		UPDATE users 
+		SET password_hash = $2, must_change_password = FALSE, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %d not found", userID)
+	}
+
+	return nil
+}
+
+// RehashPassword replaces a password hash with an upgraded hash of the same password
+func (r *postgresUserRepository) RehashPassword(ctx context.Context, userID int64, oldHash string, newHash string) error {
+	// Matching the old hash keeps a concurrent password change from being overwritten
+	query := `
+		UPDATE users
+		SET password_hash = $3
+		WHERE id = $1 AND password_hash = $2`
+
+	if _, err := r.db.Exec(ctx, query, userID, oldHash, newHash); err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
+	}
+
+	return nil
+}
+
+// SetMustChangePassword sets or clears the forced password change flag
+func (r *postgresUserRepository) SetMustChangePassword(ctx context.Context, userID int64, mustChange bool) error {
+	query := `
+		UPDATE users 
+		SET must_change_password = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID, mustChange)
+	if err != nil {
+		return fmt.Errorf("failed to set must change password: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %d not found", userID)
+	}
+
+	return nil
+}
+
+// MarkEmailVerified marks the user's email as verified if it still matches the given address
+func (r *postgresUserRepository) MarkEmailVerified(ctx context.Context, userID int64, email string) error {
+	query := `
+		UPDATE users 
+		SET email_verified_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID, email)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %d and email %s not found", userID, email)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a user and frees its Telegram link, keeping the
+// Telegram ID aside for Restore
 func (r *postgresUserRepository) Delete(ctx context.Context, id int64) error {
-	// For now, we'll just return not implemented
-	// In the future, you might want to add a deleted_at column for soft deletes
-	return fmt.Errorf("delete operation not implemented yet")
+	query := `
+		UPDATE users 
+		SET deleted_at = NOW(), deleted_telegram_id = telegram_id, telegram_id = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %d not found", id)
+	}
+
+	return nil
+}
+
+// Restore undoes the soft deletion of a user deleted after deletedAfter and
+// links its Telegram account again. It fails with ErrTelegramIDTaken if the
+// Telegram account has been linked to another user in the meantime.
+func (r *postgresUserRepository) Restore(ctx context.Context, id int64, deletedAfter time.Time) error {
+	query := `
+		UPDATE users 
+		SET deleted_at = NULL, telegram_id = deleted_telegram_id, deleted_telegram_id = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at > $2`
+
+	result, err := r.db.Exec(ctx, query, id, deletedAfter)
+	if err != nil {
+		// The email address, username or Telegram account may have been
+		// taken in the meantime
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			if pgErr.ConstraintName == telegramIDConstraint {
+				return fmt.Errorf("telegram account of user %d %w", id, ErrTelegramIDTaken)
+			}
+			return fmt.Errorf("email or username of user %d is taken", id)
+		}
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("deleted user with id %d not found", id)
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes users soft deleted before deletedBefore.
+// Sessions, tokens and credentials are removed with them by cascade.
+func (r *postgresUserRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at <= $1`
+
+	result, err := r.db.Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+
+	return result.RowsAffected(), nil
 }
 
 // CheckEmailExists checks if email already exists
 func (r *postgresUserRepository) CheckEmailExists(ctx context.Context, email string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL)`
 	
 	err := r.db.QueryRow(ctx, query, email).Scan(&exists)
 	if err != nil {
@@ -187,7 +348,7 @@ func (r *postgresUserRepository) CheckEmailExists(ctx context.Context, email str
 // CheckUsernameExists checks if username already exists
 func (r *postgresUserRepository) CheckUsernameExists(ctx context.Context, username string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 AND deleted_at IS NULL)`
 	
 	err := r.db.QueryRow(ctx, query, username).Scan(&exists)
 	if err != nil {
@@ -195,4 +356,89 @@ func (r *postgresUserRepository) CheckUsernameExists(ctx context.Context, userna
 	}
 
 	return exists, nil
+}
+
+// Search retrieves up to limit users matching the filter with an ID below
+// beforeID, newest first. Email and username match by case-insensitive prefix.
+func (r *postgresUserRepository) Search(ctx context.Context, filter *models.UserFilter, beforeID int64, limit int) ([]*models.User, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Email != "" {
+		addCondition(`LOWER(email) LIKE $%d || '%%'`, escapeLike(strings.ToLower(filter.Email)))
+	}
+	if filter.Username != "" {
+		addCondition(`LOWER(username) LIKE $%d || '%%'`, escapeLike(strings.ToLower(filter.Username)))
+	}
+	if filter.TelegramID != 0 {
+		addCondition("telegram_id = $%d", filter.TelegramID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		addCondition("created_at >= $%d", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		addCondition("created_at < $%d", filter.CreatedBefore)
+	}
+	if beforeID > 0 {
+		addCondition("id < $%d", beforeID)
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(email, ''), username, COALESCE(password_hash, ''), telegram_id, created_at, updated_at, email_verified_at, must_change_password, suspended_at, suspension_reason
+		FROM users
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.TelegramID, &user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt, &user.MustChangePassword, &user.SuspendedAt, &user.SuspensionReason); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetSuspended suspends a user with a reason, or lifts the suspension
+func (r *postgresUserRepository) SetSuspended(ctx context.Context, userID int64, suspended bool, reason string) error {
+	query := `
+		UPDATE users
+		SET suspended_at = CASE WHEN $2 THEN COALESCE(suspended_at, NOW()) ELSE NULL END,
+			suspension_reason = CASE WHEN $2 THEN $3 ELSE '' END,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID, suspended, reason)
+	if err != nil {
+		return fmt.Errorf("failed to update suspension: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %d not found", userID)
+	}
+
+	return nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
\ No newline at end of file
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// postgresAdminActionRepository implements AdminActionRepository interface
+type postgresAdminActionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresAdminActionRepository creates a new PostgreSQL admin action repository
+func NewPostgresAdminActionRepository(db *pgxpool.Pool) AdminActionRepository {
+	return &postgresAdminActionRepository{
+		db: db,
+	}
+}
+
+// Create records an admin action
+func (r *postgresAdminActionRepository) Create(ctx context.Context, action *models.AdminAction) error {
+	details := action.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode admin action details: %w", err)
+	}
+
+	query := `
+		INSERT INTO admin_actions (admin_id, target_user_id, action, details, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at`
+
+	err = r.db.QueryRow(ctx, query, action.AdminID, action.TargetUserID, action.Action, encoded).Scan(&action.ID, &action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create admin action: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves up to limit actions matching the filter with an ID below beforeID, newest first
+func (r *postgresAdminActionRepository) List(ctx context.Context, filter *models.AdminActionFilter, beforeID int64, limit int) ([]*models.AdminAction, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.AdminID != 0 {
+		addCondition("admin_id = $%d", filter.AdminID)
+	}
+	if filter.TargetUserID != 0 {
+		addCondition("target_user_id = $%d", filter.TargetUserID)
+	}
+	if beforeID > 0 {
+		addCondition("id < $%d", beforeID)
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, admin_id, target_user_id, action, details, created_at
+		FROM admin_actions
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []*models.AdminAction{}
+	for rows.Next() {
+		action := &models.AdminAction{}
+		var details []byte
+		if err := rows.Scan(&action.ID, &action.AdminID, &action.TargetUserID, &action.Action, &details, &action.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan admin action: %w", err)
+		}
+		if err := json.Unmarshal(details, &action.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode admin action details: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+
+	return actions, nil
+}
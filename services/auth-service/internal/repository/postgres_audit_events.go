@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// postgresAuditEventRepository implements AuditEventRepository interface
+type postgresAuditEventRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresAuditEventRepository creates a new PostgreSQL audit event repository
+func NewPostgresAuditEventRepository(db *pgxpool.Pool) AuditEventRepository {
+	return &postgresAuditEventRepository{
+		db: db,
+	}
+}
+
+// Create records an audit event
+func (r *postgresAuditEventRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	metadata := event.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event metadata: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_events (actor_id, target_user_id, event_type, ip_address, user_agent, request_id, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id, created_at`
+
+	err = r.db.QueryRow(ctx, query,
+		event.ActorID,
+		event.TargetUserID,
+		event.EventType,
+		event.IPAddress,
+		event.UserAgent,
+		event.RequestID,
+		encoded,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves up to limit events matching the filter with an ID below beforeID, newest first
+func (r *postgresAuditEventRepository) List(ctx context.Context, filter *models.AuditEventFilter, beforeID int64, limit int) ([]*models.AuditEvent, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorID != 0 {
+		addCondition("actor_id = $%d", filter.ActorID)
+	}
+	if filter.TargetUserID != 0 {
+		addCondition("target_user_id = $%d", filter.TargetUserID)
+	}
+	if filter.EventType != "" {
+		addCondition("event_type = $%d", filter.EventType)
+	}
+	if filter.IPAddress != "" {
+		addCondition("ip_address = $%d", filter.IPAddress)
+	}
+	if filter.RequestID != "" {
+		addCondition("request_id = $%d", filter.RequestID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		addCondition("created_at >= $%d", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		addCondition("created_at < $%d", filter.CreatedBefore)
+	}
+	if beforeID > 0 {
+		addCondition("id < $%d", beforeID)
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, actor_id, target_user_id, event_type, ip_address, user_agent, request_id, metadata, created_at
+		FROM audit_events
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.AuditEvent{}
+	for rows.Next() {
+		event := &models.AuditEvent{}
+		var metadata []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.ActorID,
+			&event.TargetUserID,
+			&event.EventType,
+			&event.IPAddress,
+			&event.UserAgent,
+			&event.RequestID,
+			&metadata,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if err := json.Unmarshal(metadata, &event.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode audit event metadata: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, nil
+}
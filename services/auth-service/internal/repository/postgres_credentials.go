@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// userHandleSize is the length of a WebAuthn user handle in bytes
+const userHandleSize = 32
+
+// postgresCredentialRepository implements CredentialRepository interface
+type postgresCredentialRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresCredentialRepository creates a new PostgreSQL credential repository
+func NewPostgresCredentialRepository(db *pgxpool.Pool) CredentialRepository {
+	return &postgresCredentialRepository{
+		db: db,
+	}
+}
+
+// GetUserHandle returns the WebAuthn user handle of a user, creating it on first use
+func (r *postgresCredentialRepository) GetUserHandle(ctx context.Context, userID int64) ([]byte, error) {
+	handle, err := newUserHandle()
+	if err != nil {
+		return nil, err
+	}
+
+	insert := `
+		INSERT INTO webauthn_user_handles (user_id, handle, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO NOTHING`
+
+	if _, err := r.db.Exec(ctx, insert, userID, handle); err != nil {
+		return nil, fmt.Errorf("failed to create user handle: %w", err)
+	}
+
+	query := `SELECT handle FROM webauthn_user_handles WHERE user_id = $1`
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&handle); err != nil {
+		return nil, fmt.Errorf("failed to get user handle: %w", err)
+	}
+
+	return handle, nil
+}
+
+// GetUserIDByHandle resolves a WebAuthn user handle to a user ID
+func (r *postgresCredentialRepository) GetUserIDByHandle(ctx context.Context, handle []byte) (int64, error) {
+	var userID int64
+	query := `SELECT user_id FROM webauthn_user_handles WHERE handle = $1`
+
+	err := r.db.QueryRow(ctx, query, handle).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("user handle not found")
+		}
+		return 0, fmt.Errorf("failed to get user handle: %w", err)
+	}
+
+	return userID, nil
+}
+
+// Create stores a newly registered credential
+func (r *postgresCredentialRepository) Create(ctx context.Context, credential *models.Credential) error {
+	query := `
+		INSERT INTO credentials (user_id, credential_id, public_key, attestation_type, transports, flags, aaguid, sign_count, name, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, created_at`
+
+	row := r.db.QueryRow(ctx, query,
+		credential.UserID,
+		credential.CredentialID,
+		credential.PublicKey,
+		credential.AttestationType,
+		credential.Transports,
+		int16(credential.Flags),
+		credential.AAGUID,
+		int64(credential.SignCount),
+		credential.Name,
+	)
+
+	if err := row.Scan(&credential.ID, &credential.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create credential: %w", err)
+	}
+
+	return nil
+}
+
+// ListByUser retrieves every credential of a user, oldest first
+func (r *postgresCredentialRepository) ListByUser(ctx context.Context, userID int64) ([]*models.Credential, error) {
+	query := `
+		SELECT id, user_id, credential_id, public_key, attestation_type, transports, flags, aaguid, sign_count, clone_warning, name, created_at, last_used_at
+		FROM credentials
+		WHERE user_id = $1
+		ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	defer rows.Close()
+
+	credentials := []*models.Credential{}
+	for rows.Next() {
+		var flags int16
+		var signCount int64
+		credential := &models.Credential{}
+
+		err := rows.Scan(
+			&credential.ID,
+			&credential.UserID,
+			&credential.CredentialID,
+			&credential.PublicKey,
+			&credential.AttestationType,
+			&credential.Transports,
+			&flags,
+			&credential.AAGUID,
+			&signCount,
+			&credential.CloneWarning,
+			&credential.Name,
+			&credential.CreatedAt,
+			&credential.LastUsedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credential: %w", err)
+		}
+
+		credential.Flags = uint8(flags)
+		credential.SignCount = uint32(signCount)
+		credentials = append(credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// UpdateAfterLogin records a successful assertion with the credential
+func (r *postgresCredentialRepository) UpdateAfterLogin(ctx context.Context, id int64, signCount uint32, flags uint8, cloneWarning bool) error {
+	query := `
+		UPDATE credentials
+		SET sign_count = $2, flags = $3, clone_warning = clone_warning OR $4, last_used_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, id, int64(signCount), int16(flags), cloneWarning)
+	if err != nil {
+		return fmt.Errorf("failed to update credential: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("credential with id %d not found", id)
+	}
+
+	return nil
+}
+
+// DeleteForUser deletes a credential if it belongs to the user
+func (r *postgresCredentialRepository) DeleteForUser(ctx context.Context, id int64, userID int64) error {
+	query := `DELETE FROM credentials WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("credential with id %d not found", id)
+	}
+
+	return nil
+}
+
+// SaveCeremony stores the challenge state of a started ceremony
+func (r *postgresCredentialRepository) SaveCeremony(ctx context.Context, ceremony *models.WebAuthnCeremony) error {
+	// Drop abandoned ceremonies while we are here
+	if _, err := r.db.Exec(ctx, `DELETE FROM webauthn_ceremonies WHERE expires_at < NOW()`); err != nil {
+		return fmt.Errorf("failed to clean up ceremonies: %w", err)
+	}
+
+	query := `
+		INSERT INTO webauthn_ceremonies (id, user_id, kind, session_data, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at`
+
+	row := r.db.QueryRow(ctx, query, ceremony.ID, ceremony.UserID, ceremony.Kind, ceremony.SessionData, ceremony.ExpiresAt)
+	if err := row.Scan(&ceremony.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save ceremony: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeCeremony atomically removes and returns an unexpired ceremony of the given kind
+func (r *postgresCredentialRepository) ConsumeCeremony(ctx context.Context, id string, kind string) (*models.WebAuthnCeremony, error) {
+	ceremony := &models.WebAuthnCeremony{}
+	query := `
+		DELETE FROM webauthn_ceremonies
+		WHERE id = $1 AND kind = $2 AND expires_at > NOW()
+		RETURNING id, user_id, kind, session_data, expires_at, created_at`
+
+	row := r.db.QueryRow(ctx, query, id, kind)
+	err := row.Scan(&ceremony.ID, &ceremony.UserID, &ceremony.Kind, &ceremony.SessionData, &ceremony.ExpiresAt, &ceremony.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("ceremony %s not found or expired", id)
+		}
+		return nil, fmt.Errorf("failed to consume ceremony: %w", err)
+	}
+
+	return ceremony, nil
+}
+
+// newUserHandle generates a random WebAuthn user handle
+func newUserHandle() ([]byte, error) {
+	handle := make([]byte, userHandleSize)
+	if _, err := rand.Read(handle); err != nil {
+		return nil, fmt.Errorf("failed to generate user handle: %w", err)
+	}
+	return handle, nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// dataExportColumns are the columns scanned by scanDataExport
+const dataExportColumns = `id, user_id, status, file_path, created_at, completed_at, expires_at, downloaded_at`
+
+// postgresDataExportRepository implements DataExportRepository interface
+type postgresDataExportRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresDataExportRepository creates a new PostgreSQL data export repository
+func NewPostgresDataExportRepository(db *pgxpool.Pool) DataExportRepository {
+	return &postgresDataExportRepository{
+		db: db,
+	}
+}
+
+// Create stores a new pending export
+func (r *postgresDataExportRepository) Create(ctx context.Context, export *models.DataExport) error {
+	query := `
+		INSERT INTO data_exports (id, user_id, status, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING created_at`
+
+	err := r.db.QueryRow(ctx, query, export.ID, export.UserID, export.Status).Scan(&export.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create data export: %w", err)
+	}
+
+	return nil
+}
+
+// GetForUser retrieves an export if it belongs to the user
+func (r *postgresDataExportRepository) GetForUser(ctx context.Context, id string, userID int64) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = $1 AND user_id = $2`
+
+	export, err := scanDataExport(r.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("data export %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// GetActiveForUser retrieves the pending or ready export of a user
+func (r *postgresDataExportRepository) GetActiveForUser(ctx context.Context, userID int64) (*models.DataExport, error) {
+	query := `
+		SELECT ` + dataExportColumns + `
+		FROM data_exports
+		WHERE user_id = $1 AND (status = $2 OR (status = $3 AND expires_at > NOW()))
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	export, err := scanDataExport(r.db.QueryRow(ctx, query, userID, models.ExportPending, models.ExportReady))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("no active data export for user %d", userID)
+		}
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// MarkReady records the archive of a built export and when it expires
+func (r *postgresDataExportRepository) MarkReady(ctx context.Context, id string, filePath string, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = $2, file_path = $3, completed_at = NOW(), expires_at = $4
+		WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, query, id, models.ExportReady, filePath, expiresAt); err != nil {
+		return fmt.Errorf("failed to mark data export ready: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records that building an export failed
+func (r *postgresDataExportRepository) MarkFailed(ctx context.Context, id string) error {
+	query := `
+		UPDATE data_exports
+		SET status = $2, completed_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, query, id, models.ExportFailed); err != nil {
+		return fmt.Errorf("failed to mark data export failed: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeReady atomically marks a ready, unexpired export of a user as downloaded
+func (r *postgresDataExportRepository) ConsumeReady(ctx context.Context, id string, userID int64) (*models.DataExport, error) {
+	query := `
+		UPDATE data_exports
+		SET status = $4, downloaded_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND status = $3 AND expires_at > NOW()
+		RETURNING ` + dataExportColumns
+
+	export, err := scanDataExport(r.db.QueryRow(ctx, query, id, userID, models.ExportReady, models.ExportDownloaded))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("no ready data export %s for user %d", id, userID)
+		}
+		return nil, fmt.Errorf("failed to consume data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// ExpireBefore marks ready exports that expired before the given time as expired
+func (r *postgresDataExportRepository) ExpireBefore(ctx context.Context, before time.Time) ([]*models.DataExport, error) {
+	query := `
+		UPDATE data_exports
+		SET status = $3
+		WHERE status = $2 AND expires_at <= $1
+		RETURNING ` + dataExportColumns
+
+	rows, err := r.db.Query(ctx, query, before, models.ExportReady, models.ExportExpired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire data exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []*models.DataExport{}
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to expire data exports: %w", err)
+	}
+
+	return exports, nil
+}
+
+// FailStale marks exports still pending since before the given time as failed
+func (r *postgresDataExportRepository) FailStale(ctx context.Context, createdBefore time.Time) (int64, error) {
+	query := `
+		UPDATE data_exports
+		SET status = $3, completed_at = NOW()
+		WHERE status = $2 AND created_at < $1`
+
+	result, err := r.db.Exec(ctx, query, createdBefore, models.ExportPending, models.ExportFailed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale data exports: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// scanDataExport scans a row selected with dataExportColumns
+func scanDataExport(row pgx.Row) (*models.DataExport, error) {
+	export := &models.DataExport{}
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.FilePath,
+		&export.CreatedAt,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+		&export.DownloadedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// postgresMFARepository implements MFARepository interface
+type postgresMFARepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresMFARepository creates a new PostgreSQL MFA repository
+func NewPostgresMFARepository(db *pgxpool.Pool) MFARepository {
+	return &postgresMFARepository{
+		db: db,
+	}
+}
+
+// SaveTOTP stores a new, unconfirmed TOTP enrollment, replacing a previous unconfirmed one
+func (r *postgresMFARepository) SaveTOTP(ctx context.Context, userID int64, secretEncrypted []byte) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret_encrypted, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_encrypted = EXCLUDED.secret_encrypted, last_used_step = 0, created_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID, secretEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to save totp enrollment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("totp already enabled for user %d", userID)
+	}
+
+	return nil
+}
+
+// IsTOTPEnabled checks if the user has a confirmed TOTP enrollment
+func (r *postgresMFARepository) IsTOTPEnabled(ctx context.Context, userID int64) (bool, error) {
+	var enabled bool
+	query := `SELECT EXISTS(SELECT 1 FROM user_totp WHERE user_id = $1 AND confirmed_at IS NOT NULL)`
+
+	err := r.db.QueryRow(ctx, query, userID).Scan(&enabled)
+	if err != nil {
+		return false, fmt.Errorf("failed to check totp enrollment: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// GetTOTP retrieves the TOTP enrollment of a user
+func (r *postgresMFARepository) GetTOTP(ctx context.Context, userID int64) (*models.TOTPCredential, error) {
+	credential := &models.TOTPCredential{}
+	query := `
+		SELECT user_id, secret_encrypted, confirmed_at, last_used_step, created_at
+		FROM user_totp
+		WHERE user_id = $1`
+
+	row := r.db.QueryRow(ctx, query, userID)
+	err := row.Scan(&credential.UserID, &credential.SecretEncrypted, &credential.ConfirmedAt, &credential.LastUsedStep, &credential.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("totp enrollment for user %d not found", userID)
+		}
+		return nil, fmt.Errorf("failed to get totp enrollment: %w", err)
+	}
+
+	return credential, nil
+}
+
+// ConfirmTOTP marks the TOTP enrollment as confirmed
+func (r *postgresMFARepository) ConfirmTOTP(ctx context.Context, userID int64, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to confirm totp enrollment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("totp enrollment for user %d not found", userID)
+	}
+
+	return nil
+}
+
+// UseTOTPStep atomically records a used time step
+func (r *postgresMFARepository) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2`
+
+	result, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// DeleteTOTP removes the TOTP enrollment and all recovery codes of a user
+func (r *postgresMFARepository) DeleteTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete totp enrollment: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of a user with the given hashes
+func (r *postgresMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		query := `INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, NOW())`
+		if _, err := tx.Exec(ctx, query, userID, hash); err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode atomically marks an unused recovery code as used
+func (r *postgresMFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM user_recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)`
+
+	result, err := r.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// postgresOneTimeTokenRepository implements OneTimeTokenRepository interface
+type postgresOneTimeTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresOneTimeTokenRepository creates a new PostgreSQL one-time token repository
+func NewPostgresOneTimeTokenRepository(db *pgxpool.Pool) OneTimeTokenRepository {
+	return &postgresOneTimeTokenRepository{
+		db: db,
+	}
+}
+
+// Create stores a newly issued token
+func (r *postgresOneTimeTokenRepository) Create(ctx context.Context, token *models.OneTimeToken) error {
+	query := `
+		INSERT INTO one_time_tokens (id, user_id, purpose, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING created_at`
+
+	row := r.db.QueryRow(ctx, query, token.ID, token.UserID, token.Purpose, token.ExpiresAt)
+	if err := row.Scan(&token.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create one-time token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume atomically marks an unused, unexpired token as used and returns it
+func (r *postgresOneTimeTokenRepository) Consume(ctx context.Context, id string, purpose string) (*models.OneTimeToken, error) {
+	token := &models.OneTimeToken{}
+	query := `
+		UPDATE one_time_tokens
+		SET used_at = NOW()
+		WHERE id = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, user_id, purpose, expires_at, used_at, created_at`
+
+	row := r.db.QueryRow(ctx, query, id, purpose)
+	err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("one-time token %s not found or already used", id)
+		}
+		return nil, fmt.Errorf("failed to consume one-time token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetActive retrieves an unused, unexpired token of the given purpose
+func (r *postgresOneTimeTokenRepository) GetActive(ctx context.Context, id string, purpose string) (*models.OneTimeToken, error) {
+	token := &models.OneTimeToken{}
+	query := `
+		SELECT id, user_id, purpose, expires_at, used_at, created_at
+		FROM one_time_tokens
+		WHERE id = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()`
+
+	row := r.db.QueryRow(ctx, query, id, purpose)
+	err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("one-time token %s not found or already used", id)
+		}
+		return nil, fmt.Errorf("failed to get one-time token: %w", err)
+	}
+
+	return token, nil
+}
+
+// InvalidateForUser marks every outstanding token of a user and purpose as used
+func (r *postgresOneTimeTokenRepository) InvalidateForUser(ctx context.Context, userID int64, purpose string) error {
+	query := `
+		UPDATE one_time_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, userID, purpose); err != nil {
+		return fmt.Errorf("failed to invalidate one-time tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailedAttempt increments the failed attempt counter of a token and returns it
+func (r *postgresOneTimeTokenRepository) RecordFailedAttempt(ctx context.Context, id string) (int, error) {
+	var attempts int
+	query := `
+		UPDATE one_time_tokens
+		SET attempts = attempts + 1
+		WHERE id = $1
+		RETURNING attempts`
+
+	err := r.db.QueryRow(ctx, query, id).Scan(&attempts)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("one-time token %s not found", id)
+		}
+		return 0, fmt.Errorf("failed to record failed attempt: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// CountCreatedSince counts the tokens of a user and purpose issued after since
+func (r *postgresOneTimeTokenRepository) CountCreatedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM one_time_tokens
+		WHERE user_id = $1 AND purpose = $2 AND created_at > $3`
+
+	if err := r.db.QueryRow(ctx, query, userID, purpose, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count one-time tokens: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// postgresRefreshTokenRepository implements RefreshTokenRepository interface
+type postgresRefreshTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRefreshTokenRepository creates a new PostgreSQL refresh token repository
+func NewPostgresRefreshTokenRepository(db *pgxpool.Pool) RefreshTokenRepository {
+	return &postgresRefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Create stores a newly issued refresh token
+func (r *postgresRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, session_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING created_at`
+
+	row := r.db.QueryRow(ctx, query, token.ID, token.UserID, token.SessionID, token.ExpiresAt)
+	if err := row.Scan(&token.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a refresh token by its jti
+func (r *postgresRefreshTokenRepository) GetByID(ctx context.Context, id string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{}
+	query := `
+		SELECT id, user_id, session_id, expires_at, used_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens
+		WHERE id = $1`
+
+	row := r.db.QueryRow(ctx, query, id)
+	err := row.Scan(&token.ID, &token.UserID, &token.SessionID, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.ReplacedBy, &token.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("refresh token %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// MarkUsed atomically marks a token as rotated
+func (r *postgresRefreshTokenRepository) MarkUsed(ctx context.Context, id string, replacedBy string) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET used_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, id, replacedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// RevokeBySession revokes every unused token of a session
+func (r *postgresRefreshTokenRepository) RevokeBySession(ctx context.Context, sessionID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE session_id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke session tokens: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes tokens that expired before the given time
+func (r *postgresRefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM refresh_tokens WHERE expires_at < $1`
+
+	result, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// postgresRoleRepository implements RoleRepository interface
+type postgresRoleRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRoleRepository creates a new PostgreSQL role repository
+func NewPostgresRoleRepository(db *pgxpool.Pool) RoleRepository {
+	return &postgresRoleRepository{
+		db: db,
+	}
+}
+
+// List retrieves every role together with its permissions
+func (r *postgresRoleRepository) List(ctx context.Context) ([]*models.Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.created_at,
+			COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id
+		ORDER BY r.name`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []*models.Role{}
+	for rows.Next() {
+		role := &models.Role{}
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.Permissions); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// ListForUser retrieves the names of the roles granted to a user
+func (r *postgresRoleRepository) ListForUser(ctx context.Context, userID int64) ([]string, error) {
+	query := `
+		SELECT r.name
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY r.name`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("failed to scan user role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// Assign grants a role to a user. Granting a role twice is a no-op.
+func (r *postgresRoleRepository) Assign(ctx context.Context, userID int64, role string, grantedBy *int64) error {
+	// The no-op update makes RETURNING yield a row for existing grants too,
+	// so no row means the role does not exist
+	query := `
+		INSERT INTO user_roles (user_id, role_id, granted_by, granted_at)
+		SELECT $1, id, $3, NOW() FROM roles WHERE name = $2
+		ON CONFLICT (user_id, role_id) DO UPDATE SET granted_by = user_roles.granted_by
+		RETURNING role_id`
+
+	var roleID int64
+	err := r.db.QueryRow(ctx, query, userID, role, grantedBy).Scan(&roleID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("role %s not found", role)
+		}
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke removes a role from a user
+func (r *postgresRoleRepository) Revoke(ctx context.Context, userID int64, role string) error {
+	query := `
+		DELETE FROM user_roles
+		WHERE user_id = $1 AND role_id = (SELECT id FROM roles WHERE name = $2)`
+
+	result, err := r.db.Exec(ctx, query, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("role %s not granted to user %d", role, userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// postgresSessionRepository implements SessionRepository interface
+type postgresSessionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresSessionRepository creates a new PostgreSQL session repository
+func NewPostgresSessionRepository(db *pgxpool.Pool) SessionRepository {
+	return &postgresSessionRepository{
+		db: db,
+	}
+}
+
+// Create stores a new session
+func (r *postgresSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, device_name, user_agent, ip_address, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW(), $6)
+		RETURNING created_at, last_used_at, expires_at`
+
+	row := r.db.QueryRow(ctx, query, session.ID, session.UserID, session.DeviceName, session.UserAgent, session.IPAddress, session.ExpiresAt)
+	if err := row.Scan(&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// ListByUser returns the unexpired sessions of a user, most recently used first
+func (r *postgresSessionRepository) ListByUser(ctx context.Context, userID int64) ([]*models.Session, error) {
+	query := `
+		SELECT id, user_id, device_name, user_agent, ip_address, created_at, last_used_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND expires_at > NOW()
+		ORDER BY last_used_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		session := &models.Session{}
+		if err := rows.Scan(&session.ID, &session.UserID, &session.DeviceName, &session.UserAgent, &session.IPAddress, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Touch updates last_used_at of a session and extends it until expiresAt
+func (r *postgresSessionRepository) Touch(ctx context.Context, id string, expiresAt time.Time) error {
+	query := `UPDATE sessions SET last_used_at = NOW(), expires_at = $2 WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, id, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("session %s %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// DeleteForUser deletes a session of the given user together with its refresh tokens
+func (r *postgresSessionRepository) DeleteForUser(ctx context.Context, id string, userID int64) error {
+	query := `DELETE FROM sessions WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("session %s %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// DeleteAllForUser deletes every session of a user except exceptID
+func (r *postgresSessionRepository) DeleteAllForUser(ctx context.Context, userID int64, exceptID string) ([]string, error) {
+	query := `
+		DELETE FROM sessions
+		WHERE user_id = $1 AND id <> $2
+		RETURNING id`
+
+	rows, err := r.db.Query(ctx, query, userID, exceptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	return ids, nil
+}
+
+// DeleteExpired deletes sessions that expired before the given time together with their refresh tokens
+func (r *postgresSessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at < $1`
+
+	result, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
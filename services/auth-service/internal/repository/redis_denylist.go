@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revokedTokenKeyPrefix   = "auth:revoked:token:"
+	revokedUserKeyPrefix    = "auth:revoked:user:"
+	revokedSessionKeyPrefix = "auth:revoked:session:"
+)
+
+// redisTokenDenylist implements TokenDenylist interface on top of Redis
+type redisTokenDenylist struct {
+	client *redis.Client
+}
+
+// NewRedisTokenDenylist creates a new Redis-backed token denylist
+func NewRedisTokenDenylist(client *redis.Client) TokenDenylist {
+	return &redisTokenDenylist{
+		client: client,
+	}
+}
+
+// RevokeToken denies a single token by its jti until it expires
+func (d *redisTokenDenylist) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// Token is already expired, nothing to deny
+		return nil
+	}
+
+	if err := d.client.Set(ctx, revokedTokenKeyPrefix+tokenID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked checks if a token jti has been denied
+func (d *redisTokenDenylist) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	count, err := d.client.Exists(ctx, revokedTokenKeyPrefix+tokenID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// RevokeUserTokens denies every token of a user issued at or before the given time
+func (d *redisTokenDenylist) RevokeUserTokens(ctx context.Context, userID int64, issuedBefore time.Time, ttl time.Duration) error {
+	key := revokedUserKeyPrefix + strconv.FormatInt(userID, 10)
+	if err := d.client.Set(ctx, key, issuedBefore.Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	return nil
+}
+
+// UserTokensRevokedAt returns the cutoff set by RevokeUserTokens, or zero time if none
+func (d *redisTokenDenylist) UserTokensRevokedAt(ctx context.Context, userID int64) (time.Time, error) {
+	key := revokedUserKeyPrefix + strconv.FormatInt(userID, 10)
+	cutoff, err := d.client.Get(ctx, key).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to get user token revocation: %w", err)
+	}
+
+	return time.Unix(cutoff, 0), nil
+}
+
+// RevokeSession denies every token of a session for ttl
+func (d *redisTokenDenylist) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	if err := d.client.Set(ctx, revokedSessionKeyPrefix+sessionID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// IsSessionRevoked checks if a session has been denied
+func (d *redisTokenDenylist) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	count, err := d.client.Exists(ctx, revokedSessionKeyPrefix+sessionID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check session revocation: %w", err)
+	}
+
+	return count > 0, nil
+}
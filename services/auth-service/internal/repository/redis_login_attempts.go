@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresKeyPrefix = "auth:login:failures:"
+	loginLockKeyPrefix     = "auth:login:lock:"
+)
+
+// redisLoginAttemptTracker implements LoginAttemptTracker interface on top of Redis
+type redisLoginAttemptTracker struct {
+	client *redis.Client
+}
+
+// NewRedisLoginAttemptTracker creates a new Redis-backed login attempt tracker
+func NewRedisLoginAttemptTracker(client *redis.Client) LoginAttemptTracker {
+	return &redisLoginAttemptTracker{
+		client: client,
+	}
+}
+
+// RecordFailure counts a failed attempt and returns the number of failures in the window
+func (t *redisLoginAttemptTracker) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	pipe := t.client.TxPipeline()
+	incr := pipe.Incr(ctx, loginFailuresKeyPrefix+key)
+	// The window starts with the first failure and is not extended by later ones
+	pipe.ExpireNX(ctx, loginFailuresKeyPrefix+key, window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return int(incr.Val()), nil
+}
+
+// Lock locks the key out for the given duration
+func (t *redisLoginAttemptTracker) Lock(ctx context.Context, key string, duration time.Duration) error {
+	if err := t.client.Set(ctx, loginLockKeyPrefix+key, 1, duration).Err(); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+
+	return nil
+}
+
+// LockedFor returns how long the key stays locked, or zero if it is not locked
+func (t *redisLoginAttemptTracker) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := t.client.PTTL(ctx, loginLockKeyPrefix+key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check login lock: %w", err)
+	}
+
+	// PTTL returns a negative value for missing keys
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// Reset clears the failure count and any lockout of the key
+func (t *redisLoginAttemptTracker) Reset(ctx context.Context, key string) error {
+	if err := t.client.Del(ctx, loginFailuresKeyPrefix+key, loginLockKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+
+	return nil
+}
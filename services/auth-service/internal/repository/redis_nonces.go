@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const nonceKeyPrefix = "auth:nonce:"
+
+// redisNonceStore implements NonceStore interface on top of Redis
+type redisNonceStore struct {
+	client *redis.Client
+}
+
+// NewRedisNonceStore creates a new Redis-backed nonce store
+func NewRedisNonceStore(client *redis.Client) NonceStore {
+	return &redisNonceStore{
+		client: client,
+	}
+}
+
+// Use records a nonce for ttl and reports whether it was unused
+func (s *redisNonceStore) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	fresh, err := s.client.SetNX(ctx, nonceKeyPrefix+nonce, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record nonce: %w", err)
+	}
+
+	return fresh, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+const rateLimitKeyPrefix = "auth:ratelimit:"
+
+// takeTokenScript refills and takes from a token bucket atomically. It uses
+// the Redis clock so replicas with skewed clocks share the same buckets.
+//
+// KEYS[1] bucket key, ARGV[1] capacity, ARGV[2] milliseconds per token.
+// Returns {allowed, remaining, reset ms, retry after ms}.
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / interval)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * interval)
+end
+
+local reset = math.ceil((capacity - tokens) * interval)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], reset + 1000)
+
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+// redisRateLimitStore implements RateLimitStore interface on top of Redis so
+// limits are shared between replicas
+type redisRateLimitStore struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimitStore creates a new Redis-backed rate limit store
+func NewRedisRateLimitStore(client *redis.Client) RateLimitStore {
+	return &redisRateLimitStore{
+		client: client,
+	}
+}
+
+// Take removes a token from the bucket identified by key
+func (s *redisRateLimitStore) Take(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error) {
+	interval := float64(limit.Interval()) / float64(time.Millisecond)
+
+	values, err := takeTokenScript.Run(ctx, s.client, []string{rateLimitKeyPrefix + key},
+		limit.Capacity(), strconv.FormatFloat(interval, 'f', -1, 64)).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	return &models.RateLimitResult{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"rhythmify/services/auth-service/internal/mailer"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+// reauthenticationWindow is how recent the login of an account without a
+// password must be to delete it
+const reauthenticationWindow = 10 * time.Minute
+
+// DeleteAccount soft deletes the current user's account after checking the
+// password and second factor, and ends every session. The returned token
+// undoes the deletion until the grace period ends.
+func (s *AuthService) DeleteAccount(ctx context.Context, current *jwt.Claims, req *models.DeleteAccountRequest) (*models.AccountDeletionResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := s.reauthenticate(ctx, user, current, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaService.CheckCode(ctx, user.ID, req.Code); err != nil {
+		return nil, err
+	}
+
+	restoreToken, err := s.tokenService.IssueOneTimeToken(ctx, user, jwt.AccountRestoreToken, s.settings.DeletionGracePeriod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue restore token: %w", err)
+	}
+
+	if err := s.userRepo.Delete(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	if err := s.tokenService.RevokeAllForUser(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.auditService.Record(ctx, models.AuditEventAccountDeleted, user.ID, user.ID, nil)
+
+	if user.Email != "" {
+		s.sendAccountDeletedEmailAsync(user, restoreToken)
+	}
+
+	return &models.AccountDeletionResponse{
+		RestoreToken:  restoreToken,
+		RestoreBefore: time.Now().Add(s.settings.DeletionGracePeriod),
+	}, nil
+}
+
+// RestoreAccount undoes an account deletion within the grace period. The
+// user has to log in again afterwards.
+func (s *AuthService) RestoreAccount(ctx context.Context, req *models.RestoreAccountRequest) error {
+	claims, err := s.tokenService.CheckOneTimeToken(ctx, req.Token, jwt.AccountRestoreToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Restore(ctx, claims.UserID, time.Now().Add(-s.settings.DeletionGracePeriod)); err != nil {
+		// The account is not restored without its Telegram login
+		if errors.Is(err, repository.ErrTelegramIDTaken) {
+			return fmt.Errorf("telegram account already linked to another user")
+		}
+		log.Printf("Failed to restore user %d: %v", claims.UserID, err)
+		return fmt.Errorf("account can no longer be restored")
+	}
+
+	// Spend the token only once the account is back, so a restore that failed
+	// on the way can be retried with the same link
+	if _, err := s.tokenService.ConsumeOneTimeToken(ctx, req.Token, jwt.AccountRestoreToken); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEventAccountRestored, claims.UserID, claims.UserID, nil)
+
+	return nil
+}
+
+// PurgeDeletedAccounts permanently removes accounts whose grace period has ended
+func (s *AuthService) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	purged, err := s.userRepo.PurgeDeleted(ctx, time.Now().Add(-s.settings.DeletionGracePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted accounts: %w", err)
+	}
+
+	// The events of purged accounts are kept without their user IDs
+	if purged > 0 {
+		s.auditService.Record(ctx, models.AuditEventAccountsPurged, 0, 0, map[string]interface{}{"count": purged})
+	}
+
+	return purged, nil
+}
+
+// RunAccountPurge purges deleted accounts every interval until ctx is done
+func (s *AuthService) RunAccountPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeDeletedAccounts(ctx)
+		if err != nil {
+			log.Printf("Account purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted accounts", purged)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reauthenticate checks the password of the account, or for accounts
+// without one, that the current session has just been started
+func (s *AuthService) reauthenticate(ctx context.Context, user *models.User, current *jwt.Claims, req *models.DeleteAccountRequest) error {
+	if user.HasPassword() {
+		valid, _, err := checkPassword(ctx, s.hasher, user, req.Password)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return fmt.Errorf("invalid credentials")
+		}
+		return nil
+	}
+
+	sessions, err := s.tokenService.ListSessions(ctx, user.ID, current.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, session := range sessions {
+		if session.Current && time.Since(session.CreatedAt) < reauthenticationWindow {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("recent login required")
+}
+
+// sendAccountDeletedEmailAsync confirms a deletion by email with a link to
+// undo it, in the background
+func (s *AuthService) sendAccountDeletedEmailAsync(user *models.User, restoreToken string) {
+	link := fmt.Sprintf("%s/restore-account?token=%s", s.settings.PublicURL, url.QueryEscape(restoreToken))
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Your Rhythmify account has been deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nYour Rhythmify account has been deleted and will be removed permanently in %s.\n\n"+
+			"If you change your mind, you can restore it before then with the link below:\n\n%s\n",
+			user.Username, s.settings.DeletionGracePeriod, link),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send account deletion email to user %d: %v", user.ID, err)
+		}
+	}()
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+// restoreUserRepo fails Restore with restoreErr while it is set
+type restoreUserRepo struct {
+	*fakeUserRepo
+	restoreErr error
+	restored   bool
+}
+
+func (r *restoreUserRepo) Restore(ctx context.Context, id int64, deletedAfter time.Time) error {
+	if r.restoreErr != nil {
+		return r.restoreErr
+	}
+	r.restored = true
+	return nil
+}
+
+func TestRestoreAccountWithTakenTelegramAccount(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: 1, Email: "user@example.com", Username: "user"}
+	users := &restoreUserRepo{
+		fakeUserRepo: newFakeUserRepo(user),
+		restoreErr:   fmt.Errorf("telegram account of user 1 %w", repository.ErrTelegramIDTaken),
+	}
+	tokens := NewTokenService(users, newFakeSessionRepo(), repository.NewMemoryRefreshTokenRepository(), newFakeOneTimeTokenRepo(), &fakeRoleRepo{}, repository.NewMemoryTokenDenylist(), jwt.NewJWTManager("test-secret", 15*time.Minute, time.Hour), nil)
+	authService := NewAuthService(users, tokens, nil, nil, fakeHasher{}, nil, NewAuditService(&fakeAuditRepo{}), AccountSettings{
+		DeletionGracePeriod: 24 * time.Hour,
+	})
+
+	link, err := tokens.IssueOneTimeToken(ctx, user, jwt.AccountRestoreToken, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueOneTimeToken failed: %v", err)
+	}
+
+	err = authService.RestoreAccount(ctx, &models.RestoreAccountRequest{Token: link})
+	if err == nil || err.Error() != "telegram account already linked to another user" {
+		t.Fatalf("RestoreAccount = %v, want telegram account already linked to another user", err)
+	}
+
+	// The link still works once the Telegram account has been unlinked
+	users.restoreErr = nil
+	if err := authService.RestoreAccount(ctx, &models.RestoreAccountRequest{Token: link}); err != nil {
+		t.Fatalf("RestoreAccount after unlinking = %v, want success", err)
+	}
+	if !users.restored {
+		t.Error("account was not restored")
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"rhythmify/services/auth-service/internal/audit"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+)
+
+// AccountSuspendedError is returned when a suspended account tries to log in
+// or refresh its tokens
+type AccountSuspendedError struct{}
+
+// Error implements the error interface
+func (e *AccountSuspendedError) Error() string {
+	return "account suspended"
+}
+
+// AdminService lets support staff find and manage user accounts. Every
+// change is recorded in the admin action log with the acting admin's ID,
+// and changes made through the auth service appear in the audit log as
+// caused by the admin.
+type AdminService struct {
+	userRepo    repository.UserRepository
+	actionRepo  repository.AdminActionRepository
+	authService *AuthService
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(userRepo repository.UserRepository, actionRepo repository.AdminActionRepository, authService *AuthService) *AdminService {
+	return &AdminService{
+		userRepo:    userRepo,
+		actionRepo:  actionRepo,
+		authService: authService,
+	}
+}
+
+// ListUsers returns a page of the users matching the filter
+func (s *AdminService) ListUsers(ctx context.Context, filter *models.UserFilter) (*models.UserListResponse, error) {
+	beforeID, limit, err := pageParams(filter.Cursor, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra user to find out whether there is a next page
+	users, err := s.userRepo.Search(ctx, filter, beforeID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	resp := &models.UserListResponse{Users: make([]*models.AdminUserResponse, 0, len(users))}
+	if len(users) > limit {
+		users = users[:limit]
+		resp.NextCursor = strconv.FormatInt(users[limit-1].ID, 10)
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, user.ToAdminResponse())
+	}
+
+	return resp, nil
+}
+
+// GetUser returns a user by ID
+func (s *AdminService) GetUser(ctx context.Context, userID int64) (*models.AdminUserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return user.ToAdminResponse(), nil
+}
+
+// UpdateUser edits the profile fields of a user
+func (s *AdminService) UpdateUser(ctx context.Context, adminID int64, userID int64, req *models.UpdateUserRequest) (*models.AdminUserResponse, error) {
+	before, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	if _, err := s.authService.UpdateProfile(audit.WithActor(ctx, adminID), userID, req); err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{}
+	if req.Email != nil && *req.Email != before.Email {
+		details["email"] = map[string]string{"from": before.Email, "to": *req.Email}
+	}
+	if req.Username != nil && *req.Username != before.Username {
+		details["username"] = map[string]string{"from": before.Username, "to": *req.Username}
+	}
+	s.record(ctx, adminID, userID, models.AdminActionUpdateUser, details)
+
+	return s.GetUser(ctx, userID)
+}
+
+// SuspendUser blocks a user from logging in and ends their sessions
+func (s *AdminService) SuspendUser(ctx context.Context, adminID int64, userID int64, reason string) (*models.AdminUserResponse, error) {
+	if adminID == userID {
+		return nil, fmt.Errorf("cannot suspend own account")
+	}
+
+	if err := s.userRepo.SetSuspended(ctx, userID, true, reason); err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	if err := s.authService.LogoutAll(audit.WithActor(ctx, adminID), userID); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, adminID, userID, models.AdminActionSuspendUser, map[string]interface{}{"reason": reason})
+
+	return s.GetUser(ctx, userID)
+}
+
+// UnsuspendUser lifts the suspension of a user
+func (s *AdminService) UnsuspendUser(ctx context.Context, adminID int64, userID int64) (*models.AdminUserResponse, error) {
+	if err := s.userRepo.SetSuspended(ctx, userID, false, ""); err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	s.record(ctx, adminID, userID, models.AdminActionUnsuspendUser, nil)
+
+	return s.GetUser(ctx, userID)
+}
+
+// ForcePasswordReset makes a user choose a new password at their next login
+// and ends their sessions
+func (s *AdminService) ForcePasswordReset(ctx context.Context, adminID int64, userID int64) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return fmt.Errorf("user not found")
+	}
+
+	if err := s.authService.RequirePasswordChange(audit.WithActor(ctx, adminID), userID); err != nil {
+		return err
+	}
+
+	s.record(ctx, adminID, userID, models.AdminActionForcePasswordReset, nil)
+
+	return nil
+}
+
+// RevokeSessions ends every session of a user
+func (s *AdminService) RevokeSessions(ctx context.Context, adminID int64, userID int64) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return fmt.Errorf("user not found")
+	}
+
+	if err := s.authService.LogoutAll(audit.WithActor(ctx, adminID), userID); err != nil {
+		return err
+	}
+
+	s.record(ctx, adminID, userID, models.AdminActionRevokeSessions, nil)
+
+	return nil
+}
+
+// ClearLockout lifts a login lockout of a user's account
+func (s *AdminService) ClearLockout(ctx context.Context, adminID int64, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if user.Email == "" {
+		return fmt.Errorf("user has no email address")
+	}
+
+	if err := s.authService.ClearLockout(audit.WithActor(ctx, adminID), &models.ClearLockoutRequest{Email: user.Email}); err != nil {
+		return err
+	}
+
+	s.record(ctx, adminID, userID, models.AdminActionClearLockout, nil)
+
+	return nil
+}
+
+// ListActions returns a page of the admin action log
+func (s *AdminService) ListActions(ctx context.Context, filter *models.AdminActionFilter) (*models.AdminActionListResponse, error) {
+	beforeID, limit, err := pageParams(filter.Cursor, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.actionRepo.List(ctx, filter, beforeID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+
+	resp := &models.AdminActionListResponse{Actions: actions}
+	if len(actions) > limit {
+		resp.Actions = actions[:limit]
+		resp.NextCursor = strconv.FormatInt(actions[limit-1].ID, 10)
+	}
+
+	return resp, nil
+}
+
+// record writes an entry to the admin action log. The action has already
+// taken effect, so a failure is logged rather than returned.
+func (s *AdminService) record(ctx context.Context, adminID int64, userID int64, action string, details map[string]interface{}) {
+	recordAdminAction(ctx, s.actionRepo, adminID, userID, action, details)
+}
+
+// recordAdminAction writes an entry to the admin action log
+func recordAdminAction(ctx context.Context, actionRepo repository.AdminActionRepository, adminID int64, userID int64, action string, details map[string]interface{}) {
+	entry := &models.AdminAction{
+		AdminID:      &adminID,
+		TargetUserID: &userID,
+		Action:       action,
+		Details:      details,
+	}
+	if err := actionRepo.Create(ctx, entry); err != nil {
+		log.Printf("Failed to record admin action %s by user %d on user %d: %v", action, adminID, userID, err)
+	}
+}
+
+// pageParams decodes a keyset cursor and applies the default page size
+func pageParams(cursor string, limit int) (int64, int, error) {
+	if limit <= 0 {
+		limit = models.DefaultAdminPageSize
+	}
+
+	if cursor == "" {
+		return 0, limit, nil
+	}
+
+	beforeID, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || beforeID <= 0 {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+
+	return beforeID, limit, nil
+}
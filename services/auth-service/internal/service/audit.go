@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"rhythmify/services/auth-service/internal/audit"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+)
+
+// AuditService records security-relevant account activity in the audit log
+// and streams it to the configured sinks
+type AuditService struct {
+	auditRepo repository.AuditEventRepository
+	sinks     []audit.Sink
+}
+
+// NewAuditService creates a new audit service. Every event is stored with
+// auditRepo and copied to each of sinks.
+func NewAuditService(auditRepo repository.AuditEventRepository, sinks ...audit.Sink) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		sinks:     sinks,
+	}
+}
+
+// Record writes an event on userID's account caused by actorID, either of
+// which is 0 when unknown. An actor set with audit.WithActor takes
+// precedence, and the client is taken from the request information of ctx.
+// The event has already happened, so failures are logged rather than
+// returned.
+func (s *AuditService) Record(ctx context.Context, eventType string, actorID int64, userID int64, metadata map[string]interface{}) {
+	if admin, ok := audit.ActorFromContext(ctx); ok {
+		actorID = admin
+	}
+	info := audit.RequestInfoFromContext(ctx)
+
+	event := &models.AuditEvent{
+		EventType: eventType,
+		IPAddress: info.IPAddress,
+		UserAgent: info.UserAgent,
+		RequestID: info.RequestID,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if actorID != 0 {
+		event.ActorID = &actorID
+	}
+	if userID != 0 {
+		event.TargetUserID = &userID
+	}
+	if event.Metadata == nil {
+		event.Metadata = map[string]interface{}{}
+	}
+
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		log.Printf("Failed to record audit event %s on user %d: %v", eventType, userID, err)
+	}
+
+	// Sinks get the event even if the database is unavailable
+	for _, sink := range s.sinks {
+		if err := sink.Write(ctx, event); err != nil {
+			log.Printf("Failed to stream audit event %s on user %d: %v", eventType, userID, err)
+		}
+	}
+}
+
+// ListActivity returns a page of the events on the user's own account
+func (s *AuditService) ListActivity(ctx context.Context, userID int64, filter *models.ActivityFilter) (*models.AuditEventListResponse, error) {
+	return s.ListEvents(ctx, &models.AuditEventFilter{
+		TargetUserID:  userID,
+		EventType:     filter.EventType,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		Cursor:        filter.Cursor,
+		Limit:         filter.Limit,
+	})
+}
+
+// ListEvents returns a page of the audit log
+func (s *AuditService) ListEvents(ctx context.Context, filter *models.AuditEventFilter) (*models.AuditEventListResponse, error) {
+	beforeID, limit, err := pageParams(filter.Cursor, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra event to find out whether there is a next page
+	events, err := s.auditRepo.List(ctx, filter, beforeID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	resp := &models.AuditEventListResponse{Events: events}
+	if len(events) > limit {
+		resp.Events = events[:limit]
+		resp.NextCursor = strconv.FormatInt(events[limit-1].ID, 10)
+	}
+
+	return resp, nil
+}
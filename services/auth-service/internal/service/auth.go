@@ -2,29 +2,73 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"rhythmify/services/auth-service/internal/mailer"
 	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/passwords"
 	"rhythmify/services/auth-service/internal/repository"
 	"rhythmify/shared/jwt"
 )
 
 // AuthService handles authentication business logic
 type AuthService struct {
-	userRepo   repository.UserRepository
-	jwtManager *jwt.JWTManager
+	userRepo     repository.UserRepository
+	tokenService *TokenService
+	mfaService   *MFAService
+	loginGuard   *LoginGuard
+	hasher       passwords.PasswordHasher
+	mailer       mailer.Mailer
+	auditService *AuditService
+	settings     AccountSettings
+}
+
+// LoginResult is the outcome of a password check. Either Tokens is set, or
+// MFAToken is set and must be exchanged at /auth/login/mfa with a code.
+type LoginResult struct {
+	User     *models.UserResponse
+	Tokens   *jwt.TokenPair
+	MFAToken string
+}
+
+// MFARequired returns true if the login must be completed with a second factor
+func (r *LoginResult) MFARequired() bool {
+	return r.MFAToken != ""
+}
+
+// AccountSettings holds tunables for account flows
+type AccountSettings struct {
+	// PublicURL is the base URL of the client app used in emailed links
+	PublicURL string
+
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
+
+	// DeletionGracePeriod is how long a deleted account can be restored before it is purged
+	DeletionGracePeriod time.Duration
+
+	// PasswordPolicy applies to every new password
+	PasswordPolicy *passwords.Policy
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo repository.UserRepository, jwtManager *jwt.JWTManager) *AuthService {
+func NewAuthService(userRepo repository.UserRepository, tokenService *TokenService, mfaService *MFAService, loginGuard *LoginGuard, hasher passwords.PasswordHasher, mailer mailer.Mailer, auditService *AuditService, settings AccountSettings) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		mfaService:   mfaService,
+		loginGuard:   loginGuard,
+		hasher:       hasher,
+		mailer:       mailer,
+		auditService: auditService,
+		settings:     settings,
 	}
 }
 
 // Register creates a new user account
-func (s *AuthService) Register(ctx context.Context, req *models.CreateUserRequest) (*models.UserResponse, *jwt.TokenPair, error) {
+func (s *AuthService) Register(ctx context.Context, req *models.CreateUserRequest, client *models.ClientInfo) (*models.UserResponse, *jwt.TokenPair, error) {
 	// Check if email already exists
 	emailExists, err := s.userRepo.CheckEmailExists(ctx, req.Email)
 	if err != nil {
@@ -43,25 +87,33 @@ func (s *AuthService) Register(ctx context.Context, req *models.CreateUserReques
 		return nil, nil, fmt.Errorf("username already exists")
 	}
 
+	// Apply password policy and hash password
+	if err := s.validateNewPassword(req.Password, req.Email, req.Username); err != nil {
+		return nil, nil, err
+	}
+	passwordHash, err := s.hasher.Hash(ctx, req.Password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
 	// Create user object
 	user := &models.User{
 		Email:    req.Email,
 		Username: req.Username,
-		Password: req.Password,
-	}
-
-	// Hash password
-	if err := user.HashPassword(); err != nil {
-		return nil, nil, fmt.Errorf("failed to hash password: %w", err)
+		Password: passwordHash,
 	}
 
 	// Save user to database
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	s.auditService.Record(ctx, models.AuditEventUserRegistered, user.ID, user.ID, nil)
 
-	// Generate tokens
-	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Username)
+	// Ask the user to confirm the address; the account works meanwhile
+	s.sendVerificationEmailAsync(user)
+
+	// Start a session and generate tokens
+	tokens, err := s.tokenService.StartSession(ctx, user, client)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
@@ -69,39 +121,153 @@ func (s *AuthService) Register(ctx context.Context, req *models.CreateUserReques
 	return user.ToResponse(), tokens, nil
 }
 
-// Login authenticates a user and returns tokens
-func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.UserResponse, *jwt.TokenPair, error) {
-	// Get user by email
-	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+// Login authenticates a user and returns tokens, or an mfa_pending token
+// when two-factor authentication is enabled
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, client *models.ClientInfo) (*LoginResult, error) {
+	user, err := s.authenticate(ctx, req.Email, req.Password, client)
 	if err != nil {
-		return nil, nil, fmt.Errorf("invalid credentials")
+		return nil, err
 	}
 
-	// Check password
-	if !user.CheckPassword(req.Password) {
-		return nil, nil, fmt.Errorf("invalid credentials")
+	// An admin may require a new password before the account can be used
+	if user.MustChangePassword {
+		s.recordLoginFailure(ctx, user.ID, req.Email, "password_change_required")
+		return nil, fmt.Errorf("password change required")
 	}
 
-	// Generate tokens
-	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Username)
+	return s.completeLogin(ctx, user, client)
+}
+
+// authenticate checks an email and password, counting failures against
+// the account and the client IP
+func (s *AuthService) authenticate(ctx context.Context, email string, password string, client *models.ClientInfo) (*models.User, error) {
+	// Refuse locked out accounts before looking at the password
+	if err := s.loginGuard.Check(ctx, email, client); err != nil {
+		s.recordLoginFailure(ctx, 0, email, "locked_out")
+		return nil, err
+	}
+
+	// Get user by email and check password
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
+		s.loginGuard.RecordFailure(ctx, email, client)
+		s.recordLoginFailure(ctx, 0, email, "unknown_email")
+		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	return user.ToResponse(), tokens, nil
+	valid, needsRehash, err := checkPassword(ctx, s.hasher, user, password)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		s.loginGuard.RecordFailure(ctx, email, client)
+		s.recordLoginFailure(ctx, user.ID, email, "invalid_password")
+		return nil, fmt.Errorf("invalid credentials")
+	}
+
+	// Only tell the owner of the password that the account is suspended
+	if user.IsSuspended() {
+		s.recordLoginFailure(ctx, user.ID, email, "account_suspended")
+		return nil, &AccountSuspendedError{}
+	}
+
+	// Upgrade hashes made with old parameters while the password is at hand
+	if needsRehash {
+		s.rehashPasswordAsync(user, password)
+	}
+
+	return user, nil
+}
+
+// completeLogin starts a session for a user whose password has been checked,
+// unless a second factor is still needed
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, client *models.ClientInfo) (*LoginResult, error) {
+	result, err := s.mfaService.StartLogin(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// The failure count is kept until the second factor has been checked too,
+	// or it would be reset by every password login of whoever guesses codes
+	eventType := models.AuditEventLoginSucceeded
+	if result.MFARequired() {
+		eventType = models.AuditEventLoginMFARequired
+	} else {
+		s.loginGuard.RecordSuccess(ctx, user.Email)
+	}
+	s.auditService.Record(ctx, eventType, user.ID, user.ID, map[string]interface{}{"method": models.LoginMethodPassword})
+
+	return result, nil
+}
+
+// recordLoginFailure writes a failed login to the audit log. userID is 0
+// when the email does not belong to an account.
+func (s *AuthService) recordLoginFailure(ctx context.Context, userID int64, email string, reason string) {
+	s.auditService.Record(ctx, models.AuditEventLoginFailed, 0, userID, map[string]interface{}{
+		"method": models.LoginMethodPassword,
+		"email":  email,
+		"reason": reason,
+	})
 }
 
 // RefreshToken generates new tokens using refresh token
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*jwt.TokenPair, error) {
-	// Validate and refresh token
-	tokens, err := s.jwtManager.RefreshAccessToken(refreshToken)
+	// Validate, rotate and refresh token
+	tokens, err := s.tokenService.Rotate(ctx, refreshToken)
 	if err != nil {
+		s.auditService.Record(ctx, models.AuditEventRefreshFailed, 0, 0, map[string]interface{}{"reason": err.Error()})
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
 	return tokens, nil
 }
 
+// Logout revokes the current access token and its refresh token family
+func (s *AuthService) Logout(ctx context.Context, claims *jwt.Claims) error {
+	if err := s.tokenService.Revoke(ctx, claims); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventLogout, claims.UserID, claims.UserID, map[string]interface{}{"session_id": claims.SessionID})
+
+	return nil
+}
+
+// LogoutAll revokes every token issued to the user
+func (s *AuthService) LogoutAll(ctx context.Context, userID int64) error {
+	if err := s.tokenService.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventLogoutAll, userID, userID, nil)
+
+	return nil
+}
+
+// ListSessions returns the active sessions of the user
+func (s *AuthService) ListSessions(ctx context.Context, userID int64, currentSessionID string) ([]*models.Session, error) {
+	sessions, err := s.tokenService.ListSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession ends one of the user's sessions
+func (s *AuthService) DeleteSession(ctx context.Context, userID int64, sessionID string) error {
+	if err := s.tokenService.EndSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventSessionRevoked, userID, userID, map[string]interface{}{"session_id": sessionID})
+
+	return nil
+}
+
 // GetProfile returns user profile information
 func (s *AuthService) GetProfile(ctx context.Context, userID int64) (*models.UserResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -119,6 +285,7 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID int64, req *mode
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
+	previousEmail, previousUsername := user.Email, user.Username
 
 	// Update fields if provided
 	if req.Email != nil {
@@ -154,23 +321,23 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID int64, req *mode
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
-	return user.ToResponse(), nil
-}
-
-// LinkTelegram links a Telegram account to a user
-func (s *AuthService) LinkTelegram(ctx context.Context, userID int64, req *models.LinkTelegramRequest) error {
-	// Check if Telegram ID is already linked to another user
-	existingUser, err := s.userRepo.GetByTelegramID(ctx, req.TelegramID)
-	if err == nil && existingUser.ID != userID {
-		return fmt.Errorf("telegram account already linked to another user")
+	changes := map[string]interface{}{}
+	if user.Email != previousEmail {
+		changes["email"] = map[string]string{"from": previousEmail, "to": user.Email}
+	}
+	if user.Username != previousUsername {
+		changes["username"] = map[string]string{"from": previousUsername, "to": user.Username}
+	}
+	if len(changes) > 0 {
+		s.auditService.Record(ctx, models.AuditEventProfileUpdated, userID, userID, changes)
 	}
 
-	// Link Telegram ID to user
-	if err := s.userRepo.LinkTelegram(ctx, userID, req.TelegramID); err != nil {
-		return fmt.Errorf("failed to link telegram: %w", err)
+	// A changed email address has to be verified again
+	if user.Email != previousEmail {
+		s.sendVerificationEmailAsync(user)
 	}
 
-	return nil
+	return user.ToResponse(), nil
 }
 
 // GetUserByTelegramID retrieves a user by their Telegram ID
@@ -183,9 +350,14 @@ func (s *AuthService) GetUserByTelegramID(ctx context.Context, telegramID int64)
 	return user.ToResponse(), nil
 }
 
+// JWKS returns the JSON Web Key Set used to verify issued tokens
+func (s *AuthService) JWKS() *jwt.JWKSet {
+	return s.tokenService.JWKS()
+}
+
 // ValidateToken validates a JWT token and returns user claims
 func (s *AuthService) ValidateToken(tokenString string) (*jwt.Claims, error) {
-	claims, err := s.jwtManager.ValidateToken(tokenString)
+	claims, err := s.tokenService.ValidateAccessToken(tokenString)
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
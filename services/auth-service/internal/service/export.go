@@ -0,0 +1,484 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"rhythmify/services/auth-service/internal/mailer"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+// exportBuildTimeout bounds how long building one archive may take. Exports
+// pending for longer are considered lost.
+const exportBuildTimeout = 10 * time.Minute
+
+// auditExportPageSize is how many audit events are read at a time for an export
+const auditExportPageSize = 500
+
+// ExportSource contributes one section to a user's data export. Services
+// plug their own data into exports by registering a source.
+type ExportSource interface {
+	// Name is the section name, used as the file name inside the archive
+	Name() string
+
+	// Export returns everything the source holds about the user. The
+	// result is encoded as JSON.
+	Export(ctx context.Context, userID int64) (interface{}, error)
+}
+
+// ExportFunc collects the data of an export section
+type ExportFunc func(ctx context.Context, userID int64) (interface{}, error)
+
+// exportSourceFunc adapts an ExportFunc to ExportSource
+type exportSourceFunc struct {
+	name string
+	fn   ExportFunc
+}
+
+func (s *exportSourceFunc) Name() string { return s.name }
+
+func (s *exportSourceFunc) Export(ctx context.Context, userID int64) (interface{}, error) {
+	return s.fn(ctx, userID)
+}
+
+// NewExportSource creates an export source from a function
+func NewExportSource(name string, fn ExportFunc) ExportSource {
+	return &exportSourceFunc{name: name, fn: fn}
+}
+
+// ExportSettings holds tunables for data exports
+type ExportSettings struct {
+	// Dir is where archives are kept until they are downloaded or expire
+	Dir string
+
+	// DownloadURL is the public URL of the download endpoint
+	DownloadURL string
+
+	// TTL is how long a built archive can be downloaded
+	TTL time.Duration
+}
+
+// ExportService builds archives of everything stored about a user
+type ExportService struct {
+	exportRepo   repository.DataExportRepository
+	userRepo     repository.UserRepository
+	tokenService *TokenService
+	mailer       mailer.Mailer
+	settings     ExportSettings
+
+	mu      sync.RWMutex
+	sources []ExportSource
+}
+
+// NewExportService creates a new export service
+func NewExportService(exportRepo repository.DataExportRepository, userRepo repository.UserRepository, tokenService *TokenService, mailer mailer.Mailer, settings ExportSettings) (*ExportService, error) {
+	if err := os.MkdirAll(settings.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	return &ExportService{
+		exportRepo:   exportRepo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		mailer:       mailer,
+		settings:     settings,
+	}, nil
+}
+
+// Register adds a source to every export built from now on. Section names
+// must be unique.
+func (s *ExportService) Register(source ExportSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.sources {
+		if existing.Name() == source.Name() {
+			panic(fmt.Sprintf("export source %q registered twice", source.Name()))
+		}
+	}
+	s.sources = append(s.sources, source)
+}
+
+// RequestExport starts building an archive of the user's data in the background
+func (s *ExportService) RequestExport(ctx context.Context, userID int64) (*models.DataExport, error) {
+	if _, err := s.exportRepo.GetActiveForUser(ctx, userID); err == nil {
+		return nil, fmt.Errorf("export already requested")
+	}
+
+	id, err := jwt.NewTokenID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate export id: %w", err)
+	}
+
+	export := &models.DataExport{
+		ID:     id,
+		UserID: userID,
+		Status: models.ExportPending,
+	}
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	s.buildAsync(export)
+
+	return export, nil
+}
+
+// GetExport returns an export of the user. A ready export comes with a
+// fresh download link, which replaces any link issued before.
+func (s *ExportService) GetExport(ctx context.Context, userID int64, id string) (*models.DataExportResponse, error) {
+	export, err := s.exportRepo.GetForUser(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("export not found")
+	}
+
+	resp := &models.DataExportResponse{DataExport: export}
+	if export.Status == models.ExportReady && export.ExpiresAt.After(time.Now()) {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("user not found: %w", err)
+		}
+
+		resp.DownloadURL, err = s.issueDownloadURL(ctx, user, export.ID, *export.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to issue download link: %w", err)
+		}
+	}
+
+	return resp, nil
+}
+
+// Download consumes a download link and returns the export it was issued
+// for. The archive can be downloaded only once; call Discard after serving it.
+func (s *ExportService) Download(ctx context.Context, token string) (*models.DataExport, error) {
+	claims, err := s.tokenService.ConsumeOneTimeToken(ctx, token, jwt.DataExportToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ResourceID == "" {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	export, err := s.exportRepo.ConsumeReady(ctx, claims.ResourceID, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	return export, nil
+}
+
+// Discard removes the archive of an export
+func (s *ExportService) Discard(export *models.DataExport) {
+	if export.FilePath == "" {
+		return
+	}
+	if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove data export %s: %v", export.ID, err)
+	}
+}
+
+// CleanupExports expires archives that were not downloaded in time and
+// gives up on builds that never finished
+func (s *ExportService) CleanupExports(ctx context.Context) (int, error) {
+	if _, err := s.exportRepo.FailStale(ctx, time.Now().Add(-exportBuildTimeout)); err != nil {
+		return 0, err
+	}
+
+	expired, err := s.exportRepo.ExpireBefore(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for _, export := range expired {
+		s.Discard(export)
+	}
+
+	// Archives of purged accounts lose their rows by cascade
+	s.removeOrphanedArchives(time.Now().Add(-s.settings.TTL - exportBuildTimeout))
+
+	return len(expired), nil
+}
+
+// RunExportCleanup cleans up exports every interval until ctx is done
+func (s *ExportService) RunExportCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := s.CleanupExports(ctx)
+		if err != nil {
+			log.Printf("Data export cleanup failed: %v", err)
+		} else if expired > 0 {
+			log.Printf("Removed %d expired data exports", expired)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// buildAsync builds an export in the background and emails the download
+// link once it is ready
+func (s *ExportService) buildAsync(export *models.DataExport) {
+	pending := *export
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), exportBuildTimeout)
+		defer cancel()
+
+		path, err := s.build(ctx, &pending)
+		if err != nil {
+			log.Printf("Failed to build data export %s for user %d: %v", pending.ID, pending.UserID, err)
+			if err := s.exportRepo.MarkFailed(ctx, pending.ID); err != nil {
+				log.Printf("Failed to mark data export %s failed: %v", pending.ID, err)
+			}
+			return
+		}
+
+		expiresAt := time.Now().Add(s.settings.TTL)
+		if err := s.exportRepo.MarkReady(ctx, pending.ID, path, expiresAt); err != nil {
+			log.Printf("Failed to mark data export %s ready: %v", pending.ID, err)
+			pending.FilePath = path
+			s.Discard(&pending)
+			return
+		}
+
+		if err := s.sendExportReadyEmail(ctx, pending.UserID, pending.ID, expiresAt); err != nil {
+			log.Printf("Failed to send data export email to user %d: %v", pending.UserID, err)
+		}
+	}()
+}
+
+// build writes the archive of an export: a manifest plus one JSON file per source
+func (s *ExportService) build(ctx context.Context, export *models.DataExport) (string, error) {
+	s.mu.RLock()
+	sources := append([]ExportSource(nil), s.sources...)
+	s.mu.RUnlock()
+
+	manifest := &models.DataExportManifest{
+		UserID:      export.UserID,
+		GeneratedAt: time.Now().UTC(),
+		Sections:    make([]string, 0, len(sources)),
+	}
+	for _, source := range sources {
+		manifest.Sections = append(manifest.Sections, source.Name())
+	}
+
+	path := filepath.Join(s.settings.Dir, export.ID+".zip")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	err = writeExportArchive(ctx, file, export.UserID, manifest, sources)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// writeExportArchive writes the manifest and every section to a zip archive
+func writeExportArchive(ctx context.Context, file *os.File, userID int64, manifest *models.DataExportManifest, sources []ExportSource) error {
+	archive := zip.NewWriter(file)
+
+	if err := writeExportEntry(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		data, err := source.Export(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", source.Name(), err)
+		}
+		if err := writeExportEntry(archive, source.Name()+".json", data); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return nil
+}
+
+// writeExportEntry adds a JSON file to an archive
+func writeExportEntry(archive *zip.Writer, name string, data interface{}) error {
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// issueDownloadURL issues a single-use download link for an export, valid
+// until its archive expires
+func (s *ExportService) issueDownloadURL(ctx context.Context, user *models.User, exportID string, expiresAt time.Time) (string, error) {
+	token, err := s.tokenService.IssueOneTimeToken(ctx, user, jwt.DataExportToken, time.Until(expiresAt), jwt.WithResource(exportID))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s?token=%s", s.settings.DownloadURL, url.QueryEscape(token)), nil
+}
+
+// sendExportReadyEmail emails the download link of a ready export
+func (s *ExportService) sendExportReadyEmail(ctx context.Context, userID int64, exportID string, expiresAt time.Time) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	link, err := s.issueDownloadURL(ctx, user, exportID, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Your Rhythmify data export is ready",
+		Body: fmt.Sprintf("Hi %s,\n\nThe copy of your Rhythmify data you asked for is ready. Download it with the link below:\n\n%s\n\n"+
+			"The link works once and expires in %s. If you did not ask for your data, please change your password.\n",
+			user.Username, link, s.settings.TTL),
+	}
+
+	return s.mailer.Send(ctx, msg)
+}
+
+// removeOrphanedArchives removes archives last written before the given time
+func (s *ExportService) removeOrphanedArchives(before time.Time) {
+	entries, err := os.ReadDir(s.settings.Dir)
+	if err != nil {
+		log.Printf("Failed to read export directory: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(before) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.settings.Dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove orphaned data export %s: %v", entry.Name(), err)
+		}
+	}
+}
+
+// ExportSources returns the account data held by the auth service: the
+// profile, the linked Telegram account, sessions and login history
+func (s *AuthService) ExportSources() []ExportSource {
+	return []ExportSource{
+		NewExportSource("profile", func(ctx context.Context, userID int64) (interface{}, error) {
+			return s.userRepo.GetByID(ctx, userID)
+		}),
+		NewExportSource("telegram", func(ctx context.Context, userID int64) (interface{}, error) {
+			user, err := s.userRepo.GetByID(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			return &models.TelegramExport{TelegramID: user.TelegramID}, nil
+		}),
+		NewExportSource("sessions", func(ctx context.Context, userID int64) (interface{}, error) {
+			return s.tokenService.ListSessions(ctx, userID, "")
+		}),
+		NewExportSource("login_history", func(ctx context.Context, userID int64) (interface{}, error) {
+			sessions, err := s.tokenService.ListSessions(ctx, userID, "")
+			if err != nil {
+				return nil, err
+			}
+
+			// Every session was started by a login
+			logins := make([]*models.LoginRecord, 0, len(sessions))
+			for _, session := range sessions {
+				logins = append(logins, &models.LoginRecord{
+					LoggedInAt: session.CreatedAt,
+					DeviceName: session.DeviceName,
+					UserAgent:  session.UserAgent,
+					IPAddress:  session.IPAddress,
+				})
+			}
+			return logins, nil
+		}),
+	}
+}
+
+// ExportSource returns the two-factor settings of the user for data exports
+func (s *MFAService) ExportSource() ExportSource {
+	return NewExportSource("two_factor", func(ctx context.Context, userID int64) (interface{}, error) {
+		enabled, err := s.IsEnabled(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.TwoFactorExport{Enabled: enabled}, nil
+	})
+}
+
+// ExportSource returns the passkeys of the user for data exports
+func (s *PasskeyService) ExportSource() ExportSource {
+	return NewExportSource("passkeys", func(ctx context.Context, userID int64) (interface{}, error) {
+		return s.ListPasskeys(ctx, userID)
+	})
+}
+
+// ExportSource returns the API keys of the user for data exports. Only the
+// visible prefix of each key is included.
+func (s *APIKeyService) ExportSource() ExportSource {
+	return NewExportSource("api_keys", func(ctx context.Context, userID int64) (interface{}, error) {
+		return s.ListAPIKeys(ctx, userID)
+	})
+}
+
+// ExportSource returns the audit events on the user's account for data exports
+func (s *AuditService) ExportSource() ExportSource {
+	return NewExportSource("audit_events", func(ctx context.Context, userID int64) (interface{}, error) {
+		filter := &models.AuditEventFilter{TargetUserID: userID}
+		events := []*models.AuditEvent{}
+		var beforeID int64
+		for {
+			page, err := s.auditRepo.List(ctx, filter, beforeID, auditExportPageSize)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, page...)
+			if len(page) < auditExportPageSize {
+				return events, nil
+			}
+			beforeID = page[len(page)-1].ID
+		}
+	})
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+)
+
+// fakeUserRepo keeps users in memory. Methods a test does not need panic
+// through the embedded nil interface.
+type fakeUserRepo struct {
+	repository.UserRepository
+
+	mu     sync.Mutex
+	users  map[int64]*models.User
+	nextID int64
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: make(map[int64]*models.User)}
+	for _, user := range users {
+		repo.add(user)
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) add(user *models.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID == 0 {
+		r.nextID++
+		user.ID = r.nextID
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *models.User) error {
+	r.add(user)
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	result := *user
+	return &result, nil
+}
+
+func (r *fakeUserRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.TelegramID != nil && *user.TelegramID == telegramID {
+			result := *user
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *fakeUserRepo) LinkTelegram(ctx context.Context, userID int64, telegramID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return fmt.Errorf("user with id %d not found", userID)
+	}
+	user.TelegramID = &telegramID
+	return nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return fmt.Errorf("user with id %d not found", userID)
+	}
+	user.Password = passwordHash
+	user.MustChangePassword = false
+	return nil
+}
+
+// fakeHasher "hashes" passwords by prefixing them, to keep tests fast
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(ctx context.Context, password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (fakeHasher) Verify(ctx context.Context, password string, encoded string) (bool, bool, error) {
+	return encoded == "hashed:"+password, false, nil
+}
+
+// fakeSessionRepo keeps sessions in memory
+type fakeSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]*models.Session
+
+	// err is returned by every call while set, standing in for a database outage
+	err error
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{sessions: make(map[string]*models.Session)}
+}
+
+func (r *fakeSessionRepo) Create(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session.CreatedAt = time.Now()
+	session.LastUsedAt = session.CreatedAt
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *fakeSessionRepo) ListByUser(ctx context.Context, userID int64) ([]*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	sessions := []*models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.ExpiresAt.After(now) {
+			result := *session
+			sessions = append(sessions, &result)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepo) Touch(ctx context.Context, id string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	session, ok := r.sessions[id]
+	if !ok {
+		return fmt.Errorf("session %s %w", id, repository.ErrNotFound)
+	}
+	session.LastUsedAt = time.Now()
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *fakeSessionRepo) DeleteForUser(ctx context.Context, id string, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID {
+		return fmt.Errorf("session %s %w", id, repository.ErrNotFound)
+	}
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *fakeSessionRepo) DeleteAllForUser(ctx context.Context, userID int64, exceptID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted []string
+	for id, session := range r.sessions {
+		if session.UserID == userID && id != exceptID {
+			delete(r.sessions, id)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+func (r *fakeSessionRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *fakeSessionRepo) exists(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.sessions[id]
+	return ok
+}
+
+// fakeOneTimeTokenRepo keeps one-time tokens in memory
+type fakeOneTimeTokenRepo struct {
+	mu       sync.Mutex
+	tokens   map[string]*models.OneTimeToken
+	failures map[string]int
+}
+
+func newFakeOneTimeTokenRepo() *fakeOneTimeTokenRepo {
+	return &fakeOneTimeTokenRepo{
+		tokens:   make(map[string]*models.OneTimeToken),
+		failures: make(map[string]int),
+	}
+}
+
+func (r *fakeOneTimeTokenRepo) Create(ctx context.Context, token *models.OneTimeToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.CreatedAt = time.Now()
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *fakeOneTimeTokenRepo) Consume(ctx context.Context, id string, purpose string) (*models.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, err := r.active(id, purpose)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	result := *token
+	return &result, nil
+}
+
+func (r *fakeOneTimeTokenRepo) InvalidateForUser(ctx context.Context, userID int64, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeOneTimeTokenRepo) GetActive(ctx context.Context, id string, purpose string) (*models.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, err := r.active(id, purpose)
+	if err != nil {
+		return nil, err
+	}
+	result := *token
+	return &result, nil
+}
+
+func (r *fakeOneTimeTokenRepo) RecordFailedAttempt(ctx context.Context, id string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures[id]++
+	return r.failures[id], nil
+}
+
+func (r *fakeOneTimeTokenRepo) CountCreatedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// active returns an unused, unexpired token. The caller holds the lock.
+func (r *fakeOneTimeTokenRepo) active(id string, purpose string) (*models.OneTimeToken, error) {
+	token, ok := r.tokens[id]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("one-time token %s not found or already used", id)
+	}
+	return token, nil
+}
+
+// fakeRoleRepo grants no roles
+type fakeRoleRepo struct {
+	repository.RoleRepository
+}
+
+func (r *fakeRoleRepo) ListForUser(ctx context.Context, userID int64) ([]string, error) {
+	return nil, nil
+}
+
+// fakeAuditRepo keeps recorded audit events in memory
+type fakeAuditRepo struct {
+	repository.AuditEventRepository
+
+	mu     sync.Mutex
+	events []*models.AuditEvent
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+	return nil
+}
+
+// recorded reports whether an event of the type was recorded with the given
+// reason in its metadata. An empty reason matches any event of the type.
+func (r *fakeAuditRepo) recorded(eventType string, reason string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range r.events {
+		if event.EventType == eventType && (reason == "" || event.Metadata["reason"] == reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeAPIKeyRepo keeps API keys in memory and counts recorded uses
+type fakeAPIKeyRepo struct {
+	repository.APIKeyRepository
+
+	mu      sync.Mutex
+	keys    map[string]*models.APIKey
+	touches int
+}
+
+func newFakeAPIKeyRepo(keys ...*models.APIKey) *fakeAPIKeyRepo {
+	r := &fakeAPIKeyRepo{keys: make(map[string]*models.APIKey)}
+	for _, key := range keys {
+		r.keys[key.KeyHash] = key
+	}
+	return r
+}
+
+func (r *fakeAPIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[keyHash]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepo) Touch(ctx context.Context, id int64, ip string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.touches++
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/authn"
+	"rhythmify/shared/jwt"
+)
+
+func TestIntrospectAPIKeyDoesNotRecordUse(t *testing.T) {
+	ctx := context.Background()
+	rawKey := models.APIKeyPrefix + "introspected"
+	users := newFakeUserRepo(&models.User{ID: 1, Email: "user@example.com", Username: "user"})
+	keys := newFakeAPIKeyRepo(&models.APIKey{ID: 7, UserID: 1, KeyHash: hashAPIKey(rawKey), Scopes: []string{models.ScopeProfileRead}})
+	apiKeys := NewAPIKeyService(keys, users, NewAuditService(&fakeAuditRepo{}), 10, nil)
+	introspection := NewIntrospectionService(nil, apiKeys, users, time.Minute)
+
+	resp, err := introspection.Introspect(ctx, rawKey)
+	if err != nil {
+		t.Fatalf("Introspect failed: %v", err)
+	}
+	if !resp.Active || resp.TokenType != models.IntrospectedAPIKey || resp.Scope != models.ScopeProfileRead {
+		t.Errorf("Introspect = %+v, want an active key with its scope", resp)
+	}
+	if keys.touches != 0 {
+		t.Errorf("introspection recorded %d uses of the key, want none", keys.touches)
+	}
+
+	// Authenticating with the key still records the use
+	if _, err := apiKeys.AuthenticateAPIKey(ctx, rawKey, "192.0.2.1"); err != nil {
+		t.Fatalf("AuthenticateAPIKey failed: %v", err)
+	}
+	if keys.touches != 1 {
+		t.Errorf("authentication recorded %d uses of the key, want 1", keys.touches)
+	}
+}
+
+func TestIntrospectReportsAudience(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: 1, Email: "user@example.com", Username: "user"}
+	users := newFakeUserRepo(user)
+	audience := []string{"rhythmify-api"}
+	tokens := NewTokenService(users, newFakeSessionRepo(), repository.NewMemoryRefreshTokenRepository(), nil, &fakeRoleRepo{}, repository.NewMemoryTokenDenylist(), jwt.NewJWTManager("test-secret", 15*time.Minute, time.Hour), audience)
+	introspection := NewIntrospectionService(tokens, nil, users, time.Minute)
+
+	pair, err := tokens.StartSession(ctx, user, nil)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	// Services verifying the token locally see the audience
+	claims, err := tokens.ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if err := authn.RequireAudience("rhythmify-api")(claims); err != nil {
+		t.Errorf("RequireAudience on a local token = %v, want success", err)
+	}
+
+	// and so do those introspecting it
+	resp, err := introspection.Introspect(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("Introspect failed: %v", err)
+	}
+	if len(resp.Audience) != 1 || resp.Audience[0] != "rhythmify-api" {
+		t.Errorf("introspected audience = %v, want %v", resp.Audience, audience)
+	}
+}
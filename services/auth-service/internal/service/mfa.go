@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"rhythmify/services/auth-service/internal/mfa"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/passwords"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+// maxMFAAttempts is the number of wrong codes accepted per pending MFA login
+const maxMFAAttempts = 5
+
+// MFAService handles TOTP two-factor authentication and recovery codes
+type MFAService struct {
+	userRepo     repository.UserRepository
+	mfaRepo      repository.MFARepository
+	tokenService *TokenService
+	hasher       passwords.PasswordHasher
+	cipher       *mfa.Cipher
+	loginGuard   *LoginGuard
+	auditService *AuditService
+	issuer       string
+	pendingTTL   time.Duration
+}
+
+// NewMFAService creates a new MFA service. Secrets are encrypted with cipher
+// and the pending login token lives for pendingTTL. Wrong codes at login
+// count towards the lockout of loginGuard.
+func NewMFAService(userRepo repository.UserRepository, mfaRepo repository.MFARepository, tokenService *TokenService, hasher passwords.PasswordHasher, cipher *mfa.Cipher, loginGuard *LoginGuard, auditService *AuditService, issuer string, pendingTTL time.Duration) *MFAService {
+	return &MFAService{
+		userRepo:     userRepo,
+		mfaRepo:      mfaRepo,
+		tokenService: tokenService,
+		hasher:       hasher,
+		cipher:       cipher,
+		loginGuard:   loginGuard,
+		auditService: auditService,
+		issuer:       issuer,
+		pendingTTL:   pendingTTL,
+	}
+}
+
+// IsEnabled checks if the user has two-factor authentication turned on
+func (s *MFAService) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	return s.mfaRepo.IsTOTPEnabled(ctx, userID)
+}
+
+// EnrollTOTP starts TOTP enrollment and returns the secret to add to an authenticator app
+func (s *MFAService) EnrollTOTP(ctx context.Context, userID int64) (*models.TOTPEnrollmentResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	enabled, err := s.mfaRepo.IsTOTPEnabled(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check two-factor status: %w", err)
+	}
+	if enabled {
+		return nil, fmt.Errorf("two-factor authentication already enabled")
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	encrypted, err := s.cipher.Encrypt([]byte(secret), totpAssociatedData(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	if err := s.mfaRepo.SaveTOTP(ctx, userID, encrypted); err != nil {
+		return nil, fmt.Errorf("failed to save enrollment: %w", err)
+	}
+
+	return &models.TOTPEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: mfa.KeyURI(s.issuer, accountName(user), secret),
+	}, nil
+}
+
+// ConfirmTOTP finishes enrollment with a code from the authenticator app and
+// returns a fresh set of recovery codes
+func (s *MFAService) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	credential, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("no pending two-factor enrollment")
+	}
+	if credential.IsConfirmed() {
+		return nil, fmt.Errorf("two-factor authentication already enabled")
+	}
+
+	secret, err := s.cipher.Decrypt(credential.SecretEncrypted, totpAssociatedData(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	step, ok := mfa.ValidateCode(string(secret), code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid code")
+	}
+
+	if err := s.mfaRepo.ConfirmTOTP(ctx, userID, step); err != nil {
+		return nil, fmt.Errorf("failed to confirm enrollment: %w", err)
+	}
+	s.auditService.Record(ctx, models.AuditEventMFAEnabled, userID, userID, nil)
+
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// DisableTOTP turns off two-factor authentication after checking the password and a code
+func (s *MFAService) DisableTOTP(ctx context.Context, userID int64, req *models.DisableTOTPRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	passwordValid, _, err := checkPassword(ctx, s.hasher, user, req.Password)
+	if err != nil {
+		return err
+	}
+	if !passwordValid {
+		return fmt.Errorf("invalid credentials")
+	}
+
+	valid, err := s.verifyCode(ctx, userID, req.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("invalid code")
+	}
+
+	if err := s.mfaRepo.DeleteTOTP(ctx, userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventMFADisabled, userID, userID, nil)
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	valid, err := s.verifyCode(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid code")
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditEventRecoveryCodesRegenerated, userID, userID, nil)
+
+	return codes, nil
+}
+
+// CheckCode verifies a code of a user with two-factor authentication
+// enabled and accepts anything otherwise. It guards sensitive actions.
+func (s *MFAService) CheckCode(ctx context.Context, userID int64, code string) error {
+	enabled, err := s.mfaRepo.IsTOTPEnabled(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check two-factor authentication: %w", err)
+	}
+	if !enabled {
+		return nil
+	}
+
+	valid, err := s.verifyCode(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("invalid code")
+	}
+
+	return nil
+}
+
+// StartLogin starts a session for a user who passed the first factor. When
+// two-factor authentication is enabled it issues the short-lived
+// mfa_pending token instead.
+func (s *MFAService) StartLogin(ctx context.Context, user *models.User, client *models.ClientInfo) (*LoginResult, error) {
+	enabled, err := s.mfaRepo.IsTOTPEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check two-factor status: %w", err)
+	}
+
+	if enabled {
+		mfaToken, err := s.tokenService.IssueOneTimeToken(ctx, user, jwt.MFAPendingToken, s.pendingTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate tokens: %w", err)
+		}
+		return &LoginResult{User: user.ToResponse(), MFAToken: mfaToken}, nil
+	}
+
+	// Start a session and generate tokens
+	tokens, err := s.tokenService.StartSession(ctx, user, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	return &LoginResult{User: user.ToResponse(), Tokens: tokens}, nil
+}
+
+// CompleteLogin exchanges an mfa_pending token and a TOTP or recovery code for
+// a token pair. Wrong codes count as failed logins of the account, so a
+// stolen password cannot be used to guess codes across many pending logins.
+func (s *MFAService) CompleteLogin(ctx context.Context, req *models.LoginMFARequest, client *models.ClientInfo) (*models.UserResponse, *jwt.TokenPair, error) {
+	claims, err := s.tokenService.CheckOneTimeToken(ctx, req.MFAToken, jwt.MFAPendingToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Refuse locked out accounts before looking at the code
+	if err := s.loginGuard.Check(ctx, claims.Email, client); err != nil {
+		return nil, nil, err
+	}
+
+	valid, err := s.verifyCode(ctx, claims.UserID, req.Code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !valid {
+		s.loginGuard.RecordFailure(ctx, claims.Email, client)
+		s.auditService.Record(ctx, models.AuditEventLoginFailed, 0, claims.UserID, map[string]interface{}{
+			"method": models.LoginMethodTOTP,
+			"reason": "invalid_code",
+		})
+		if err := s.tokenService.RecordFailedAttempt(ctx, claims, maxMFAAttempts); err != nil {
+			return nil, nil, fmt.Errorf("invalid or expired token")
+		}
+		return nil, nil, fmt.Errorf("invalid code")
+	}
+
+	// Consuming the token makes the exchange single-use even under concurrent requests
+	if _, err := s.tokenService.ConsumeOneTimeToken(ctx, req.MFAToken, jwt.MFAPendingToken); err != nil {
+		return nil, nil, err
+	}
+
+	s.loginGuard.RecordSuccess(ctx, claims.Email)
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid or expired token")
+	}
+
+	tokens, err := s.tokenService.StartSession(ctx, user, client)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventLoginSucceeded, user.ID, user.ID, map[string]interface{}{"method": models.LoginMethodTOTP})
+
+	return user.ToResponse(), tokens, nil
+}
+
+// verifyCode checks a TOTP code or an unused recovery code. Each TOTP time
+// step and each recovery code is accepted only once.
+func (s *MFAService) verifyCode(ctx context.Context, userID int64, code string) (bool, error) {
+	if !mfa.IsTOTPCode(code) {
+		used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, mfa.HashRecoveryCode(code))
+		if err != nil {
+			return false, fmt.Errorf("failed to check recovery code: %w", err)
+		}
+		return used, nil
+	}
+
+	credential, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil || !credential.IsConfirmed() {
+		return false, fmt.Errorf("two-factor authentication not enabled")
+	}
+
+	secret, err := s.cipher.Decrypt(credential.SecretEncrypted, totpAssociatedData(userID))
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	step, ok := mfa.ValidateCode(string(secret), code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	// Reject a replayed code from the same or an earlier time step
+	fresh, err := s.mfaRepo.UseTOTPStep(ctx, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record code use: %w", err)
+	}
+
+	return fresh, nil
+}
+
+// replaceRecoveryCodes generates and stores a new set of recovery codes
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes, err := mfa.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = mfa.HashRecoveryCode(code)
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// accountName is the label shown by authenticator apps. Accounts created
+// through Telegram may have no email address.
+func accountName(user *models.User) string {
+	if user.Email != "" {
+		return user.Email
+	}
+	return user.Username
+}
+
+// totpAssociatedData binds an encrypted TOTP secret to its owner
+func totpAssociatedData(userID int64) []byte {
+	return []byte("totp:" + strconv.FormatInt(userID, 10))
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+// defaultPasskeyName is used when a passkey is registered without a name
+const defaultPasskeyName = "Passkey"
+
+// PasskeyService handles WebAuthn passkey registration and passwordless login
+type PasskeyService struct {
+	userRepo       repository.UserRepository
+	credentialRepo repository.CredentialRepository
+	tokenService   *TokenService
+	webAuthn       *webauthn.WebAuthn
+	auditService   *AuditService
+	ceremonyTTL    time.Duration
+}
+
+// NewPasskeyService creates a new passkey service. A started ceremony must
+// be finished within ceremonyTTL.
+func NewPasskeyService(userRepo repository.UserRepository, credentialRepo repository.CredentialRepository, tokenService *TokenService, webAuthn *webauthn.WebAuthn, auditService *AuditService, ceremonyTTL time.Duration) *PasskeyService {
+	return &PasskeyService{
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		tokenService:   tokenService,
+		webAuthn:       webAuthn,
+		auditService:   auditService,
+		ceremonyTTL:    ceremonyTTL,
+	}
+}
+
+// BeginRegistration starts registering a new passkey for the user
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID int64) (*models.BeginPasskeyResponse, error) {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(
+		user,
+		webauthn.WithExclusions(webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin registration: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, &userID, models.CeremonyRegistration, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BeginPasskeyResponse{CeremonyID: ceremonyID, Options: creation}, nil
+}
+
+// FinishRegistration verifies the authenticator's attestation and stores the passkey
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID int64, req *models.FinishPasskeyRegistrationRequest) (*models.Credential, error) {
+	session, err := s.consumeCeremony(ctx, req.CeremonyID, models.CeremonyRegistration, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey")
+	}
+
+	created, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey")
+	}
+
+	name := req.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	credential := &models.Credential{
+		UserID:          userID,
+		CredentialID:    created.ID,
+		PublicKey:       created.PublicKey,
+		AttestationType: created.AttestationType,
+		Transports:      make([]string, len(created.Transport)),
+		Flags:           uint8(created.Flags.ProtocolValue()),
+		AAGUID:          created.Authenticator.AAGUID,
+		SignCount:       created.Authenticator.SignCount,
+		Name:            name,
+	}
+	for i, transport := range created.Transport {
+		credential.Transports[i] = string(transport)
+	}
+
+	if err := s.credentialRepo.Create(ctx, credential); err != nil {
+		return nil, fmt.Errorf("failed to save passkey: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventPasskeyAdded, userID, userID, map[string]interface{}{
+		"passkey_id": credential.ID,
+		"name":       credential.Name,
+	})
+
+	return credential, nil
+}
+
+// BeginLogin starts a passwordless login with a discoverable credential
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*models.BeginPasskeyResponse, error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin login: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, nil, models.CeremonyLogin, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BeginPasskeyResponse{CeremonyID: ceremonyID, Options: assertion}, nil
+}
+
+// FinishLogin verifies the authenticator's assertion and starts a session
+// the same way a password login does
+func (s *PasskeyService) FinishLogin(ctx context.Context, req *models.FinishPasskeyLoginRequest, client *models.ClientInfo) (*models.UserResponse, *jwt.TokenPair, error) {
+	session, err := s.consumeCeremony(ctx, req.CeremonyID, models.CeremonyLogin, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid passkey")
+	}
+
+	resolved, validated, err := s.webAuthn.ValidatePasskeyLogin(s.discoverUser(ctx), *session, parsed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid passkey")
+	}
+
+	user := resolved.(*passkeyUser)
+	stored := user.credential(validated.ID)
+	if stored == nil {
+		return nil, nil, fmt.Errorf("invalid passkey")
+	}
+
+	flags := uint8(parsed.Response.AuthenticatorData.Flags)
+	cloneWarning := validated.Authenticator.CloneWarning
+	if err := s.credentialRepo.UpdateAfterLogin(ctx, stored.ID, validated.Authenticator.SignCount, flags, cloneWarning); err != nil {
+		return nil, nil, fmt.Errorf("failed to update passkey: %w", err)
+	}
+
+	// A signature counter that went backwards means the key may have been copied
+	if cloneWarning {
+		log.Printf("SECURITY: passkey %d of user %d reported a non-increasing sign count, refusing login", stored.ID, user.ID)
+		s.auditService.Record(ctx, models.AuditEventLoginFailed, 0, user.ID, map[string]interface{}{
+			"method":     models.LoginMethodPasskey,
+			"reason":     "cloned_passkey",
+			"passkey_id": stored.ID,
+		})
+		return nil, nil, fmt.Errorf("invalid passkey")
+	}
+
+	tokens, err := s.tokenService.StartSession(ctx, user.User, client)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventLoginSucceeded, user.ID, user.ID, map[string]interface{}{
+		"method":     models.LoginMethodPasskey,
+		"passkey_id": stored.ID,
+	})
+
+	return user.ToResponse(), tokens, nil
+}
+
+// ListPasskeys returns the passkeys registered by the user
+func (s *PasskeyService) ListPasskeys(ctx context.Context, userID int64) ([]*models.Credential, error) {
+	credentials, err := s.credentialRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// DeletePasskey removes one of the user's passkeys
+func (s *PasskeyService) DeletePasskey(ctx context.Context, userID int64, id int64) error {
+	if err := s.credentialRepo.DeleteForUser(ctx, id, userID); err != nil {
+		return fmt.Errorf("passkey not found")
+	}
+
+	s.auditService.Record(ctx, models.AuditEventPasskeyRemoved, userID, userID, map[string]interface{}{"passkey_id": id})
+
+	return nil
+}
+
+// loadUser builds the WebAuthn view of a user with their handle and credentials
+func (s *PasskeyService) loadUser(ctx context.Context, userID int64) (*passkeyUser, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	handle, err := s.credentialRepo.GetUserHandle(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user handle: %w", err)
+	}
+
+	credentials, err := s.credentialRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	return &passkeyUser{User: user, handle: handle, credentials: credentials}, nil
+}
+
+// discoverUser resolves the user handle returned by a discoverable credential
+func (s *PasskeyService) discoverUser(ctx context.Context) webauthn.DiscoverableUserHandler {
+	return func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := s.credentialRepo.GetUserIDByHandle(ctx, userHandle)
+		if err != nil {
+			return nil, err
+		}
+		return s.loadUser(ctx, userID)
+	}
+}
+
+// saveCeremony stores the challenge state and returns the ceremony ID for the client
+func (s *PasskeyService) saveCeremony(ctx context.Context, userID *int64, kind string, session *webauthn.SessionData) (string, error) {
+	id, err := jwt.NewTokenID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ceremony id: %w", err)
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ceremony: %w", err)
+	}
+
+	ceremony := &models.WebAuthnCeremony{
+		ID:          id,
+		UserID:      userID,
+		Kind:        kind,
+		SessionData: data,
+		ExpiresAt:   time.Now().Add(s.ceremonyTTL),
+	}
+
+	if err := s.credentialRepo.SaveCeremony(ctx, ceremony); err != nil {
+		return "", fmt.Errorf("failed to save ceremony: %w", err)
+	}
+
+	return id, nil
+}
+
+// consumeCeremony loads and removes a ceremony. If userID is set the
+// ceremony must have been started by that user.
+func (s *PasskeyService) consumeCeremony(ctx context.Context, id string, kind string, userID *int64) (*webauthn.SessionData, error) {
+	ceremony, err := s.credentialRepo.ConsumeCeremony(ctx, id, kind)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired ceremony")
+	}
+
+	if userID != nil && (ceremony.UserID == nil || *ceremony.UserID != *userID) {
+		return nil, fmt.Errorf("invalid or expired ceremony")
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(ceremony.SessionData, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode ceremony: %w", err)
+	}
+
+	return &session, nil
+}
+
+// passkeyUser adapts a user and their passkeys to webauthn.User
+type passkeyUser struct {
+	*models.User
+	handle      []byte
+	credentials []*models.Credential
+}
+
+// WebAuthnID returns the opaque user handle
+func (u *passkeyUser) WebAuthnID() []byte {
+	return u.handle
+}
+
+// WebAuthnName returns the account name shown by the authenticator
+func (u *passkeyUser) WebAuthnName() string {
+	return accountName(u.User)
+}
+
+// WebAuthnDisplayName returns the display name shown by the authenticator
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.Username
+}
+
+// WebAuthnCredentials returns the user's passkeys in library form
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.credentials))
+	for i, c := range u.credentials {
+		transports := make([]protocol.AuthenticatorTransport, len(c.Transports))
+		for j, transport := range c.Transports {
+			transports[j] = protocol.AuthenticatorTransport(transport)
+		}
+
+		credentials[i] = webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(c.Flags)),
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    c.AAGUID,
+				SignCount: c.SignCount,
+			},
+		}
+	}
+	return credentials
+}
+
+// credential finds a stored passkey by its credential ID
+func (u *passkeyUser) credential(credentialID []byte) *models.Credential {
+	for _, c := range u.credentials {
+		if bytes.Equal(c.CredentialID, credentialID) {
+			return c
+		}
+	}
+	return nil
+}
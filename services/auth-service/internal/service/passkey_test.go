@@ -0,0 +1,348 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+const (
+	testRPID   = "rhythmify.test"
+	testOrigin = "https://rhythmify.test"
+)
+
+// softAuthenticator is a passkey authenticator in software. It answers
+// registrations with "none" attestation and signs assertions with an
+// ECDSA P-256 key, the way a platform authenticator would.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("failed to generate credential id: %v", err)
+	}
+
+	return &softAuthenticator{key: key, credentialID: credentialID}
+}
+
+// register answers navigator.credentials.create with a new credential
+func (a *softAuthenticator) register(t *testing.T, options interface{}) json.RawMessage {
+	t.Helper()
+
+	creation, ok := options.(*protocol.CredentialCreation)
+	if !ok {
+		t.Fatalf("registration options are %T, want *protocol.CredentialCreation", options)
+	}
+	userHandle, ok := creation.Response.User.ID.(protocol.URLEncodedBase64)
+	if !ok {
+		t.Fatalf("user handle is %T, want protocol.URLEncodedBase64", creation.Response.User.ID)
+	}
+	a.userHandle = userHandle
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("failed to encode public key: %v", err)
+	}
+
+	// Attested credential data: AAGUID, credential ID length, credential ID, public key
+	attested := make([]byte, 16, 18+len(a.credentialID)+len(publicKey))
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	authData := a.authenticatorData(protocol.FlagAttestedCredentialData, attested)
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode attestation object: %v", err)
+	}
+
+	return a.credential(t, map[string]interface{}{
+		"clientDataJSON":    base64URL(clientData(t, protocol.CreateCeremony, creation.Response.Challenge)),
+		"attestationObject": base64URL(attestationObject),
+		"transports":        []string{"internal"},
+	})
+}
+
+// login answers navigator.credentials.get with an assertion of the credential
+func (a *softAuthenticator) login(t *testing.T, options interface{}) json.RawMessage {
+	t.Helper()
+
+	assertion, ok := options.(*protocol.CredentialAssertion)
+	if !ok {
+		t.Fatalf("login options are %T, want *protocol.CredentialAssertion", options)
+	}
+
+	a.signCount++
+	authData := a.authenticatorData(0, nil)
+	clientDataJSON := clientData(t, protocol.AssertCeremony, assertion.Response.Challenge)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign assertion: %v", err)
+	}
+
+	return a.credential(t, map[string]interface{}{
+		"clientDataJSON":    base64URL(clientDataJSON),
+		"authenticatorData": base64URL(authData),
+		"signature":         base64URL(signature),
+		"userHandle":        base64URL(a.userHandle),
+	})
+}
+
+// authenticatorData builds authenticator data for the relying party with
+// user presence and verification, the given extra flags and the sign count
+func (a *softAuthenticator) authenticatorData(flags protocol.AuthenticatorFlags, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, byte(protocol.FlagUserPresent|protocol.FlagUserVerified|flags))
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+// credential wraps an authenticator response in a PublicKeyCredential
+func (a *softAuthenticator) credential(t *testing.T, response map[string]interface{}) json.RawMessage {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"id":       base64URL(a.credentialID),
+		"rawId":    base64URL(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode credential: %v", err)
+	}
+	return data
+}
+
+// clientData builds the clientDataJSON the browser passes to the authenticator
+func clientData(t *testing.T, ceremony protocol.CeremonyType, challenge protocol.URLEncodedBase64) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(protocol.CollectedClientData{
+		Type:      ceremony,
+		Challenge: challenge.String(),
+		Origin:    testOrigin,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode client data: %v", err)
+	}
+	return data
+}
+
+func base64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// passkeyTestEnv is a passkey service over in-memory repositories with one user
+type passkeyTestEnv struct {
+	service     *PasskeyService
+	tokens      *TokenService
+	credentials repository.CredentialRepository
+	audit       *fakeAuditRepo
+	user        *models.User
+}
+
+func newPasskeyTestEnv(t *testing.T) *passkeyTestEnv {
+	t.Helper()
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Rhythmify",
+		RPOrigins:     []string{testOrigin},
+	})
+	if err != nil {
+		t.Fatalf("failed to create relying party: %v", err)
+	}
+
+	env := &passkeyTestEnv{
+		credentials: repository.NewMemoryCredentialRepository(),
+		audit:       &fakeAuditRepo{},
+		user:        &models.User{ID: 1, Email: "user@example.com", Username: "user"},
+	}
+	users := newFakeUserRepo(env.user)
+	env.tokens = NewTokenService(users, newFakeSessionRepo(), repository.NewMemoryRefreshTokenRepository(), nil, &fakeRoleRepo{}, repository.NewMemoryTokenDenylist(), jwt.NewJWTManager("test-secret", 15*time.Minute, time.Hour), nil)
+	env.service = NewPasskeyService(users, env.credentials, env.tokens, webAuthn, NewAuditService(env.audit), time.Minute)
+	return env
+}
+
+// stored returns the stored state of one of the test user's passkeys
+func (env *passkeyTestEnv) stored(t *testing.T, id int64) *models.Credential {
+	t.Helper()
+
+	credentials, err := env.credentials.ListByUser(context.Background(), env.user.ID)
+	if err != nil {
+		t.Fatalf("ListByUser failed: %v", err)
+	}
+	for _, credential := range credentials {
+		if credential.ID == id {
+			return credential
+		}
+	}
+	t.Fatalf("passkey %d not stored", id)
+	return nil
+}
+
+// register adds a passkey of the authenticator to the test user
+func (env *passkeyTestEnv) register(t *testing.T, authenticator *softAuthenticator) *models.Credential {
+	t.Helper()
+	ctx := context.Background()
+
+	begin, err := env.service.BeginRegistration(ctx, env.user.ID)
+	if err != nil {
+		t.Fatalf("BeginRegistration failed: %v", err)
+	}
+
+	credential, err := env.service.FinishRegistration(ctx, env.user.ID, &models.FinishPasskeyRegistrationRequest{
+		CeremonyID: begin.CeremonyID,
+		Credential: authenticator.register(t, begin.Options),
+	})
+	if err != nil {
+		t.Fatalf("FinishRegistration failed: %v", err)
+	}
+	return credential
+}
+
+// login runs a passwordless login with the authenticator
+func (env *passkeyTestEnv) login(t *testing.T, authenticator *softAuthenticator) (*models.UserResponse, *jwt.TokenPair, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	begin, err := env.service.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+
+	return env.service.FinishLogin(ctx, &models.FinishPasskeyLoginRequest{
+		CeremonyID: begin.CeremonyID,
+		Credential: authenticator.login(t, begin.Options),
+	}, &models.ClientInfo{DeviceName: "test"})
+}
+
+func TestPasskeyRegisterAndLogin(t *testing.T) {
+	env := newPasskeyTestEnv(t)
+	authenticator := newSoftAuthenticator(t)
+
+	credential := env.register(t, authenticator)
+	if credential.Name != defaultPasskeyName {
+		t.Errorf("passkey name = %q, want %q", credential.Name, defaultPasskeyName)
+	}
+	if !bytes.Equal(credential.CredentialID, authenticator.credentialID) {
+		t.Error("stored credential ID does not match the authenticator")
+	}
+	if !env.audit.recorded(models.AuditEventPasskeyAdded, "") {
+		t.Error("passkey registration was not audited")
+	}
+
+	for i := 1; i <= 2; i++ {
+		user, tokens, err := env.login(t, authenticator)
+		if err != nil {
+			t.Fatalf("login %d failed: %v", i, err)
+		}
+		if user.ID != env.user.ID {
+			t.Errorf("login %d: user = %d, want %d", i, user.ID, env.user.ID)
+		}
+		if _, err := env.tokens.ValidateAccessToken(tokens.AccessToken); err != nil {
+			t.Errorf("login %d: access token invalid: %v", i, err)
+		}
+
+		stored := env.stored(t, credential.ID)
+		if stored.SignCount != authenticator.signCount {
+			t.Errorf("login %d: sign count = %d, want %d", i, stored.SignCount, authenticator.signCount)
+		}
+		if stored.LastUsedAt == nil {
+			t.Errorf("login %d: last use not recorded", i)
+		}
+	}
+
+	// A finished ceremony cannot be replayed
+	begin, err := env.service.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+	req := &models.FinishPasskeyLoginRequest{CeremonyID: begin.CeremonyID, Credential: authenticator.login(t, begin.Options)}
+	if _, _, err := env.service.FinishLogin(context.Background(), req, nil); err != nil {
+		t.Fatalf("FinishLogin failed: %v", err)
+	}
+	if _, _, err := env.service.FinishLogin(context.Background(), req, nil); err == nil || err.Error() != "invalid or expired ceremony" {
+		t.Errorf("replayed FinishLogin = %v, want invalid or expired ceremony", err)
+	}
+}
+
+func TestPasskeyLoginRefusesClonedAuthenticator(t *testing.T) {
+	env := newPasskeyTestEnv(t)
+	authenticator := newSoftAuthenticator(t)
+	credential := env.register(t, authenticator)
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := env.login(t, authenticator); err != nil {
+			t.Fatalf("login failed: %v", err)
+		}
+	}
+
+	// A copy of the key made after the first login lags behind the original
+	clone := *authenticator
+	clone.signCount = 1
+
+	user, tokens, err := env.login(t, &clone)
+	if err == nil || err.Error() != "invalid passkey" {
+		t.Fatalf("login with a cloned key = %v, want invalid passkey", err)
+	}
+	if user != nil || tokens != nil {
+		t.Error("login with a cloned key returned tokens")
+	}
+
+	stored := env.stored(t, credential.ID)
+	if !stored.CloneWarning {
+		t.Error("clone warning not stored")
+	}
+	if stored.SignCount != authenticator.signCount {
+		t.Errorf("sign count = %d, want %d kept from the original", stored.SignCount, authenticator.signCount)
+	}
+	if !env.audit.recorded(models.AuditEventLoginFailed, "cloned_passkey") {
+		t.Error("refused login was not audited")
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"rhythmify/services/auth-service/internal/mailer"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/passwords"
+	"rhythmify/shared/jwt"
+)
+
+// WeakPasswordError is returned when a new password violates the password policy
+type WeakPasswordError struct {
+	Violations []passwords.Violation
+}
+
+// Error implements the error interface
+func (e *WeakPasswordError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = strings.ToLower(violation.Message[:1]) + violation.Message[1:]
+	}
+	return "weak password: " + strings.Join(messages, "; ")
+}
+
+// ForgotPassword emails a password reset link if an account with the email
+// exists. The lookup and delivery run in the background so the caller sees
+// the same result and timing whether or not the email is registered.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) {
+	// Keep the request information for the audit log, not the cancellation
+	requestCtx := context.WithoutCancel(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(requestCtx, 30*time.Second)
+		defer cancel()
+
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			// Unknown email: nothing to send
+			return
+		}
+		s.auditService.Record(ctx, models.AuditEventPasswordResetRequested, 0, user.ID, nil)
+
+		if err := s.sendPasswordResetEmail(ctx, user); err != nil {
+			log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}()
+}
+
+// ResetPassword sets a new password using a reset token and ends every session of the user
+func (s *AuthService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	claims, err := s.tokenService.CheckOneTimeToken(ctx, req.Token, jwt.PasswordResetToken)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid or expired token")
+	}
+
+	// The address may have changed since the token was sent
+	if user.Email != claims.Email {
+		return fmt.Errorf("invalid or expired token")
+	}
+
+	// A password the policy rejects must not use up the link, so the user can
+	// pick another one
+	if err := s.validateNewPassword(req.NewPassword, user.Email, user.Username); err != nil {
+		return err
+	}
+
+	// Consuming the token makes the reset single-use even under concurrent requests
+	if _, err := s.tokenService.ConsumeOneTimeToken(ctx, req.Token, jwt.PasswordResetToken); err != nil {
+		return err
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password must not stay logged in
+	if err := s.tokenService.RevokeAllForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventPasswordReset, user.ID, user.ID, nil)
+
+	return nil
+}
+
+// ChangePassword changes the password of the current user. Every other session
+// is ended and the current one receives a new token pair.
+func (s *AuthService) ChangePassword(ctx context.Context, current *jwt.Claims, req *models.ChangePasswordRequest) (*jwt.TokenPair, error) {
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	valid, _, err := checkPassword(ctx, s.hasher, user, req.CurrentPassword)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, fmt.Errorf("current password is incorrect")
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return nil, fmt.Errorf("new password must differ from the current password")
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenService.EndOtherSessions(ctx, user.ID, current.SessionID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	tokens, err := s.tokenService.RenewSession(ctx, user, current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventPasswordChanged, user.ID, user.ID, nil)
+
+	return tokens, nil
+}
+
+// SetPassword adds a password to an account created without one, such as
+// an account created through Telegram
+func (s *AuthService) SetPassword(ctx context.Context, userID int64, req *models.SetPasswordRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if user.HasPassword() {
+		return fmt.Errorf("password already set")
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEventPasswordSet, userID, userID, nil)
+
+	return nil
+}
+
+// ChangeExpiredPassword changes the password of an account flagged with
+// must_change_password and logs it in, since such accounts cannot obtain
+// tokens through Login. Other accounts must log in and use ChangePassword.
+func (s *AuthService) ChangeExpiredPassword(ctx context.Context, req *models.ChangeExpiredPasswordRequest, client *models.ClientInfo) (*LoginResult, error) {
+	user, err := s.authenticate(ctx, req.Email, req.CurrentPassword, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// The password is changed before the second factor is asked for, so this
+	// must not become a way around Login for accounts that are not flagged
+	if !user.MustChangePassword {
+		s.loginGuard.RecordFailure(ctx, req.Email, client)
+		s.recordLoginFailure(ctx, user.ID, req.Email, "password_change_not_required")
+		return nil, fmt.Errorf("password change not required")
+	}
+
+	// Reject the new password before anything is changed, so the user can
+	// simply try again with another one
+	if req.NewPassword == req.CurrentPassword {
+		return nil, fmt.Errorf("new password must differ from the current password")
+	}
+	if err := s.validateNewPassword(req.NewPassword, user.Email, user.Username); err != nil {
+		return nil, err
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenService.RevokeAllForUser(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.auditService.Record(ctx, models.AuditEventPasswordChanged, user.ID, user.ID, map[string]interface{}{"expired": true})
+
+	user.MustChangePassword = false
+	return s.completeLogin(ctx, user, client)
+}
+
+// RequirePasswordChange flags an account so Login refuses to issue tokens
+// until the password has been changed, and ends its sessions
+func (s *AuthService) RequirePasswordChange(ctx context.Context, userID int64) error {
+	if err := s.userRepo.SetMustChangePassword(ctx, userID, true); err != nil {
+		return fmt.Errorf("failed to flag user: %w", err)
+	}
+
+	if err := s.tokenService.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventPasswordChangeRequired, 0, userID, nil)
+
+	return nil
+}
+
+// setPassword applies the password policy, stores the new hash and
+// invalidates outstanding reset links
+func (s *AuthService) setPassword(ctx context.Context, user *models.User, password string) error {
+	if err := s.validateNewPassword(password, user.Email, user.Username); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(ctx, password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = passwordHash
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, user.Password); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.tokenService.InvalidateOneTimeTokens(ctx, user.ID, jwt.PasswordResetToken); err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	return nil
+}
+
+// rehashPasswordAsync replaces the user's password hash with one made with
+// the current parameters in the background
+func (s *AuthService) rehashPasswordAsync(user *models.User, password string) {
+	userID, oldHash := user.ID, user.Password
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		newHash, err := s.hasher.Hash(ctx, password)
+		if err != nil {
+			log.Printf("Failed to rehash password of user %d: %v", userID, err)
+			return
+		}
+
+		if err := s.userRepo.RehashPassword(ctx, userID, oldHash, newHash); err != nil {
+			log.Printf("Failed to rehash password of user %d: %v", userID, err)
+		}
+	}()
+}
+
+// checkPassword verifies a password against the user's hash. Accounts
+// without a password never match.
+func checkPassword(ctx context.Context, hasher passwords.PasswordHasher, user *models.User, password string) (bool, bool, error) {
+	if !user.HasPassword() {
+		return false, false, nil
+	}
+
+	valid, needsRehash, err := hasher.Verify(ctx, password, user.Password)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to verify password: %w", err)
+	}
+
+	return valid, needsRehash, nil
+}
+
+// validateNewPassword applies the password policy to a new password of the
+// account with the given email and username
+func (s *AuthService) validateNewPassword(password string, email string, username string) error {
+	if violations := s.settings.PasswordPolicy.Check(password, email, username); len(violations) > 0 {
+		return &WeakPasswordError{Violations: violations}
+	}
+
+	return nil
+}
+
+// sendPasswordResetEmail issues a reset token and emails the link to the user
+func (s *AuthService) sendPasswordResetEmail(ctx context.Context, user *models.User) error {
+	token, err := s.tokenService.IssueOneTimeToken(ctx, user, jwt.PasswordResetToken, s.settings.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.settings.PublicURL, url.QueryEscape(token))
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Rhythmify password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link expires in %s and can be used once. If you did not ask for a reset, you can ignore this email.\n",
+			user.Username, link, s.settings.PasswordResetTTL),
+	}
+
+	return s.mailer.Send(ctx, msg)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/passwords"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+func TestResetPasswordKeepsLinkForRejectedPassword(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: 1, Email: "user@example.com", Username: "user", Password: "hashed:old password"}
+	users := newFakeUserRepo(user)
+	tokens := NewTokenService(users, newFakeSessionRepo(), repository.NewMemoryRefreshTokenRepository(), newFakeOneTimeTokenRepo(), &fakeRoleRepo{}, repository.NewMemoryTokenDenylist(), jwt.NewJWTManager("test-secret", 15*time.Minute, time.Hour), nil)
+	authService := NewAuthService(users, tokens, nil, nil, fakeHasher{}, nil, NewAuditService(&fakeAuditRepo{}), AccountSettings{
+		PasswordPolicy: &passwords.Policy{MinLength: 12},
+	})
+
+	link, err := tokens.IssueOneTimeToken(ctx, user, jwt.PasswordResetToken, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueOneTimeToken failed: %v", err)
+	}
+
+	err = authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: link, NewPassword: "short"})
+	var weak *WeakPasswordError
+	if !errors.As(err, &weak) {
+		t.Fatalf("ResetPassword with a short password = %v, want *WeakPasswordError", err)
+	}
+
+	// The same link still works with a password the policy accepts
+	if err := authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: link, NewPassword: "a much longer password"}); err != nil {
+		t.Fatalf("ResetPassword after a rejected password = %v, want success", err)
+	}
+	stored, _ := users.GetByID(ctx, user.ID)
+	if stored.Password != "hashed:a much longer password" {
+		t.Error("password was not changed")
+	}
+
+	// and only once
+	err = authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: link, NewPassword: "yet another long password"})
+	if err == nil || err.Error() != "invalid or expired token" {
+		t.Errorf("reused link = %v, want invalid or expired token", err)
+	}
+}
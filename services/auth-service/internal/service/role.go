@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+)
+
+// RoleService manages role assignments and resolves the permissions behind
+// the roles claim. Role definitions are cached in memory so that permission
+// checks never hit the database.
+type RoleService struct {
+	roleRepo   repository.RoleRepository
+	userRepo   repository.UserRepository
+	actionRepo repository.AdminActionRepository
+
+	mu          sync.RWMutex
+	permissions map[string]map[string]bool
+}
+
+// NewRoleService creates a new role service. Call Refresh before serving
+// requests to load the role definitions.
+func NewRoleService(roleRepo repository.RoleRepository, userRepo repository.UserRepository, actionRepo repository.AdminActionRepository) *RoleService {
+	return &RoleService{
+		roleRepo:    roleRepo,
+		userRepo:    userRepo,
+		actionRepo:  actionRepo,
+		permissions: map[string]map[string]bool{},
+	}
+}
+
+// Refresh reloads the permissions of every role
+func (s *RoleService) Refresh(ctx context.Context) error {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	permissions := make(map[string]map[string]bool, len(roles))
+	for _, role := range roles {
+		granted := make(map[string]bool, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			granted[permission] = true
+		}
+		permissions[role.Name] = granted
+	}
+
+	s.mu.Lock()
+	s.permissions = permissions
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RunRoleRefresh reloads role definitions every interval until ctx is done,
+// picking up permission changes made by other instances
+func (s *RoleService) RunRoleRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := s.Refresh(ctx); err != nil {
+			log.Printf("Role refresh failed: %v", err)
+		}
+	}
+}
+
+// HasPermission checks if any of the roles grants the permission
+func (s *RoleService) HasPermission(roles []string, permission string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, role := range roles {
+		if s.permissions[role][permission] {
+			return true
+		}
+	}
+	return false
+}
+
+// ListRoles returns every role with its permissions
+func (s *RoleService) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// GetUserRoles returns the roles granted to a user
+func (s *RoleService) GetUserRoles(ctx context.Context, userID int64) (*models.UserRolesResponse, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	roles, err := s.roleRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+
+	return &models.UserRolesResponse{UserID: userID, Roles: roles}, nil
+}
+
+// AssignRole grants a role to a user on behalf of an admin. The user's
+// tokens carry the role from their next refresh.
+func (s *RoleService) AssignRole(ctx context.Context, actorID int64, userID int64, role string) (*models.UserRolesResponse, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	if err := s.roleRepo.Assign(ctx, userID, role, &actorID); err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			return nil, fmt.Errorf("role not found")
+		}
+		return nil, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	recordAdminAction(ctx, s.actionRepo, actorID, userID, models.AdminActionAssignRole, map[string]interface{}{"role": role})
+
+	return s.GetUserRoles(ctx, userID)
+}
+
+// RevokeRole removes a role from a user on behalf of an admin. Tokens
+// already issued keep the role until they expire or are refreshed.
+func (s *RoleService) RevokeRole(ctx context.Context, actorID int64, userID int64, role string) error {
+	// Keep admins from locking themselves out
+	if actorID == userID && role == models.RoleAdmin {
+		return fmt.Errorf("cannot remove own admin role")
+	}
+
+	if err := s.roleRepo.Revoke(ctx, userID, role); err != nil {
+		if strings.Contains(err.Error(), "not granted") {
+			return fmt.Errorf("role not granted")
+		}
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	recordAdminAction(ctx, s.actionRepo, actorID, userID, models.AdminActionRevokeRole, map[string]interface{}{"role": role})
+
+	return nil
+}
+
+// BootstrapAdmins grants the admin role to the accounts with the given
+// email addresses, so that a fresh deployment has someone to assign roles
+func (s *RoleService) BootstrapAdmins(ctx context.Context, emails []string) error {
+	for _, email := range emails {
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			log.Printf("Warning: admin %s has no account yet", email)
+			continue
+		}
+
+		if err := s.roleRepo.Assign(ctx, user.ID, models.RoleAdmin, nil); err != nil {
+			return fmt.Errorf("failed to grant admin role to %s: %w", email, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/services/auth-service/internal/telegram"
+)
+
+// TelegramSettings holds the Telegram bot configuration
+type TelegramSettings struct {
+	BotToken    string
+	BotUsername string
+
+	// AuthMaxAge is how old a signed Telegram login payload may be
+	AuthMaxAge time.Duration
+
+	// LinkCodeTTL is how long a bot deep-link code stays valid, and
+	// LinkCodesPerHour caps how many a user may request
+	LinkCodeTTL      time.Duration
+	LinkCodesPerHour int
+}
+
+// TelegramService links Telegram accounts after verifying that the user owns them
+type TelegramService struct {
+	userRepo         repository.UserRepository
+	oneTimeTokenRepo repository.OneTimeTokenRepository
+	mfaService       *MFAService
+	auditService     *AuditService
+	settings         TelegramSettings
+}
+
+// NewTelegramService creates a new Telegram service
+func NewTelegramService(userRepo repository.UserRepository, oneTimeTokenRepo repository.OneTimeTokenRepository, mfaService *MFAService, auditService *AuditService, settings TelegramSettings) *TelegramService {
+	return &TelegramService{
+		userRepo:         userRepo,
+		oneTimeTokenRepo: oneTimeTokenRepo,
+		mfaService:       mfaService,
+		auditService:     auditService,
+		settings:         settings,
+	}
+}
+
+// LoginWithWebApp logs in the Telegram user of a Mini App, creating an
+// account without email or password on first use. The boolean result
+// reports whether the account was created.
+func (s *TelegramService) LoginWithWebApp(ctx context.Context, req *models.TelegramWebAppLoginRequest, client *models.ClientInfo) (*LoginResult, bool, error) {
+	if s.settings.BotToken == "" {
+		return nil, false, fmt.Errorf("telegram login not configured")
+	}
+
+	data, err := telegram.ParseWebAppInitData(req.InitData, s.settings.BotToken, s.settings.AuthMaxAge, time.Now())
+	if errors.Is(err, telegram.ErrExpired) {
+		return nil, false, fmt.Errorf("telegram login data expired")
+	}
+	if err != nil || data.User.IsBot {
+		return nil, false, fmt.Errorf("invalid telegram login data")
+	}
+
+	created := false
+	user, err := s.userRepo.GetByTelegramID(ctx, data.User.ID)
+	if err != nil {
+		user, err = s.createTelegramUser(ctx, &data.User)
+		if err != nil {
+			return nil, false, err
+		}
+		created = true
+		s.auditService.Record(ctx, models.AuditEventUserRegistered, user.ID, user.ID, map[string]interface{}{"method": models.LoginMethodTelegram})
+	}
+
+	result, err := s.mfaService.StartLogin(ctx, user, client)
+	if err != nil {
+		return nil, false, err
+	}
+
+	eventType := models.AuditEventLoginSucceeded
+	if result.MFARequired() {
+		eventType = models.AuditEventLoginMFARequired
+	}
+	s.auditService.Record(ctx, eventType, user.ID, user.ID, map[string]interface{}{"method": models.LoginMethodTelegram})
+
+	return result, created, nil
+}
+
+// LinkTelegram links a Telegram account to a user after checking the Login
+// Widget signature
+func (s *TelegramService) LinkTelegram(ctx context.Context, userID int64, req *models.LinkTelegramRequest) error {
+	if s.settings.BotToken == "" {
+		return fmt.Errorf("telegram login not configured")
+	}
+
+	err := telegram.CheckLoginWidget(req.SignedFields(), s.settings.BotToken, s.settings.AuthMaxAge, time.Now())
+	if errors.Is(err, telegram.ErrExpired) {
+		return fmt.Errorf("telegram login data expired")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid telegram login data")
+	}
+
+	return s.link(ctx, userID, req.ID)
+}
+
+// IssueLinkCode creates a short-lived code the user passes to the bot
+// through a t.me deep link. Only the newest code of a user is valid.
+func (s *TelegramService) IssueLinkCode(ctx context.Context, userID int64) (*models.TelegramLinkCodeResponse, error) {
+	if s.settings.BotUsername == "" {
+		return nil, fmt.Errorf("telegram bot not configured")
+	}
+
+	issued, err := s.oneTimeTokenRepo.CountCreatedSince(ctx, userID, models.TelegramLinkCodePurpose, time.Now().Add(-time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check link codes: %w", err)
+	}
+	if issued >= s.settings.LinkCodesPerHour {
+		return nil, fmt.Errorf("too many link codes requested")
+	}
+
+	code, err := telegram.NewLinkCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate link code: %w", err)
+	}
+
+	if err := s.oneTimeTokenRepo.InvalidateForUser(ctx, userID, models.TelegramLinkCodePurpose); err != nil {
+		return nil, fmt.Errorf("failed to invalidate link codes: %w", err)
+	}
+
+	// Only the hash is stored, so a leaked table cannot be used to link accounts
+	token := &models.OneTimeToken{
+		ID:        telegram.HashLinkCode(code),
+		UserID:    userID,
+		Purpose:   models.TelegramLinkCodePurpose,
+		ExpiresAt: time.Now().Add(s.settings.LinkCodeTTL),
+	}
+	if err := s.oneTimeTokenRepo.Create(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to store link code: %w", err)
+	}
+
+	return &models.TelegramLinkCodeResponse{
+		Code:      code,
+		URL:       telegram.DeepLink(s.settings.BotUsername, code),
+		ExpiresAt: token.ExpiresAt,
+	}, nil
+}
+
+// RedeemLinkCode links the sender of a "/start <code>" bot message to the
+// user the code was issued to
+func (s *TelegramService) RedeemLinkCode(ctx context.Context, update *models.TelegramUpdate) (*models.UserResponse, error) {
+	message := update.Message
+	if message == nil || message.From == nil || message.From.IsBot {
+		return nil, fmt.Errorf("not a link command")
+	}
+
+	code, ok := telegram.StartPayload(message.Text)
+	if !ok {
+		return nil, fmt.Errorf("not a link command")
+	}
+
+	token, err := s.oneTimeTokenRepo.Consume(ctx, telegram.HashLinkCode(code), models.TelegramLinkCodePurpose)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired link code")
+	}
+
+	if err := s.link(ctx, token.UserID, message.From.ID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return user.ToResponse(), nil
+}
+
+// UnlinkTelegram removes the Telegram account from a user
+func (s *TelegramService) UnlinkTelegram(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if user.TelegramID == nil {
+		return fmt.Errorf("telegram account not linked")
+	}
+
+	// Accounts created through Telegram would be locked out without it
+	if user.Email == "" || !user.HasPassword() {
+		return fmt.Errorf("add an email and password before unlinking telegram")
+	}
+
+	if err := s.userRepo.UnlinkTelegram(ctx, userID); err != nil {
+		return fmt.Errorf("failed to unlink telegram: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventTelegramUnlinked, userID, userID, map[string]interface{}{"telegram_id": *user.TelegramID})
+
+	return nil
+}
+
+// link stores a verified Telegram ID on the user
+func (s *TelegramService) link(ctx context.Context, userID int64, telegramID int64) error {
+	// Check if Telegram ID is already linked to another user
+	existingUser, err := s.userRepo.GetByTelegramID(ctx, telegramID)
+	if err == nil && existingUser.ID != userID {
+		return fmt.Errorf("telegram account already linked to another user")
+	}
+
+	// Link Telegram ID to user
+	if err := s.userRepo.LinkTelegram(ctx, userID, telegramID); err != nil {
+		return fmt.Errorf("failed to link telegram: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventTelegramLinked, userID, userID, map[string]interface{}{"telegram_id": telegramID})
+
+	return nil
+}
+
+// createTelegramUser creates an account for a Telegram user. A concurrent
+// login may have created it first, in which case that account is returned.
+func (s *TelegramService) createTelegramUser(ctx context.Context, telegramUser *telegram.WebAppUser) (*models.User, error) {
+	username, err := s.availableUsername(ctx, telegramUser)
+	if err != nil {
+		return nil, err
+	}
+
+	telegramID := telegramUser.ID
+	user := &models.User{
+		Username:   username,
+		TelegramID: &telegramID,
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		if existing, lookupErr := s.userRepo.GetByTelegramID(ctx, telegramID); lookupErr == nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// availableUsername picks a free username, preferring the Telegram username
+func (s *TelegramService) availableUsername(ctx context.Context, telegramUser *telegram.WebAppUser) (string, error) {
+	id := strconv.FormatInt(telegramUser.ID, 10)
+
+	candidates := []string{"tg_" + id}
+	if len(telegramUser.Username) >= 3 {
+		candidates = []string{telegramUser.Username, telegramUser.Username + "_" + id, "tg_" + id}
+	}
+
+	for _, candidate := range candidates {
+		exists, err := s.userRepo.CheckUsernameExists(ctx, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check username: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("username already exists")
+}
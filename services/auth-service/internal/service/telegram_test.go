@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+)
+
+// botUpdate is a Bot API update as the bot forwards it, with a message from
+// the given Telegram user
+func botUpdate(t *testing.T, fromID int64, isBot bool, text string) *models.TelegramUpdate {
+	t.Helper()
+
+	payload := fmt.Sprintf(`{
+		"update_id": 10000,
+		"message": {
+			"message_id": 1365,
+			"from": {"id": %d, "is_bot": %t, "first_name": "Ada", "username": "ada"},
+			"chat": {"id": %d, "type": "private"},
+			"date": 1700000000,
+			"text": %q
+		}
+	}`, fromID, isBot, fromID, text)
+
+	var update models.TelegramUpdate
+	if err := json.Unmarshal([]byte(payload), &update); err != nil {
+		t.Fatalf("failed to decode update: %v", err)
+	}
+	return &update
+}
+
+func TestTelegramRedeemLinkCode(t *testing.T) {
+	const telegramID = 424242
+
+	tests := []struct {
+		name string
+
+		// update builds the forwarded update from the issued code
+		update func(t *testing.T, code string) *models.TelegramUpdate
+
+		// linkedTo is the ID of a user the Telegram account is already linked to
+		linkedTo int64
+
+		wantErr string
+	}{
+		{
+			name: "start command",
+			update: func(t *testing.T, code string) *models.TelegramUpdate {
+				return botUpdate(t, telegramID, false, "/start "+code)
+			},
+		},
+		{
+			name: "message without a code",
+			update: func(t *testing.T, code string) *models.TelegramUpdate {
+				return botUpdate(t, telegramID, false, "/start")
+			},
+			wantErr: "not a link command",
+		},
+		{
+			name: "message from a bot",
+			update: func(t *testing.T, code string) *models.TelegramUpdate {
+				return botUpdate(t, telegramID, true, "/start "+code)
+			},
+			wantErr: "not a link command",
+		},
+		{
+			name: "update without a message",
+			update: func(t *testing.T, code string) *models.TelegramUpdate {
+				return &models.TelegramUpdate{UpdateID: 10000}
+			},
+			wantErr: "not a link command",
+		},
+		{
+			name: "unknown code",
+			update: func(t *testing.T, code string) *models.TelegramUpdate {
+				return botUpdate(t, telegramID, false, "/start "+code+"x")
+			},
+			wantErr: "invalid or expired link code",
+		},
+		{
+			name: "telegram account of another user",
+			update: func(t *testing.T, code string) *models.TelegramUpdate {
+				return botUpdate(t, telegramID, false, "/start "+code)
+			},
+			linkedTo: 2,
+			wantErr:  "telegram account already linked to another user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			user := &models.User{ID: 1, Email: "user@example.com", Username: "user"}
+			users := newFakeUserRepo(user)
+			if tt.linkedTo != 0 {
+				linked := int64(telegramID)
+				users.add(&models.User{ID: tt.linkedTo, Username: "other", TelegramID: &linked})
+			}
+			audit := &fakeAuditRepo{}
+			telegramService := NewTelegramService(users, newFakeOneTimeTokenRepo(), nil, NewAuditService(audit), TelegramSettings{
+				BotUsername:      "rhythmify_bot",
+				LinkCodeTTL:      10 * time.Minute,
+				LinkCodesPerHour: 5,
+			})
+
+			issued, err := telegramService.IssueLinkCode(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("IssueLinkCode failed: %v", err)
+			}
+
+			linked, err := telegramService.RedeemLinkCode(ctx, tt.update(t, issued.Code))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("RedeemLinkCode error = %v, want %q", err, tt.wantErr)
+				}
+				if stored, _ := users.GetByID(ctx, user.ID); stored.TelegramID != nil {
+					t.Error("telegram account linked although redemption failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RedeemLinkCode failed: %v", err)
+			}
+
+			if linked.ID != user.ID {
+				t.Errorf("linked user = %d, want %d", linked.ID, user.ID)
+			}
+			stored, _ := users.GetByID(ctx, user.ID)
+			if stored.TelegramID == nil || *stored.TelegramID != telegramID {
+				t.Errorf("telegram id = %v, want %d", stored.TelegramID, telegramID)
+			}
+			if !audit.recorded(models.AuditEventTelegramLinked, "") {
+				t.Error("link was not audited")
+			}
+
+			// A code links one account only once
+			if _, err := telegramService.RedeemLinkCode(ctx, tt.update(t, issued.Code)); err == nil || err.Error() != "invalid or expired link code" {
+				t.Errorf("second redemption = %v, want invalid or expired link code", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+// ErrSessionNotFound is returned for sessions that do not exist or belong to
+// another user
+var ErrSessionNotFound = errors.New("session not found")
+
+// TokenService issues JWT token pairs and keeps track of sessions and
+// their refresh tokens
+type TokenService struct {
+	userRepo         repository.UserRepository
+	sessionRepo      repository.SessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	oneTimeTokenRepo repository.OneTimeTokenRepository
+	roleRepo         repository.RoleRepository
+	denylist         repository.TokenDenylist
+	jwtManager       *jwt.JWTManager
+	audience         []string
+}
+
+// NewTokenService creates a new token service
+func NewTokenService(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	oneTimeTokenRepo repository.OneTimeTokenRepository,
+	roleRepo repository.RoleRepository,
+	denylist repository.TokenDenylist,
+	jwtManager *jwt.JWTManager,
+	audience []string,
+) *TokenService {
+	return &TokenService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		oneTimeTokenRepo: oneTimeTokenRepo,
+		roleRepo:         roleRepo,
+		denylist:         denylist,
+		jwtManager:       jwtManager,
+		audience:         audience,
+	}
+}
+
+// StartSession creates a new session for the client and issues its first token pair
+func (s *TokenService) StartSession(ctx context.Context, user *models.User, client *models.ClientInfo) (*jwt.TokenPair, error) {
+	if user.IsSuspended() {
+		return nil, &AccountSuspendedError{}
+	}
+
+	sessionID, err := jwt.NewTokenID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	tokens, err := s.newTokenPair(ctx, user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A session lives as long as its newest refresh token
+	session := &models.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		ExpiresAt: tokens.RefreshExpiresAt,
+	}
+	if client != nil {
+		session.DeviceName = client.DeviceName
+		session.UserAgent = client.UserAgent
+		session.IPAddress = client.IPAddress
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.Create(ctx, refreshTokenRecord(user.ID, tokens)); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Rotate exchanges a refresh token for a new token pair in the same session.
+// The presented token is marked as used; presenting it again ends the
+// whole session.
+func (s *TokenService) Rotate(ctx context.Context, refreshToken string) (*jwt.TokenPair, error) {
+	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.refreshTokenRepo.GetByID(ctx, claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token not recognized")
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, fmt.Errorf("refresh token revoked")
+	}
+
+	if stored.UsedAt != nil {
+		s.handleReuse(ctx, stored)
+		return nil, fmt.Errorf("refresh token reuse detected")
+	}
+
+	// Reload the user so the new tokens carry current profile data
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if user.IsSuspended() {
+		return nil, &AccountSuspendedError{}
+	}
+
+	tokens, err := s.newTokenPair(ctx, user, stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse to refresh a session that has been deleted, and keep it alive
+	// as long as the new refresh token
+	if err := s.touchSession(ctx, stored.SessionID, tokens.RefreshExpiresAt); err != nil {
+		return nil, err
+	}
+
+	// Store the new token before spending the old one, so a failed insert
+	// leaves the client with a refresh token that still works. A stored
+	// token that loses the race below is never handed out.
+	if err := s.refreshTokenRepo.Create(ctx, refreshTokenRecord(user.ID, tokens)); err != nil {
+		return nil, err
+	}
+
+	// Losing the race against a concurrent rotation counts as reuse as well
+	rotated, err := s.refreshTokenRepo.MarkUsed(ctx, stored.ID, tokens.RefreshTokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		s.handleReuse(ctx, stored)
+		return nil, fmt.Errorf("refresh token reuse detected")
+	}
+
+	return tokens, nil
+}
+
+// ValidateAccessToken validates a JWT token and returns its claims
+func (s *TokenService) ValidateAccessToken(tokenString string) (*jwt.Claims, error) {
+	return s.jwtManager.ValidateToken(tokenString)
+}
+
+// JWKS returns the public keys that verify issued tokens
+func (s *TokenService) JWKS() *jwt.JWKSet {
+	return s.jwtManager.JWKS()
+}
+
+// IsRevoked checks if a validated token has been revoked before its expiry
+func (s *TokenService) IsRevoked(ctx context.Context, claims *jwt.Claims) (bool, error) {
+	if claims.ID != "" {
+		revoked, err := s.denylist.IsTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			return false, err
+		}
+		if revoked {
+			return true, nil
+		}
+	}
+
+	if claims.SessionID != "" {
+		revoked, err := s.denylist.IsSessionRevoked(ctx, claims.SessionID)
+		if err != nil {
+			return false, err
+		}
+		if revoked {
+			return true, nil
+		}
+	}
+
+	cutoff, err := s.denylist.UserTokensRevokedAt(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	if !cutoff.IsZero() && claims.IssuedAt != nil && !claims.IssuedAt.Time.After(cutoff) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Revoke revokes the access token described by claims and ends its session
+func (s *TokenService) Revoke(ctx context.Context, claims *jwt.Claims) error {
+	if claims.ExpiresAt != nil {
+		if err := s.denylist.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != "" {
+		if err := s.EndSession(ctx, claims.UserID, claims.SessionID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeAllForUser ends every session of the user and denies all access
+// tokens issued up to now
+func (s *TokenService) RevokeAllForUser(ctx context.Context, userID int64) error {
+	if err := s.EndOtherSessions(ctx, userID, ""); err != nil {
+		return err
+	}
+
+	return s.denylist.RevokeUserTokens(ctx, userID, time.Now(), s.jwtManager.AccessTokenDuration())
+}
+
+// ListSessions returns the sessions of a user, flagging the current one
+func (s *TokenService) ListSessions(ctx context.Context, userID int64, currentSessionID string) ([]*models.Session, error) {
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// EndSession deletes a session of the user and denies its outstanding access tokens
+func (s *TokenService) EndSession(ctx context.Context, userID int64, sessionID string) error {
+	if err := s.sessionRepo.DeleteForUser(ctx, sessionID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	return s.denylist.RevokeSession(ctx, sessionID, s.jwtManager.AccessTokenDuration())
+}
+
+// RenewSession replaces the tokens of an existing session: the current access
+// token and all outstanding refresh tokens of the session are revoked and a
+// new pair is issued
+func (s *TokenService) RenewSession(ctx context.Context, user *models.User, current *jwt.Claims) (*jwt.TokenPair, error) {
+	if current.SessionID == "" {
+		return nil, ErrSessionNotFound
+	}
+
+	if current.ExpiresAt != nil {
+		if err := s.denylist.RevokeToken(ctx, current.ID, current.ExpiresAt.Time); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.refreshTokenRepo.RevokeBySession(ctx, current.SessionID); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokenPair(ctx, user, current.SessionID)
+}
+
+// EndOtherSessions deletes every session of the user except exceptID (may be
+// empty) and denies their outstanding access tokens
+func (s *TokenService) EndOtherSessions(ctx context.Context, userID int64, exceptID string) error {
+	sessionIDs, err := s.sessionRepo.DeleteAllForUser(ctx, userID, exceptID)
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err := s.denylist.RevokeSession(ctx, sessionID, s.jwtManager.AccessTokenDuration()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CleanupSessions deletes expired sessions and refresh tokens and returns
+// how many of each were removed
+func (s *TokenService) CleanupSessions(ctx context.Context) (int64, int64, error) {
+	now := time.Now()
+
+	tokens, err := s.refreshTokenRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sessions, err := s.sessionRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		return 0, tokens, err
+	}
+
+	return sessions, tokens, nil
+}
+
+// RunSessionCleanup removes expired sessions and refresh tokens every
+// interval until ctx is done
+func (s *TokenService) RunSessionCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sessions, tokens, err := s.CleanupSessions(ctx)
+		if err != nil {
+			log.Printf("Session cleanup failed: %v", err)
+		} else if sessions > 0 || tokens > 0 {
+			log.Printf("Removed %d expired sessions and %d expired refresh tokens", sessions, tokens)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// touchSession records a use of a session and extends it to expiresAt. Only
+// a missing session is reported as ErrSessionNotFound; database failures are
+// returned as is.
+func (s *TokenService) touchSession(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	if err := s.sessionRepo.Touch(ctx, sessionID, expiresAt); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// issueTokenPair generates a token pair within an existing session, extends
+// the session and persists its refresh token
+func (s *TokenService) issueTokenPair(ctx context.Context, user *models.User, sessionID string) (*jwt.TokenPair, error) {
+	tokens, err := s.newTokenPair(ctx, user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.touchSession(ctx, sessionID, tokens.RefreshExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.Create(ctx, refreshTokenRecord(user.ID, tokens)); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// IssueOneTimeToken issues a single-use token of the given type for the user.
+// Outstanding tokens of the same type are invalidated.
+func (s *TokenService) IssueOneTimeToken(ctx context.Context, user *models.User, tokenType jwt.TokenType, ttl time.Duration, opts ...jwt.TokenOption) (string, error) {
+	if err := s.oneTimeTokenRepo.InvalidateForUser(ctx, user.ID, string(tokenType)); err != nil {
+		return "", err
+	}
+
+	token, claims, err := s.jwtManager.GenerateOneTimeToken(user.ID, user.Email, tokenType, ttl, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	record := &models.OneTimeToken{
+		ID:        claims.ID,
+		UserID:    user.ID,
+		Purpose:   string(tokenType),
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if err := s.oneTimeTokenRepo.Create(ctx, record); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// InvalidateOneTimeTokens invalidates every outstanding single-use token of a type for the user
+func (s *TokenService) InvalidateOneTimeTokens(ctx context.Context, userID int64, tokenType jwt.TokenType) error {
+	return s.oneTimeTokenRepo.InvalidateForUser(ctx, userID, string(tokenType))
+}
+
+// CheckOneTimeToken validates a single-use token of the given type without consuming it
+func (s *TokenService) CheckOneTimeToken(ctx context.Context, tokenString string, tokenType jwt.TokenType) (*jwt.Claims, error) {
+	claims, err := s.jwtManager.ValidateToken(tokenString)
+	if err != nil || claims.Type != tokenType {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	if _, err := s.oneTimeTokenRepo.GetActive(ctx, claims.ID, string(tokenType)); err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	return claims, nil
+}
+
+// RecordFailedAttempt counts a failed attempt against a single-use token and
+// consumes the token once maxAttempts is reached
+func (s *TokenService) RecordFailedAttempt(ctx context.Context, claims *jwt.Claims, maxAttempts int) error {
+	attempts, err := s.oneTimeTokenRepo.RecordFailedAttempt(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+
+	if attempts >= maxAttempts {
+		if _, err := s.oneTimeTokenRepo.Consume(ctx, claims.ID, string(claims.Type)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConsumeOneTimeToken validates a single-use token of the given type and marks it as used
+func (s *TokenService) ConsumeOneTimeToken(ctx context.Context, tokenString string, tokenType jwt.TokenType) (*jwt.Claims, error) {
+	claims, err := s.jwtManager.ValidateToken(tokenString)
+	if err != nil || claims.Type != tokenType {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	if _, err := s.oneTimeTokenRepo.Consume(ctx, claims.ID, string(tokenType)); err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	return claims, nil
+}
+
+// handleReuse ends the session of a replayed refresh token and logs a security event
+func (s *TokenService) handleReuse(ctx context.Context, token *models.RefreshToken) {
+	log.Printf("SECURITY: refresh token reuse detected user_id=%d session_id=%s token_id=%s; ending session",
+		token.UserID, token.SessionID, token.ID)
+
+	if err := s.EndSession(ctx, token.UserID, token.SessionID); err != nil {
+		log.Printf("SECURITY: failed to end session %s: %v", token.SessionID, err)
+	}
+}
+
+// newTokenPair generates a token pair of the user within a session
+func (s *TokenService) newTokenPair(ctx context.Context, user *models.User, sessionID string) (*jwt.TokenPair, error) {
+	opts, err := s.tokenOptions(ctx, user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Username, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// tokenOptions returns the claims options for a token pair of the user
+func (s *TokenService) tokenOptions(ctx context.Context, user *models.User, sessionID string) ([]jwt.TokenOption, error) {
+	// Roles are looked up once per issued pair so that permission checks
+	// can rely on the claims alone
+	roles, err := s.roleRepo.ListForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jwt.TokenOption{
+		jwt.WithSession(sessionID),
+		jwt.WithEmailVerified(user.IsEmailVerified()),
+		jwt.WithRoles(roles),
+		jwt.WithAudience(s.audience...),
+	}, nil
+}
+
+// refreshTokenRecord builds the persisted record for the refresh token of a pair
+func refreshTokenRecord(userID int64, tokens *jwt.TokenPair) *models.RefreshToken {
+	return &models.RefreshToken{
+		ID:        tokens.RefreshTokenID,
+		UserID:    userID,
+		SessionID: tokens.SessionID,
+		ExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/services/auth-service/internal/repository"
+	"rhythmify/shared/jwt"
+)
+
+// failingRefreshTokenRepo fails Create while failCreate is set
+type failingRefreshTokenRepo struct {
+	repository.RefreshTokenRepository
+	failCreate bool
+}
+
+func (r *failingRefreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	if r.failCreate {
+		return fmt.Errorf("failed to create refresh token: connection refused")
+	}
+	return r.RefreshTokenRepository.Create(ctx, token)
+}
+
+// tokenTestEnv is a token service over in-memory repositories with one user
+type tokenTestEnv struct {
+	service       *TokenService
+	sessions      *fakeSessionRepo
+	refreshTokens *failingRefreshTokenRepo
+	denylist      repository.TokenDenylist
+	jwtManager    *jwt.JWTManager
+	user          *models.User
+}
+
+func newTokenTestEnv() *tokenTestEnv {
+	env := &tokenTestEnv{
+		sessions:      newFakeSessionRepo(),
+		refreshTokens: &failingRefreshTokenRepo{RefreshTokenRepository: repository.NewMemoryRefreshTokenRepository()},
+		denylist:      repository.NewMemoryTokenDenylist(),
+		jwtManager:    jwt.NewJWTManager("test-secret", 15*time.Minute, time.Hour),
+		user:          &models.User{ID: 1, Email: "user@example.com", Username: "user"},
+	}
+	env.service = NewTokenService(newFakeUserRepo(env.user), env.sessions, env.refreshTokens, nil, &fakeRoleRepo{}, env.denylist, env.jwtManager, nil)
+	return env
+}
+
+func TestTokenServiceRotate(t *testing.T) {
+	tests := []struct {
+		name string
+
+		// prepare runs after the session is started and returns the refresh
+		// token to rotate
+		prepare func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string
+
+		wantErr          string
+		wantSessionEnded bool
+	}{
+		{
+			name: "valid token",
+			prepare: func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string {
+				return tokens.RefreshToken
+			},
+		},
+		{
+			name: "rotated token",
+			prepare: func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string {
+				rotated, err := env.service.Rotate(context.Background(), tokens.RefreshToken)
+				if err != nil {
+					t.Fatalf("first rotation failed: %v", err)
+				}
+				return rotated.RefreshToken
+			},
+		},
+		{
+			name: "reused token ends the session",
+			prepare: func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string {
+				if _, err := env.service.Rotate(context.Background(), tokens.RefreshToken); err != nil {
+					t.Fatalf("first rotation failed: %v", err)
+				}
+				return tokens.RefreshToken
+			},
+			wantErr:          "refresh token reuse detected",
+			wantSessionEnded: true,
+		},
+		{
+			name: "revoked token",
+			prepare: func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string {
+				if err := env.refreshTokens.RevokeBySession(context.Background(), tokens.SessionID); err != nil {
+					t.Fatalf("RevokeBySession failed: %v", err)
+				}
+				return tokens.RefreshToken
+			},
+			wantErr: "refresh token revoked",
+		},
+		{
+			name: "unknown token",
+			prepare: func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string {
+				other, err := env.jwtManager.GenerateTokenPair(env.user.ID, env.user.Email, env.user.Username, jwt.WithSession(tokens.SessionID))
+				if err != nil {
+					t.Fatalf("GenerateTokenPair failed: %v", err)
+				}
+				return other.RefreshToken
+			},
+			wantErr: "refresh token not recognized",
+		},
+		{
+			name: "access token",
+			prepare: func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string {
+				return tokens.AccessToken
+			},
+			wantErr: "not a refresh token",
+		},
+		{
+			name: "ended session",
+			prepare: func(t *testing.T, env *tokenTestEnv, tokens *jwt.TokenPair) string {
+				if err := env.service.EndSession(context.Background(), env.user.ID, tokens.SessionID); err != nil {
+					t.Fatalf("EndSession failed: %v", err)
+				}
+				return tokens.RefreshToken
+			},
+			wantErr:          "session not found",
+			wantSessionEnded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTokenTestEnv()
+
+			tokens, err := env.service.StartSession(ctx, env.user, &models.ClientInfo{DeviceName: "test"})
+			if err != nil {
+				t.Fatalf("StartSession failed: %v", err)
+			}
+			refreshToken := tt.prepare(t, env, tokens)
+
+			rotated, err := env.service.Rotate(ctx, refreshToken)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Rotate error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Rotate failed: %v", err)
+				}
+				if rotated.SessionID != tokens.SessionID {
+					t.Errorf("rotated session = %s, want %s", rotated.SessionID, tokens.SessionID)
+				}
+				stored, err := env.refreshTokens.GetByID(ctx, rotated.RefreshTokenID)
+				if err != nil {
+					t.Fatalf("new refresh token not stored: %v", err)
+				}
+				if stored.UsedAt != nil || stored.RevokedAt != nil {
+					t.Errorf("new refresh token is already spent")
+				}
+			}
+
+			if ended := !env.sessions.exists(tokens.SessionID); ended != tt.wantSessionEnded {
+				t.Errorf("session ended = %v, want %v", ended, tt.wantSessionEnded)
+			}
+			revoked, err := env.denylist.IsSessionRevoked(ctx, tokens.SessionID)
+			if err != nil {
+				t.Fatalf("IsSessionRevoked failed: %v", err)
+			}
+			if revoked != tt.wantSessionEnded {
+				t.Errorf("session revoked = %v, want %v", revoked, tt.wantSessionEnded)
+			}
+		})
+	}
+}
+
+func TestTokenServiceRotateKeepsTokenWhenStoreFails(t *testing.T) {
+	ctx := context.Background()
+	env := newTokenTestEnv()
+
+	tokens, err := env.service.StartSession(ctx, env.user, nil)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	env.refreshTokens.failCreate = true
+	if _, err := env.service.Rotate(ctx, tokens.RefreshToken); err == nil {
+		t.Fatal("Rotate succeeded although the new token could not be stored")
+	}
+
+	// The client retries with the same token once the database is back
+	env.refreshTokens.failCreate = false
+	if _, err := env.service.Rotate(ctx, tokens.RefreshToken); err != nil {
+		t.Fatalf("retry after a failed store = %v, want success", err)
+	}
+	if !env.sessions.exists(tokens.SessionID) {
+		t.Error("session ended after a failed store")
+	}
+}
+
+func TestTokenServiceSessionStoreOutage(t *testing.T) {
+	ctx := context.Background()
+	env := newTokenTestEnv()
+
+	tokens, err := env.service.StartSession(ctx, env.user, nil)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	claims, err := env.service.ValidateAccessToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+
+	outage := fmt.Errorf("failed to update session: connection refused")
+	env.sessions.err = outage
+
+	if _, err := env.service.Rotate(ctx, tokens.RefreshToken); !errors.Is(err, outage) {
+		t.Errorf("Rotate error = %v, want the database error", err)
+	}
+	if err := env.service.EndSession(ctx, env.user.ID, tokens.SessionID); !errors.Is(err, outage) {
+		t.Errorf("EndSession error = %v, want the database error", err)
+	}
+	if err := env.service.Revoke(ctx, claims); !errors.Is(err, outage) {
+		t.Errorf("Revoke error = %v, want the database error", err)
+	}
+
+	// The refresh token is still good once the database is back
+	env.sessions.err = nil
+	if _, err := env.service.Rotate(ctx, tokens.RefreshToken); err != nil {
+		t.Errorf("Rotate after the outage = %v, want success", err)
+	}
+}
+
+func TestTokenServiceRevoke(t *testing.T) {
+	ctx := context.Background()
+	env := newTokenTestEnv()
+
+	tokens, err := env.service.StartSession(ctx, env.user, nil)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	claims, err := env.service.ValidateAccessToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+
+	if err := env.service.Revoke(ctx, claims); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+
+	revoked, err := env.service.IsRevoked(ctx, claims)
+	if err != nil {
+		t.Fatalf("IsRevoked failed: %v", err)
+	}
+	if !revoked {
+		t.Error("access token still valid after Revoke")
+	}
+	if env.sessions.exists(tokens.SessionID) {
+		t.Error("session still exists after Revoke")
+	}
+	if _, err := env.service.Rotate(ctx, tokens.RefreshToken); err == nil {
+		t.Error("refresh token still rotates after Revoke")
+	}
+
+	// Revoking again after the session is gone is not an error
+	if err := env.service.Revoke(ctx, claims); err != nil {
+		t.Errorf("second Revoke = %v, want nil", err)
+	}
+}
+
+func TestTokenServiceSessionExpiry(t *testing.T) {
+	ctx := context.Background()
+	env := newTokenTestEnv()
+
+	live, err := env.service.StartSession(ctx, env.user, nil)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	// A session whose refresh token has already expired
+	expiredManager := jwt.NewJWTManager("test-secret", 15*time.Minute, -time.Minute)
+	expiredService := NewTokenService(newFakeUserRepo(env.user), env.sessions, env.refreshTokens, nil, &fakeRoleRepo{}, env.denylist, expiredManager, nil)
+	expired, err := expiredService.StartSession(ctx, env.user, nil)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	// Rotating extends the session to the new refresh token
+	rotated, err := env.service.Rotate(ctx, live.RefreshToken)
+	if err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+
+	sessions, err := env.service.ListSessions(ctx, env.user.ID, live.SessionID)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != live.SessionID {
+		t.Fatalf("ListSessions = %d sessions, want only the live one", len(sessions))
+	}
+	if !sessions[0].ExpiresAt.Equal(rotated.RefreshExpiresAt) {
+		t.Errorf("session expires at %v, want %v", sessions[0].ExpiresAt, rotated.RefreshExpiresAt)
+	}
+
+	removedSessions, removedTokens, err := env.service.CleanupSessions(ctx)
+	if err != nil {
+		t.Fatalf("CleanupSessions failed: %v", err)
+	}
+	if removedSessions != 1 || removedTokens != 1 {
+		t.Errorf("CleanupSessions removed %d sessions and %d tokens, want 1 and 1", removedSessions, removedTokens)
+	}
+	if env.sessions.exists(expired.SessionID) {
+		t.Error("expired session still exists after cleanup")
+	}
+	if !env.sessions.exists(live.SessionID) {
+		t.Error("live session removed by cleanup")
+	}
+	if _, err := env.service.Rotate(ctx, rotated.RefreshToken); err != nil {
+		t.Errorf("Rotate after cleanup = %v, want success", err)
+	}
+}
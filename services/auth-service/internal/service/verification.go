@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"rhythmify/services/auth-service/internal/mailer"
+	"rhythmify/services/auth-service/internal/models"
+	"rhythmify/shared/jwt"
+)
+
+// VerifyEmail confirms the email address a verification token was issued for
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) (*models.UserResponse, error) {
+	claims, err := s.tokenService.ConsumeOneTimeToken(ctx, token, jwt.EmailVerificationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	// The address may have changed since the token was sent
+	if user.Email != claims.Email {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, user.ID, claims.Email); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+	s.auditService.Record(ctx, models.AuditEventEmailVerified, user.ID, user.ID, map[string]interface{}{"email": claims.Email})
+
+	return user.ToResponse(), nil
+}
+
+// ResendVerificationEmail sends a fresh verification email to the user
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if user.IsEmailVerified() {
+		return fmt.Errorf("email already verified")
+	}
+
+	if user.Email == "" {
+		return fmt.Errorf("no email address set")
+	}
+
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	s.auditService.Record(ctx, models.AuditEventVerificationSent, userID, userID, map[string]interface{}{"email": user.Email})
+
+	return nil
+}
+
+// sendVerificationEmail issues a verification token and emails the link to the user
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := s.tokenService.IssueOneTimeToken(ctx, user, jwt.EmailVerificationToken, s.settings.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.settings.PublicURL, url.QueryEscape(token))
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your Rhythmify email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in %s. If you did not create a Rhythmify account, you can ignore this email.\n",
+			user.Username, link, s.settings.EmailVerificationTTL),
+	}
+
+	return s.mailer.Send(ctx, msg)
+}
+
+// sendVerificationEmailAsync sends the verification email in the background so
+// a slow mail server doesn't hold up the request
+func (s *AuthService) sendVerificationEmailAsync(user *models.User) {
+	recipient := *user
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.sendVerificationEmail(ctx, &recipient); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", recipient.ID, err)
+		}
+	}()
+}
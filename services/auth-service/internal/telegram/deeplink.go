@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// linkCodeSize is the number of random bytes in a deep-link code
+const linkCodeSize = 16
+
+// NewLinkCode generates a random code usable as a /start parameter
+func NewLinkCode() (string, error) {
+	b := make([]byte, linkCodeSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	// The URL-safe alphabet matches what Telegram allows in start parameters
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashLinkCode returns the form of a link code that is stored
+func HashLinkCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// DeepLink builds the t.me URL that opens the bot with the code as start parameter
+func DeepLink(botUsername string, code string) string {
+	return "https://t.me/" + url.PathEscape(botUsername) + "?start=" + url.QueryEscape(code)
+}
+
+// StartPayload extracts the parameter of a "/start <payload>" message. The
+// command may be addressed to the bot as "/start@botname".
+func StartPayload(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		return "", false
+	}
+
+	command := fields[0]
+	if at := strings.IndexByte(command, '@'); at >= 0 {
+		command = command[:at]
+	}
+	if command != "/start" {
+		return "", false
+	}
+
+	return fields[1], true
+}
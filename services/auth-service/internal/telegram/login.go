@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidHash is returned when the payload was not signed with the bot token
+	ErrInvalidHash = errors.New("telegram: invalid hash")
+
+	// ErrExpired is returned when auth_date is missing, in the future or older than allowed
+	ErrExpired = errors.New("telegram: auth data expired")
+)
+
+// clockSkew is how far auth_date may lie in the future
+const clockSkew = 30 * time.Second
+
+// CheckLoginWidget verifies a Telegram Login Widget payload. fields holds
+// every field Telegram sent, including hash and auth_date.
+//
+// See https://core.telegram.org/widgets/login#checking-authorization
+func CheckLoginWidget(fields map[string]string, botToken string, maxAge time.Duration, now time.Time) error {
+	secret := sha256.Sum256([]byte(botToken))
+
+	if !checkHash(fields, secret[:]) {
+		return ErrInvalidHash
+	}
+
+	return checkAuthDate(fields["auth_date"], maxAge, now)
+}
+
+// checkHash compares the hash field with the HMAC-SHA256 of the data-check-string
+func checkHash(fields map[string]string, secret []byte) bool {
+	expected, err := hex.DecodeString(fields["hash"])
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(dataCheckString(fields)))
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// dataCheckString joins every field except hash as sorted key=value lines
+func dataCheckString(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + "=" + fields[key]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkAuthDate rejects payloads signed more than maxAge ago
+func checkAuthDate(value string, maxAge time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return ErrExpired
+	}
+
+	authDate := time.Unix(unix, 0)
+	if authDate.After(now.Add(clockSkew)) || now.Sub(authDate) > maxAge {
+		return ErrExpired
+	}
+
+	return nil
+}
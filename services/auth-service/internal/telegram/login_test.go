@@ -0,0 +1,146 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:test-bot-token"
+
+// signLoginWidget adds the hash Telegram would send with the fields. It
+// follows the documented algorithm on its own rather than reusing the code
+// under test: the key is SHA-256 of the bot token and the data-check-string
+// is every other field as key=value lines in alphabetical order.
+func signLoginWidget(fields map[string]string, botToken string) map[string]string {
+	lines := []string{}
+	for key, value := range fields {
+		lines = append(lines, key+"="+value)
+	}
+	sort.Strings(lines)
+
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+
+	signed := map[string]string{"hash": hex.EncodeToString(mac.Sum(nil))}
+	for key, value := range fields {
+		signed[key] = value
+	}
+	return signed
+}
+
+func TestCheckLoginWidget(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	maxAge := time.Hour
+
+	loginFields := func(authDate time.Time) map[string]string {
+		return map[string]string{
+			"id":         "42",
+			"first_name": "Ada",
+			"username":   "ada",
+			"photo_url":  "https://t.me/i/userpic/320/ada.jpg",
+			"auth_date":  strconv.FormatInt(authDate.Unix(), 10),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		fields  func() map[string]string
+		wantErr error
+	}{
+		{
+			name: "valid payload",
+			fields: func() map[string]string {
+				return signLoginWidget(loginFields(now.Add(-time.Minute)), testBotToken)
+			},
+		},
+		{
+			name: "changed field",
+			fields: func() map[string]string {
+				fields := signLoginWidget(loginFields(now.Add(-time.Minute)), testBotToken)
+				fields["id"] = "43"
+				return fields
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "added field",
+			fields: func() map[string]string {
+				fields := signLoginWidget(loginFields(now.Add(-time.Minute)), testBotToken)
+				fields["last_name"] = "Lovelace"
+				return fields
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "other bot token",
+			fields: func() map[string]string {
+				return signLoginWidget(loginFields(now.Add(-time.Minute)), "654321:other-bot-token")
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "missing hash",
+			fields: func() map[string]string {
+				fields := signLoginWidget(loginFields(now.Add(-time.Minute)), testBotToken)
+				delete(fields, "hash")
+				return fields
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "non-hex hash",
+			fields: func() map[string]string {
+				fields := signLoginWidget(loginFields(now.Add(-time.Minute)), testBotToken)
+				fields["hash"] = "not-a-hex-hash"
+				return fields
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "stale auth_date",
+			fields: func() map[string]string {
+				return signLoginWidget(loginFields(now.Add(-maxAge-time.Second)), testBotToken)
+			},
+			wantErr: ErrExpired,
+		},
+		{
+			name: "auth_date within clock skew",
+			fields: func() map[string]string {
+				return signLoginWidget(loginFields(now.Add(clockSkew)), testBotToken)
+			},
+		},
+		{
+			name: "future auth_date",
+			fields: func() map[string]string {
+				return signLoginWidget(loginFields(now.Add(clockSkew+time.Second)), testBotToken)
+			},
+			wantErr: ErrExpired,
+		},
+		{
+			name: "missing auth_date",
+			fields: func() map[string]string {
+				fields := loginFields(now)
+				delete(fields, "auth_date")
+				return signLoginWidget(fields, testBotToken)
+			},
+			wantErr: ErrExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckLoginWidget(tt.fields(), testBotToken, maxAge, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckLoginWidget = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
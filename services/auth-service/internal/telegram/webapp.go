@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrMissingUser is returned when Mini App init data carries no user
+var ErrMissingUser = errors.New("telegram: init data has no user")
+
+// WebAppUser is the user object of Mini App init data
+type WebAppUser struct {
+	ID           int64  `json:"id"`
+	IsBot        bool   `json:"is_bot,omitempty"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name,omitempty"`
+	Username     string `json:"username,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+// WebAppData is verified Mini App init data
+type WebAppData struct {
+	User       WebAppUser
+	AuthDate   time.Time
+	QueryID    string
+	StartParam string
+}
+
+// ParseWebAppInitData verifies the raw Telegram.WebApp.initData query string
+// and returns its contents
+//
+// See https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func ParseWebAppInitData(initData string, botToken string, maxAge time.Duration, now time.Time) (*WebAppData, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, ErrInvalidHash
+	}
+
+	fields := make(map[string]string, len(values))
+	for key := range values {
+		fields[key] = values.Get(key)
+	}
+
+	// Mini Apps derive the secret with "WebAppData" as the HMAC key
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(botToken))
+
+	if !checkHash(fields, mac.Sum(nil)) {
+		return nil, ErrInvalidHash
+	}
+
+	if err := checkAuthDate(fields["auth_date"], maxAge, now); err != nil {
+		return nil, err
+	}
+
+	var user WebAppUser
+	if fields["user"] == "" || json.Unmarshal([]byte(fields["user"]), &user) != nil || user.ID == 0 {
+		return nil, ErrMissingUser
+	}
+
+	authDate, _ := strconv.ParseInt(fields["auth_date"], 10, 64)
+
+	return &WebAppData{
+		User:       user,
+		AuthDate:   time.Unix(authDate, 0),
+		QueryID:    fields["query_id"],
+		StartParam: fields["start_param"],
+	}, nil
+}
@@ -0,0 +1,172 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signWebAppInitData encodes the fields as Telegram.WebApp.initData. The
+// secret key is HMAC-SHA256 of the bot token with "WebAppData" as the key,
+// and the data-check-string is built as for the Login Widget.
+func signWebAppInitData(fields map[string]string, botToken string) string {
+	lines := []string{}
+	for key, value := range fields {
+		lines = append(lines, key+"="+value)
+	}
+	sort.Strings(lines)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+
+	values := url.Values{"hash": {hex.EncodeToString(mac.Sum(nil))}}
+	for key, value := range fields {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
+
+func TestParseWebAppInitData(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	maxAge := time.Hour
+
+	initFields := func(authDate time.Time) map[string]string {
+		return map[string]string{
+			"query_id":    "AAHdF6IQAAAAAN0XohDhrOrc",
+			"user":        `{"id":42,"first_name":"Ada","username":"ada","language_code":"en"}`,
+			"auth_date":   strconv.FormatInt(authDate.Unix(), 10),
+			"start_param": "invite",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		initData func() string
+		wantErr  error
+	}{
+		{
+			name: "valid init data",
+			initData: func() string {
+				return signWebAppInitData(initFields(now.Add(-time.Minute)), testBotToken)
+			},
+		},
+		{
+			name: "signed like the Login Widget",
+			initData: func() string {
+				// The bot token hashed with SHA-256 is the Login Widget key,
+				// which Mini Apps must not accept
+				fields := signLoginWidget(initFields(now.Add(-time.Minute)), testBotToken)
+				values := url.Values{}
+				for key, value := range fields {
+					values.Set(key, value)
+				}
+				return values.Encode()
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "other bot token",
+			initData: func() string {
+				return signWebAppInitData(initFields(now.Add(-time.Minute)), "654321:other-bot-token")
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "changed user",
+			initData: func() string {
+				values, _ := url.ParseQuery(signWebAppInitData(initFields(now.Add(-time.Minute)), testBotToken))
+				values.Set("user", `{"id":43,"first_name":"Ada"}`)
+				return values.Encode()
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "missing hash",
+			initData: func() string {
+				values, _ := url.ParseQuery(signWebAppInitData(initFields(now.Add(-time.Minute)), testBotToken))
+				values.Del("hash")
+				return values.Encode()
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "malformed query string",
+			initData: func() string {
+				return "user=%zz&hash=00"
+			},
+			wantErr: ErrInvalidHash,
+		},
+		{
+			name: "stale auth_date",
+			initData: func() string {
+				return signWebAppInitData(initFields(now.Add(-maxAge-time.Second)), testBotToken)
+			},
+			wantErr: ErrExpired,
+		},
+		{
+			name: "future auth_date",
+			initData: func() string {
+				return signWebAppInitData(initFields(now.Add(clockSkew+time.Second)), testBotToken)
+			},
+			wantErr: ErrExpired,
+		},
+		{
+			name: "missing user",
+			initData: func() string {
+				fields := initFields(now.Add(-time.Minute))
+				delete(fields, "user")
+				return signWebAppInitData(fields, testBotToken)
+			},
+			wantErr: ErrMissingUser,
+		},
+		{
+			name: "user without id",
+			initData: func() string {
+				fields := initFields(now.Add(-time.Minute))
+				fields["user"] = `{"first_name":"Ada"}`
+				return signWebAppInitData(fields, testBotToken)
+			},
+			wantErr: ErrMissingUser,
+		},
+		{
+			name: "user is not json",
+			initData: func() string {
+				fields := initFields(now.Add(-time.Minute))
+				fields["user"] = "ada"
+				return signWebAppInitData(fields, testBotToken)
+			},
+			wantErr: ErrMissingUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseWebAppInitData(tt.initData(), testBotToken, maxAge, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseWebAppInitData error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if data.User.ID != 42 || data.User.Username != "ada" || data.User.LanguageCode != "en" {
+				t.Errorf("User = %+v, want the signed user", data.User)
+			}
+			if !data.AuthDate.Equal(now.Add(-time.Minute)) {
+				t.Errorf("AuthDate = %v, want %v", data.AuthDate, now.Add(-time.Minute))
+			}
+			if data.QueryID != "AAHdF6IQAAAAAN0XohDhrOrc" || data.StartParam != "invite" {
+				t.Errorf("QueryID, StartParam = %q, %q, want the signed values", data.QueryID, data.StartParam)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+-- Allow admins to force a password change on next login
+ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
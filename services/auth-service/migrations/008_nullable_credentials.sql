@@ -0,0 +1,4 @@
+-- Accounts created through Telegram have neither an email address nor a
+-- password until the user adds them
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
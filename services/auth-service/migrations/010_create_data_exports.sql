@@ -0,0 +1,20 @@
+-- Create data_exports table (archives of a user's data built on request)
+CREATE TABLE IF NOT EXISTS data_exports (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    file_path TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    downloaded_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create index on user_id for finding a user's exports
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports(user_id);
+
+-- Allow only one export per user to be built at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_user_pending ON data_exports(user_id) WHERE status = 'pending';
+
+-- Create index on expires_at for cleanup
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports(expires_at);
@@ -0,0 +1,28 @@
+-- Create audit_events table (security-relevant activity on accounts). Events
+-- outlive the accounts they name, which are set to NULL when purged.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    target_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    event_type VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on target_user_id for the activity of an account
+CREATE INDEX IF NOT EXISTS idx_audit_events_target_user_id ON audit_events(target_user_id, id);
+
+-- Create index on actor_id for the events caused by a user or admin
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events(actor_id, id);
+
+-- Create index on event_type for filtering by kind of event
+CREATE INDEX IF NOT EXISTS idx_audit_events_event_type ON audit_events(event_type, id);
+
+-- Create index on ip_address for tracing activity from one address
+CREATE INDEX IF NOT EXISTS idx_audit_events_ip_address ON audit_events(ip_address, id);
+
+-- Create index on created_at for time range queries
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
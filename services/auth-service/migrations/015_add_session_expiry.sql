@@ -0,0 +1,17 @@
+-- A session lives as long as its newest refresh token
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+-- Backfill from the refresh tokens of existing sessions; sessions without
+-- any are already dead
+UPDATE sessions s
+SET expires_at = COALESCE(
+    (SELECT MAX(rt.expires_at) FROM refresh_tokens rt WHERE rt.session_id = s.id),
+    NOW()
+)
+WHERE s.expires_at IS NULL;
+
+ALTER TABLE sessions ALTER COLUMN expires_at SET NOT NULL;
+
+-- Indexes for the cleanup of expired sessions and refresh tokens
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
@@ -0,0 +1,3 @@
+-- Deleted accounts give up their Telegram link so it can be used by another
+-- account. The ID is kept aside to link it again when the account is restored.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_telegram_id BIGINT;
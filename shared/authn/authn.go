@@ -0,0 +1,142 @@
+// Package authn authenticates requests to Rhythmify services with tokens
+// issued by the auth service.
+//
+// A Verifier turns a bearer token into claims. Tokens can be verified
+// locally with the shared JWT secret (NewSecretVerifier), locally with the
+// keys the auth service publishes at /.well-known/jwks.json
+// (NewJWKSVerifier), or remotely with token introspection
+// (NewIntrospectionVerifier). Only introspection sees revoked tokens,
+// suspended accounts and API keys.
+//
+// The Gin and net/http middleware store the verified claims in the request
+// context, where ClaimsFromContext and UserIDFromContext find them. Checks
+// such as RequireAudience and RequireScopes can be passed to the middleware
+// or added per route.
+package authn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"rhythmify/shared/jwt"
+)
+
+// Errors returned by verifiers and checks. Any other error means the token
+// could not be verified at the moment, for example because the auth service
+// is unreachable.
+var (
+	ErrMissingToken      = errors.New("authn: missing bearer token")
+	ErrInvalidToken      = errors.New("authn: invalid or expired token")
+	ErrWrongAudience     = errors.New("authn: token is not meant for this service")
+	ErrInsufficientScope = errors.New("authn: token lacks a required scope")
+)
+
+// Verifier verifies a bearer token and returns its claims
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*jwt.Claims, error)
+}
+
+// Check inspects verified claims and returns an error if the request must be
+// rejected
+type Check func(claims *jwt.Claims) error
+
+// RequireAudience accepts tokens issued for at least one of the audiences
+func RequireAudience(audiences ...string) Check {
+	return func(claims *jwt.Claims) error {
+		for _, want := range audiences {
+			for _, aud := range claims.Audience {
+				if aud == want {
+					return nil
+				}
+			}
+		}
+		return ErrWrongAudience
+	}
+}
+
+// RequireScopes accepts tokens granting every scope. Access tokens from a
+// login are not limited by scopes; API keys are.
+func RequireScopes(scopes ...string) Check {
+	return func(claims *jwt.Claims) error {
+		for _, scope := range scopes {
+			if !claims.HasScope(scope) {
+				return ErrInsufficientScope
+			}
+		}
+		return nil
+	}
+}
+
+// contextKey is the request context key of the verified claims
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the claims
+func NewContext(ctx context.Context, claims *jwt.Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext extracts the verified claims from a request context
+func ClaimsFromContext(ctx context.Context) (*jwt.Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*jwt.Claims)
+	return claims, ok
+}
+
+// UserIDFromContext extracts the authenticated user ID from a request context
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// authenticate verifies the bearer token of an Authorization header and runs
+// the checks against its claims
+func authenticate(ctx context.Context, verifier Verifier, authHeader string, checks []Check) (*jwt.Claims, error) {
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil, ErrMissingToken
+	}
+
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runChecks(claims, checks); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// runChecks returns the error of the first failing check
+func runChecks(claims *jwt.Claims, checks []Check) error {
+	for _, check := range checks {
+		if err := check(claims); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorStatus maps an authentication error to an HTTP status, error message
+// and error code
+func errorStatus(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		return http.StatusUnauthorized, "Authorization header with a bearer token is required", "UNAUTHORIZED"
+	case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrWrongAudience):
+		return http.StatusUnauthorized, "Invalid or expired token", "UNAUTHORIZED"
+	case errors.Is(err, ErrInsufficientScope):
+		return http.StatusForbidden, "Token lacks a required scope", "INSUFFICIENT_SCOPE"
+	default:
+		return http.StatusServiceUnavailable, "Authentication is temporarily unavailable", "AUTH_UNAVAILABLE"
+	}
+}
@@ -0,0 +1,274 @@
+package authn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rhythmify/shared/jwt"
+)
+
+const testSecret = "test-secret"
+
+// verifierFunc adapts a function to a Verifier
+type verifierFunc func(ctx context.Context, token string) (*jwt.Claims, error)
+
+func (f verifierFunc) Verify(ctx context.Context, token string) (*jwt.Claims, error) {
+	return f(ctx, token)
+}
+
+// testVerifier accepts access tokens signed with testSecret, an API key
+// with the profile:read scope and reports the auth service as down for
+// the token "unavailable"
+func testVerifier() Verifier {
+	secret := NewSecretVerifier(testSecret)
+	return verifierFunc(func(ctx context.Context, token string) (*jwt.Claims, error) {
+		switch token {
+		case "rfy_key":
+			return jwt.NewAPIKeyClaims(1, "user@example.com", "user", "7", nil, jwt.WithScopes([]string{"profile:read"})), nil
+		case "unavailable":
+			return nil, errors.New("connection refused")
+		}
+		return secret.Verify(ctx, token)
+	})
+}
+
+// testTokens issues an access and a refresh token for user 1
+func testTokens(t *testing.T, opts ...jwt.TokenOption) *jwt.TokenPair {
+	t.Helper()
+
+	pair, err := jwt.NewJWTManager(testSecret, time.Minute, time.Hour).GenerateTokenPair(1, "user@example.com", "user", opts...)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	return pair
+}
+
+// middlewareTest is a request against middleware requiring the playlist
+// audience and the profile:read scope
+type middlewareTest struct {
+	name          string
+	authorization string
+	wantStatus    int
+	wantCode      string
+}
+
+func middlewareTests(t *testing.T) []middlewareTest {
+	forPlaylist := testTokens(t, jwt.WithAudience("playlist"))
+	forBilling := testTokens(t, jwt.WithAudience("billing"))
+
+	return []middlewareTest{
+		{name: "valid access token", authorization: "Bearer " + forPlaylist.AccessToken, wantStatus: http.StatusOK},
+		{name: "missing header", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "not a bearer token", authorization: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "empty bearer token", authorization: "Bearer ", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "invalid token", authorization: "Bearer not-a-token", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "refresh token", authorization: "Bearer " + forPlaylist.RefreshToken, wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "wrong audience", authorization: "Bearer " + forBilling.AccessToken, wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "api key without the audience", authorization: "Bearer rfy_key", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "auth service unavailable", authorization: "Bearer unavailable", wantStatus: http.StatusServiceUnavailable, wantCode: "AUTH_UNAVAILABLE"},
+	}
+}
+
+// scopeTests are requests against middleware requiring the profile:write scope
+func scopeTests(t *testing.T) []middlewareTest {
+	return []middlewareTest{
+		{name: "access token", authorization: "Bearer " + testTokens(t).AccessToken, wantStatus: http.StatusOK},
+		{name: "api key without the scope", authorization: "Bearer rfy_key", wantStatus: http.StatusForbidden, wantCode: "INSUFFICIENT_SCOPE"},
+	}
+}
+
+// checkResponse checks the status, error code and WWW-Authenticate header
+// of a middleware response
+func checkResponse(t *testing.T, rec *httptest.ResponseRecorder, tt middlewareTest) {
+	t.Helper()
+
+	if rec.Code != tt.wantStatus {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+	}
+	if tt.wantStatus == http.StatusOK {
+		return
+	}
+
+	var body struct {
+		Success bool   `json:"success"`
+		Code    string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode error response %q: %v", rec.Body.String(), err)
+	}
+	if body.Success || body.Code != tt.wantCode {
+		t.Errorf("error response = %s, want code %s", rec.Body.String(), tt.wantCode)
+	}
+
+	wantChallenge := ""
+	if tt.wantStatus == http.StatusUnauthorized {
+		wantChallenge = "Bearer"
+	}
+	if got := rec.Header().Get("WWW-Authenticate"); got != wantChallenge {
+		t.Errorf("WWW-Authenticate = %q, want %q", got, wantChallenge)
+	}
+}
+
+func TestGin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/playlists", Gin(testVerifier(), RequireAudience("playlist"), RequireScopes("profile:read")), func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok || userID != 1 {
+			t.Errorf("user id in context = %d, %v", userID, ok)
+		}
+		if c.GetInt64("user_id") != 1 {
+			t.Errorf("user_id key = %v, want 1", c.GetInt64("user_id"))
+		}
+		c.Status(http.StatusOK)
+	})
+	router.PUT("/profile", Gin(testVerifier()), GinRequire(RequireScopes("profile:write")), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tt := range middlewareTests(t) {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponse(t, serve(router, http.MethodGet, "/playlists", tt.authorization), tt)
+		})
+	}
+	for _, tt := range scopeTests(t) {
+		t.Run("route "+tt.name, func(t *testing.T) {
+			checkResponse(t, serve(router, http.MethodPut, "/profile", tt.authorization), tt)
+		})
+	}
+}
+
+func TestGinOptional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var authenticated bool
+	router := gin.New()
+	router.GET("/charts", GinOptional(testVerifier()), func(c *gin.Context) {
+		_, authenticated = GetClaims(c)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/library", GinOptional(testVerifier()), GinRequire(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name              string
+		authorization     string
+		wantAuthenticated bool
+	}{
+		{name: "valid token", authorization: "Bearer " + testTokens(t).AccessToken, wantAuthenticated: true},
+		{name: "anonymous"},
+		{name: "invalid token", authorization: "Bearer not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticated = false
+			rec := serve(router, http.MethodGet, "/charts", tt.authorization)
+			if rec.Code != http.StatusOK || authenticated != tt.wantAuthenticated {
+				t.Errorf("status = %d, authenticated = %v, want 200 and %v", rec.Code, authenticated, tt.wantAuthenticated)
+			}
+
+			// Routes requiring a login still reject anonymous requests
+			wantStatus := http.StatusUnauthorized
+			if tt.wantAuthenticated {
+				wantStatus = http.StatusOK
+			}
+			if rec := serve(router, http.MethodGet, "/library", tt.authorization); rec.Code != wantStatus {
+				t.Errorf("required route status = %d, want %d", rec.Code, wantStatus)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /playlists", Middleware(testVerifier(), RequireAudience("playlist"), RequireScopes("profile:read"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := UserIDFromContext(r.Context()); !ok || userID != 1 {
+			t.Errorf("user id in context = %d, %v", userID, ok)
+		}
+		w.WriteHeader(http.StatusOK)
+	})))
+	mux.Handle("PUT /profile", Middleware(testVerifier())(Require(RequireScopes("profile:write"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))))
+
+	for _, tt := range middlewareTests(t) {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponse(t, serve(mux, http.MethodGet, "/playlists", tt.authorization), tt)
+		})
+	}
+	for _, tt := range scopeTests(t) {
+		t.Run("route "+tt.name, func(t *testing.T) {
+			checkResponse(t, serve(mux, http.MethodPut, "/profile", tt.authorization), tt)
+		})
+	}
+}
+
+func TestOptionalMiddleware(t *testing.T) {
+	var authenticated bool
+	handler := OptionalMiddleware(testVerifier())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, authenticated = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	if rec := serve(handler, http.MethodGet, "/charts", "Bearer not-a-token"); rec.Code != http.StatusOK || authenticated {
+		t.Errorf("invalid token: status = %d, authenticated = %v, want 200 anonymous", rec.Code, authenticated)
+	}
+	if rec := serve(handler, http.MethodGet, "/charts", "Bearer "+testTokens(t).AccessToken); rec.Code != http.StatusOK || !authenticated {
+		t.Errorf("valid token: status = %d, authenticated = %v, want 200 authenticated", rec.Code, authenticated)
+	}
+
+	required := OptionalMiddleware(testVerifier())(Require()(handler))
+	if rec := serve(required, http.MethodGet, "/library", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous request to a required route: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestRequireAudience(t *testing.T) {
+	tests := []struct {
+		name      string
+		audience  []string
+		accepted  []string
+		wantError bool
+	}{
+		{name: "matching audience", audience: []string{"playlist"}, accepted: []string{"playlist"}},
+		{name: "one of several", audience: []string{"billing", "playlist"}, accepted: []string{"search", "playlist"}},
+		{name: "other audience", audience: []string{"billing"}, accepted: []string{"playlist"}, wantError: true},
+		{name: "no audience", accepted: []string{"playlist"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &jwt.Claims{}
+			claims.Audience = tt.audience
+
+			err := RequireAudience(tt.accepted...)(claims)
+			if (err != nil) != tt.wantError {
+				t.Errorf("RequireAudience = %v, want error %v", err, tt.wantError)
+			}
+			if err != nil && !errors.Is(err, ErrWrongAudience) {
+				t.Errorf("RequireAudience = %v, want ErrWrongAudience", err)
+			}
+		})
+	}
+}
+
+// serve sends a request with the Authorization header to the handler
+func serve(handler http.Handler, method, target, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,79 @@
+package authn
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"rhythmify/shared/response"
+)
+
+// Middleware creates a net/http middleware that requires a valid bearer
+// token passing every check
+func Middleware(verifier Verifier, checks ...Check) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := authenticate(r.Context(), verifier, r.Header.Get("Authorization"), checks)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+		})
+	}
+}
+
+// OptionalMiddleware creates a net/http middleware that authenticates
+// requests with a valid bearer token and lets every other request through
+// anonymously
+func OptionalMiddleware(verifier Verifier, checks ...Check) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := authenticate(r.Context(), verifier, r.Header.Get("Authorization"), checks)
+			if err == nil {
+				r = r.WithContext(NewContext(r.Context(), claims))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Require creates a net/http middleware that runs more checks on a route.
+// It must run after Middleware or OptionalMiddleware and rejects anonymous
+// requests.
+func Require(checks ...Check) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				writeError(w, ErrMissingToken)
+				return
+			}
+
+			if err := runChecks(claims, checks); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeError responds with the error of a failed authentication in the
+// same shape as the Gin services
+func writeError(w http.ResponseWriter, err error) {
+	status, message, code := errorStatus(err)
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response.ErrorResponse{
+		Success: false,
+		Error:   message,
+		Code:    code,
+	})
+}
@@ -0,0 +1,248 @@
+package authn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"rhythmify/shared/jwt"
+	"rhythmify/shared/serviceauth"
+)
+
+// maxIntrospectionCacheEntries bounds the introspection cache; it is
+// cleared of expired entries when full
+const maxIntrospectionCacheEntries = 10000
+
+// IntrospectionSettings configures an IntrospectionVerifier
+type IntrospectionSettings struct {
+	// URL is the introspection endpoint, e.g. http://auth:8081/oauth/introspect
+	URL string
+
+	// ServiceName and ServiceKey sign requests as in INTERNAL_SERVICE_KEYS
+	ServiceName string
+	ServiceKey  string
+
+	// MaxCacheTTL caps how long responses are cached. The auth service
+	// decides the TTL of each response; zero disables caching.
+	MaxCacheTTL time.Duration
+
+	// Client defaults to one with a 5 second timeout
+	Client *http.Client
+}
+
+// IntrospectionVerifier asks the auth service whether a token is active. It
+// sees revocations, deleted and suspended accounts and accepts API keys,
+// at the cost of a request per token and cache period.
+type IntrospectionVerifier struct {
+	settings IntrospectionSettings
+
+	mu    sync.Mutex
+	cache map[string]introspectionCacheEntry
+}
+
+// introspectionCacheEntry is a cached result of an active token
+type introspectionCacheEntry struct {
+	claims    *jwt.Claims
+	expiresAt time.Time
+}
+
+// introspectionResponse is the RFC 7662 response of the auth service
+type introspectionResponse struct {
+	Active        bool     `json:"active"`
+	Scope         string   `json:"scope"`
+	Username      string   `json:"username"`
+	TokenType     string   `json:"token_type"`
+	ExpiresAt     int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Subject       string   `json:"sub"`
+	Issuer        string   `json:"iss"`
+	Audience      []string `json:"aud"`
+	TokenID       string   `json:"jti"`
+	SessionID     string   `json:"sid"`
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles"`
+}
+
+// NewIntrospectionVerifier creates a verifier that introspects tokens
+func NewIntrospectionVerifier(settings IntrospectionSettings) *IntrospectionVerifier {
+	if settings.Client == nil {
+		settings.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	return &IntrospectionVerifier{
+		settings: settings,
+		cache:    make(map[string]introspectionCacheEntry),
+	}
+}
+
+// Verify implements Verifier
+func (v *IntrospectionVerifier) Verify(ctx context.Context, token string) (*jwt.Claims, error) {
+	// Keep raw tokens out of memory longer than needed
+	sum := sha256.Sum256([]byte(token))
+	cacheKey := hex.EncodeToString(sum[:])
+
+	if claims, ok := v.cached(cacheKey); ok {
+		return claims, nil
+	}
+
+	claims, ttl, err := v.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inactive tokens are not cached, so an account that is unsuspended
+	// works again right away
+	if claims == nil {
+		return nil, ErrInvalidToken
+	}
+	v.store(cacheKey, claims, ttl)
+
+	return claims, nil
+}
+
+// introspect sends a signed introspection request. It returns nil claims
+// for inactive tokens, and how long the answer may be cached.
+func (v *IntrospectionVerifier) introspect(ctx context.Context, token string) (*jwt.Claims, time.Duration, error) {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.settings.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := serviceauth.Sign(req, v.settings.ServiceName, []byte(v.settings.ServiceKey)); err != nil {
+		return nil, 0, fmt.Errorf("failed to sign introspection request: %w", err)
+	}
+
+	resp, err := v.settings.Client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to introspect token: unexpected status %d", resp.StatusCode)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+
+	ttl := maxAge(resp.Header.Get("Cache-Control"))
+	if !result.Active {
+		return nil, ttl, nil
+	}
+
+	claims, err := result.claims()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return claims, ttl, nil
+}
+
+// cached returns a cached result that has not expired yet
+func (v *IntrospectionVerifier) cached(key string) (*jwt.Claims, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry, ok := v.cache[key]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.claims, true
+}
+
+// store caches a result for ttl, capped by MaxCacheTTL
+func (v *IntrospectionVerifier) store(key string, claims *jwt.Claims, ttl time.Duration) {
+	if ttl > v.settings.MaxCacheTTL {
+		ttl = v.settings.MaxCacheTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	if len(v.cache) >= maxIntrospectionCacheEntries {
+		for k, entry := range v.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(v.cache, k)
+			}
+		}
+		// Still full of live entries, start over rather than grow
+		if len(v.cache) >= maxIntrospectionCacheEntries {
+			v.cache = make(map[string]introspectionCacheEntry)
+		}
+	}
+
+	v.cache[key] = introspectionCacheEntry{claims: claims, expiresAt: now.Add(ttl)}
+}
+
+// claims converts an active introspection response to claims
+func (r *introspectionResponse) claims() (*jwt.Claims, error) {
+	userID, err := strconv.ParseInt(r.Subject, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject in introspection response: %q", r.Subject)
+	}
+
+	claims := &jwt.Claims{
+		UserID:        userID,
+		Username:      r.Username,
+		Type:          jwt.AccessToken,
+		SessionID:     r.SessionID,
+		EmailVerified: r.EmailVerified,
+		Roles:         r.Roles,
+		RegisteredClaims: gojwt.RegisteredClaims{
+			ID:       r.TokenID,
+			Subject:  r.Subject,
+			Issuer:   r.Issuer,
+			Audience: r.Audience,
+		},
+	}
+	if r.TokenType == "api_key" {
+		claims.Type = jwt.APIKeyToken
+		claims.Scopes = strings.Fields(r.Scope)
+	}
+	if r.ExpiresAt != 0 {
+		claims.ExpiresAt = gojwt.NewNumericDate(time.Unix(r.ExpiresAt, 0))
+	}
+	if r.IssuedAt != 0 {
+		claims.IssuedAt = gojwt.NewNumericDate(time.Unix(r.IssuedAt, 0))
+	}
+
+	return claims, nil
+}
+
+// maxAge parses the max-age of a Cache-Control header. no-store, no-cache
+// and missing directives mean the response must not be cached.
+func maxAge(cacheControl string) time.Duration {
+	var age time.Duration
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+		switch {
+		case directive == "no-store" || directive == "no-cache":
+			return 0
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil || seconds < 0 {
+				return 0
+			}
+			age = time.Duration(seconds) * time.Second
+		}
+	}
+	return age
+}
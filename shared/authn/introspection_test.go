@@ -0,0 +1,218 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"rhythmify/shared/jwt"
+	"rhythmify/shared/serviceauth"
+)
+
+const (
+	activeResponse   = `{"active":true,"sub":"1","username":"user","token_type":"access_token","jti":"abc","sid":"s1","aud":["playlist"],"roles":["admin"]}`
+	apiKeyResponse   = `{"active":true,"sub":"1","username":"user","token_type":"api_key","jti":"7","scope":"profile:read export"}`
+	inactiveResponse = `{"active":false}`
+)
+
+// introspectionServer answers introspection requests with a fixed body and
+// Cache-Control header and counts them
+type introspectionServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	body         string
+	cacheControl string
+	requests     int
+}
+
+func newIntrospectionServer(t *testing.T, body, cacheControl string) *introspectionServer {
+	s := &introspectionServer{body: body, cacheControl: cacheControl}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests++
+		if r.Header.Get(serviceauth.HeaderService) != "playlist" || r.Header.Get(serviceauth.HeaderSignature) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("token") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if s.cacheControl != "" {
+			w.Header().Set("Cache-Control", s.cacheControl)
+		}
+		fmt.Fprint(w, s.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *introspectionServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func newTestIntrospectionVerifier(url string, maxCacheTTL time.Duration) *IntrospectionVerifier {
+	return NewIntrospectionVerifier(IntrospectionSettings{
+		URL:         url,
+		ServiceName: "playlist",
+		ServiceKey:  "playlist-key",
+		MaxCacheTTL: maxCacheTTL,
+	})
+}
+
+func TestIntrospectionVerifierCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		cacheControl string
+		maxCacheTTL  time.Duration
+		wantActive   bool
+		wantRequests int
+	}{
+		{name: "cacheable active token", body: activeResponse, cacheControl: "private, max-age=60", maxCacheTTL: time.Minute, wantActive: true, wantRequests: 1},
+		{name: "caching disabled", body: activeResponse, cacheControl: "private, max-age=60", wantActive: true, wantRequests: 3},
+		{name: "no-store", body: activeResponse, cacheControl: "no-store", maxCacheTTL: time.Minute, wantActive: true, wantRequests: 3},
+		{name: "no-cache", body: activeResponse, cacheControl: "max-age=60, no-cache", maxCacheTTL: time.Minute, wantActive: true, wantRequests: 3},
+		{name: "no Cache-Control", body: activeResponse, maxCacheTTL: time.Minute, wantActive: true, wantRequests: 3},
+		{name: "invalid max-age", body: activeResponse, cacheControl: "max-age=soon", maxCacheTTL: time.Minute, wantActive: true, wantRequests: 3},
+		{name: "inactive token", body: inactiveResponse, cacheControl: "private, max-age=60", maxCacheTTL: time.Minute, wantRequests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newIntrospectionServer(t, tt.body, tt.cacheControl)
+			verifier := newTestIntrospectionVerifier(server.URL, tt.maxCacheTTL)
+
+			for i := 0; i < 3; i++ {
+				claims, err := verifier.Verify(context.Background(), "token")
+				if tt.wantActive {
+					if err != nil {
+						t.Fatalf("Verify failed: %v", err)
+					}
+					if claims.UserID != 1 || claims.Type != jwt.AccessToken {
+						t.Errorf("claims = %+v", claims)
+					}
+				} else if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Verify = %v, want ErrInvalidToken", err)
+				}
+			}
+
+			if got := server.requestCount(); got != tt.wantRequests {
+				t.Errorf("introspection requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestIntrospectionVerifierCapsCacheTTL(t *testing.T) {
+	server := newIntrospectionServer(t, activeResponse, "private, max-age=3600")
+	verifier := newTestIntrospectionVerifier(server.URL, time.Minute)
+
+	if _, err := verifier.Verify(context.Background(), "token"); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	verifier.mu.Lock()
+	defer verifier.mu.Unlock()
+	if len(verifier.cache) != 1 {
+		t.Fatalf("cache holds %d entries, want 1", len(verifier.cache))
+	}
+	for _, entry := range verifier.cache {
+		if ttl := time.Until(entry.expiresAt); ttl > time.Minute || ttl < 50*time.Second {
+			t.Errorf("cached for %s, want MaxCacheTTL of a minute", ttl)
+		}
+	}
+}
+
+func TestIntrospectionVerifierExpiresCache(t *testing.T) {
+	server := newIntrospectionServer(t, activeResponse, "private, max-age=60")
+	verifier := newTestIntrospectionVerifier(server.URL, time.Minute)
+
+	if _, err := verifier.Verify(context.Background(), "token"); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// Age the entry past its expiry
+	verifier.mu.Lock()
+	for key, entry := range verifier.cache {
+		entry.expiresAt = time.Now().Add(-time.Second)
+		verifier.cache[key] = entry
+	}
+	verifier.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), "token"); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if got := server.requestCount(); got != 2 {
+		t.Errorf("introspection requests = %d, want 2", got)
+	}
+}
+
+func TestIntrospectionVerifierClaims(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		check func(t *testing.T, claims *jwt.Claims)
+	}{
+		{
+			name: "access token",
+			body: activeResponse,
+			check: func(t *testing.T, claims *jwt.Claims) {
+				if claims.Type != jwt.AccessToken || claims.SessionID != "s1" || !claims.HasRole("admin") {
+					t.Errorf("claims = %+v", claims)
+				}
+				if err := RequireAudience("playlist")(claims); err != nil {
+					t.Errorf("RequireAudience = %v, want success", err)
+				}
+			},
+		},
+		{
+			name: "api key",
+			body: apiKeyResponse,
+			check: func(t *testing.T, claims *jwt.Claims) {
+				if claims.Type != jwt.APIKeyToken || claims.ID != "7" {
+					t.Errorf("claims = %+v", claims)
+				}
+				if err := RequireScopes("profile:read", "export")(claims); err != nil {
+					t.Errorf("RequireScopes for granted scopes = %v", err)
+				}
+				if err := RequireScopes("profile:write")(claims); !errors.Is(err, ErrInsufficientScope) {
+					t.Errorf("RequireScopes for another scope = %v, want ErrInsufficientScope", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newIntrospectionServer(t, tt.body, "no-store")
+			claims, err := newTestIntrospectionVerifier(server.URL, 0).Verify(context.Background(), "token")
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			tt.check(t, claims)
+		})
+	}
+}
+
+func TestIntrospectionVerifierUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := newTestIntrospectionVerifier(server.URL, time.Minute).Verify(context.Background(), "token")
+	if err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify with the auth service failing = %v, want an error other than ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,144 @@
+package authn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"rhythmify/shared/jwt"
+)
+
+// jwksRefetchInterval is how often a token signed with an unknown key may
+// trigger fetching the key set early
+const jwksRefetchInterval = 30 * time.Second
+
+// JWKSVerifier verifies RS256 and EdDSA access tokens with the key set the
+// auth service publishes. The keys are cached and refreshed in the
+// background by RunRefresh, and fetched early when a token names a key
+// that is not known yet. It cannot see revocations.
+type JWKSVerifier struct {
+	url    string
+	client *http.Client
+
+	mu      sync.RWMutex
+	manager *jwt.JWTManager
+
+	// fetchMu guards fetchedAt only and is never held during a fetch
+	fetchMu   sync.Mutex
+	fetchedAt time.Time
+}
+
+// NewJWKSVerifier creates a verifier for the key set at url and fetches it
+// once. A nil client uses one with a 10 second timeout.
+func NewJWKSVerifier(ctx context.Context, url string, client *http.Client) (*JWKSVerifier, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	v := &JWKSVerifier{
+		url:    url,
+		client: client,
+	}
+	if err := v.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Verify implements Verifier
+func (v *JWKSVerifier) Verify(ctx context.Context, token string) (*jwt.Claims, error) {
+	v.mu.RLock()
+	manager := v.manager
+	v.mu.RUnlock()
+
+	claims, err := verifyAccessToken(manager, token)
+	if err == nil || !errors.Is(err, jwt.ErrUnknownKey) {
+		return claims, err
+	}
+
+	// The key may have been added since the last refresh
+	v.refetch(ctx)
+
+	v.mu.RLock()
+	manager = v.manager
+	v.mu.RUnlock()
+
+	return verifyAccessToken(manager, token)
+}
+
+// Refresh fetches the key set and replaces the cached keys
+func (v *JWKSVerifier) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build key set request: %w", err)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch key set: unexpected status %d", resp.StatusCode)
+	}
+
+	var set jwt.JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode key set: %w", err)
+	}
+
+	keyring, err := jwt.NewKeyringFromJWKS(&set)
+	if err != nil {
+		return fmt.Errorf("failed to load key set: %w", err)
+	}
+
+	v.mu.Lock()
+	v.manager = jwt.NewJWTManagerWithKeyring(keyring, 0, 0)
+	v.mu.Unlock()
+
+	return nil
+}
+
+// RunRefresh refreshes the key set every interval until ctx is done. Failed
+// refreshes keep the keys fetched before.
+func (v *JWKSVerifier) RunRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := v.Refresh(ctx); err != nil {
+			log.Printf("JWKS refresh failed: %v", err)
+		}
+	}
+}
+
+// refetch refreshes the key set for an unknown key, at most once every
+// jwksRefetchInterval so that forged kids can't hammer the auth service.
+// Only the caller that starts a fetch waits for it; the others go on with
+// the keys they have.
+func (v *JWKSVerifier) refetch(ctx context.Context) {
+	v.fetchMu.Lock()
+	if time.Since(v.fetchedAt) < jwksRefetchInterval {
+		v.fetchMu.Unlock()
+		return
+	}
+	v.fetchedAt = time.Now()
+	v.fetchMu.Unlock()
+
+	if err := v.Refresh(ctx); err != nil {
+		log.Printf("JWKS refresh failed: %v", err)
+	}
+}
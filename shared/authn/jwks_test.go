@@ -0,0 +1,238 @@
+package authn
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"rhythmify/shared/jwt"
+)
+
+// newTestSigner creates a JWT manager signing with a new Ed25519 key
+func newTestSigner(t *testing.T) *jwt.JWTManager {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	key, err := jwt.ParseKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParseKeyPEM failed: %v", err)
+	}
+	keyring, err := jwt.NewKeyring(key)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	return jwt.NewJWTManagerWithKeyring(keyring, time.Minute, time.Hour)
+}
+
+// accessToken issues an access token for user 1
+func accessToken(t *testing.T, signer *jwt.JWTManager) string {
+	t.Helper()
+
+	pair, err := signer.GenerateTokenPair(1, "user@example.com", "user")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	return pair.AccessToken
+}
+
+// jwksServer publishes the key set of a signer and counts fetches
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	signer  *jwt.JWTManager
+	failing bool
+	fetches int
+}
+
+func newJWKSServer(t *testing.T, signer *jwt.JWTManager) *jwksServer {
+	s := &jwksServer{signer: signer}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.fetches++
+		if s.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(s.signer.JWKS())
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// publish replaces the published key set
+func (s *jwksServer) publish(signer *jwt.JWTManager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signer = signer
+}
+
+func (s *jwksServer) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func TestJWKSVerifierCachesKeys(t *testing.T) {
+	signer := newTestSigner(t)
+	server := newJWKSServer(t, signer)
+
+	verifier, err := NewJWKSVerifier(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewJWKSVerifier failed: %v", err)
+	}
+
+	token := accessToken(t, signer)
+	for i := 0; i < 3; i++ {
+		claims, err := verifier.Verify(context.Background(), token)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if claims.UserID != 1 {
+			t.Errorf("user id = %d, want 1", claims.UserID)
+		}
+	}
+	if got := server.fetchCount(); got != 1 {
+		t.Errorf("key set fetched %d times, want once", got)
+	}
+
+	if _, err := verifier.Verify(context.Background(), "not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify of a malformed token = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestNewJWKSVerifierFailsWithoutKeys(t *testing.T) {
+	server := newJWKSServer(t, newTestSigner(t))
+	server.setFailing(true)
+
+	if _, err := NewJWKSVerifier(context.Background(), server.URL, nil); err == nil {
+		t.Error("NewJWKSVerifier succeeded without a key set")
+	}
+}
+
+func TestJWKSVerifierRefetchesUnknownKey(t *testing.T) {
+	ctx := context.Background()
+	server := newJWKSServer(t, newTestSigner(t))
+
+	verifier, err := NewJWKSVerifier(ctx, server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewJWKSVerifier failed: %v", err)
+	}
+
+	// The auth service rotates to a new key
+	rotated := newTestSigner(t)
+	server.publish(rotated)
+
+	if _, err := verifier.Verify(ctx, accessToken(t, rotated)); err != nil {
+		t.Fatalf("Verify with a rotated key = %v, want success", err)
+	}
+	if got := server.fetchCount(); got != 2 {
+		t.Fatalf("key set fetched %d times, want 2", got)
+	}
+
+	// Further unknown keys don't hit the auth service within the interval
+	forged := newTestSigner(t)
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(ctx, accessToken(t, forged)); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify with an unknown key = %v, want ErrInvalidToken", err)
+		}
+	}
+	if got := server.fetchCount(); got != 2 {
+		t.Errorf("key set fetched %d times within the refetch interval, want 2", got)
+	}
+
+	// Once the interval has passed an unknown key triggers a fetch again
+	verifier.fetchMu.Lock()
+	verifier.fetchedAt = time.Now().Add(-jwksRefetchInterval)
+	verifier.fetchMu.Unlock()
+
+	server.publish(forged)
+	if _, err := verifier.Verify(ctx, accessToken(t, forged)); err != nil {
+		t.Fatalf("Verify after the refetch interval = %v, want success", err)
+	}
+	if got := server.fetchCount(); got != 3 {
+		t.Errorf("key set fetched %d times, want 3", got)
+	}
+}
+
+func TestJWKSVerifierRunRefresh(t *testing.T) {
+	signer := newTestSigner(t)
+	server := newJWKSServer(t, signer)
+
+	verifier, err := NewJWKSVerifier(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewJWKSVerifier failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		verifier.RunRefresh(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// Failed refreshes keep the keys fetched before
+	server.setFailing(true)
+	waitFor(t, func() bool { return server.fetchCount() >= 3 })
+	if _, err := verifier.Verify(context.Background(), accessToken(t, signer)); err != nil {
+		t.Errorf("Verify after failed refreshes = %v, want success", err)
+	}
+
+	// A key published later is picked up without a token asking for it
+	rotated := newTestSigner(t)
+	server.publish(rotated)
+	server.setFailing(false)
+	fetched := server.fetchCount()
+	waitFor(t, func() bool { return server.fetchCount() > fetched+1 })
+
+	verifier.mu.RLock()
+	_, err = verifyAccessToken(verifier.manager, accessToken(t, rotated))
+	verifier.mu.RUnlock()
+	if err != nil {
+		t.Errorf("key published later was not refreshed: %v", err)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunRefresh did not return after the context was done")
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
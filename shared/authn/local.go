@@ -0,0 +1,41 @@
+package authn
+
+import (
+	"context"
+	"fmt"
+
+	"rhythmify/shared/jwt"
+)
+
+// SecretVerifier verifies HS256 access tokens with the JWT secret shared
+// with the auth service. It cannot see revocations.
+type SecretVerifier struct {
+	manager *jwt.JWTManager
+}
+
+// NewSecretVerifier creates a verifier for tokens signed with secret
+func NewSecretVerifier(secret string) *SecretVerifier {
+	return &SecretVerifier{
+		manager: jwt.NewJWTManager(secret, 0, 0),
+	}
+}
+
+// Verify implements Verifier
+func (v *SecretVerifier) Verify(ctx context.Context, token string) (*jwt.Claims, error) {
+	return verifyAccessToken(v.manager, token)
+}
+
+// verifyAccessToken validates a token with the manager and only accepts
+// access tokens
+func verifyAccessToken(manager *jwt.JWTManager, token string) (*jwt.Claims, error) {
+	claims, err := manager.ValidateToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if claims.Type != jwt.AccessToken {
+		return nil, fmt.Errorf("%w: not an access token", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
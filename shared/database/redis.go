@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig holds Redis configuration
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// NewRedisConnection creates a new Redis client and verifies the connection
+func NewRedisConnection(cfg RedisConfig) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	log.Printf("Successfully connected to Redis: %s", cfg.Addr)
+	return client, nil
+}
+
+// CloseRedisConnection closes the Redis client gracefully
+func CloseRedisConnection(client *redis.Client) {
+	if client != nil {
+		client.Close()
+		log.Println("Redis connection closed")
+	}
+}
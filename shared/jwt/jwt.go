@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -14,27 +16,155 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+
+	// Single-use tokens sent by email
+	EmailVerificationToken TokenType = "email_verification"
+	PasswordResetToken     TokenType = "password_reset"
+	AccountRestoreToken    TokenType = "account_restore"
+	DataExportToken        TokenType = "data_export"
+
+	// Short-lived token proving the first login factor was passed
+	MFAPendingToken TokenType = "mfa_pending"
+
+	// Claims of a request authenticated with an API key. They are never
+	// signed into a token.
+	APIKeyToken TokenType = "api_key"
 )
 
 // Claims represents the JWT claims
 type Claims struct {
-	UserID   int64     `json:"user_id"`
-	Email    string    `json:"email"`
-	Username string    `json:"username"`
-	Type     TokenType `json:"type"`
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	Type      TokenType `json:"type"`
+	SessionID string    `json:"sid,omitempty"`
+
+	// ResourceID binds a one-time token to the object it grants access to
+	ResourceID string `json:"rid,omitempty"`
+
+	EmailVerified bool     `json:"email_verified,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasRole checks if the claims carry the given role
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope checks if the claims grant the given scope. Only API keys are
+// limited by scopes; every other token grants them all.
+func (c *Claims) HasScope(scope string) bool {
+	if c.Type != APIKeyToken {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // TokenPair represents access and refresh tokens
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"`
+
+	// Server-side bookkeeping, never sent to clients
+	AccessTokenID    string    `json:"-"`
+	RefreshTokenID   string    `json:"-"`
+	RefreshExpiresAt time.Time `json:"-"`
+	SessionID        string    `json:"-"`
 }
 
-// JWTManager handles JWT operations
+// TokenOption customizes the claims of generated tokens
+type TokenOption func(*Claims)
+
+// WithSession sets the session the pair belongs to. All refresh tokens of a
+// session form one rotation family. Without it a new session ID is generated.
+func WithSession(sessionID string) TokenOption {
+	return func(c *Claims) {
+		c.SessionID = sessionID
+	}
+}
+
+// WithEmailVerified marks the user's email address as verified in the claims
+func WithEmailVerified(verified bool) TokenOption {
+	return func(c *Claims) {
+		c.EmailVerified = verified
+	}
+}
+
+// WithRoles sets the roles granted to the user in the claims
+func WithRoles(roles []string) TokenOption {
+	return func(c *Claims) {
+		c.Roles = roles
+	}
+}
+
+// WithScopes limits the claims to the given scopes
+func WithScopes(scopes []string) TokenOption {
+	return func(c *Claims) {
+		c.Scopes = scopes
+	}
+}
+
+// WithAudience sets the services the token is meant for, which they can
+// require with authn.RequireAudience
+func WithAudience(audience ...string) TokenOption {
+	return func(c *Claims) {
+		c.Audience = audience
+	}
+}
+
+// WithResource binds a one-time token to a single object, such as the data
+// export a download link was issued for
+func WithResource(id string) TokenOption {
+	return func(c *Claims) {
+		c.ResourceID = id
+	}
+}
+
+// NewAPIKeyClaims builds the claims of a request authenticated with an API
+// key. The key ID is used as the token ID, and the claims expire with the
+// key if it has an expiry.
+func NewAPIKeyClaims(userID int64, email, username string, keyID string, expiresAt *time.Time, opts ...TokenOption) *Claims {
+	claims := &Claims{
+		UserID:   userID,
+		Email:    email,
+		Username: username,
+	}
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	claims.Type = APIKeyToken
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:       keyID,
+		Subject:  fmt.Sprintf("%d", userID),
+		Issuer:   "rhythmify-auth",
+		Audience: claims.Audience,
+	}
+	if expiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*expiresAt)
+	}
+
+	return claims
+}
+
+// JWTManager handles JWT operations. It signs with HS256 and a shared
+// secret, or with the active key of an asymmetric keyring.
 type JWTManager struct {
 	secretKey            string
+	keyring              *Keyring
 	accessTokenDuration  time.Duration
 	refreshTokenDuration time.Duration
 }
@@ -48,57 +178,162 @@ func NewJWTManager(secretKey string, accessDuration, refreshDuration time.Durati
 	}
 }
 
+// NewJWTManagerWithKeyring creates a JWT manager that signs with the active
+// key of the keyring and verifies with any of its keys. A keyring without an
+// active key yields a verification-only manager.
+func NewJWTManagerWithKeyring(keyring *Keyring, accessDuration, refreshDuration time.Duration) *JWTManager {
+	return &JWTManager{
+		keyring:              keyring,
+		accessTokenDuration:  accessDuration,
+		refreshTokenDuration: refreshDuration,
+	}
+}
+
+// JWKS returns the public verification keys. It is empty for HS256 managers.
+func (j *JWTManager) JWKS() *JWKSet {
+	if j.keyring == nil {
+		return &JWKSet{Keys: []JWK{}}
+	}
+	return j.keyring.JWKS()
+}
+
+// AccessTokenDuration returns the lifetime of access tokens
+func (j *JWTManager) AccessTokenDuration() time.Duration {
+	return j.accessTokenDuration
+}
+
 // GenerateTokenPair generates both access and refresh tokens
-func (j *JWTManager) GenerateTokenPair(userID int64, email, username string) (*TokenPair, error) {
+func (j *JWTManager) GenerateTokenPair(userID int64, email, username string, opts ...TokenOption) (*TokenPair, error) {
+	base := Claims{
+		UserID:   userID,
+		Email:    email,
+		Username: username,
+	}
+	for _, opt := range opts {
+		opt(&base)
+	}
+
+	if base.SessionID == "" {
+		sessionID, err := NewTokenID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate session id: %w", err)
+		}
+		base.SessionID = sessionID
+	}
+
 	// Generate access token
-	accessToken, err := j.generateToken(userID, email, username, AccessToken, j.accessTokenDuration)
+	accessToken, accessClaims, err := j.generateToken(base, AccessToken, j.accessTokenDuration)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err := j.generateToken(userID, email, username, RefreshToken, j.refreshTokenDuration)
+	refreshToken, refreshClaims, err := j.generateToken(base, RefreshToken, j.refreshTokenDuration)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
 	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    int64(j.accessTokenDuration.Seconds()),
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(j.accessTokenDuration.Seconds()),
+		AccessTokenID:    accessClaims.ID,
+		RefreshTokenID:   refreshClaims.ID,
+		RefreshExpiresAt: refreshClaims.ExpiresAt.Time,
+		SessionID:        base.SessionID,
 	}, nil
 }
 
-// generateToken creates a JWT token with the given parameters
-func (j *JWTManager) generateToken(userID int64, email, username string, tokenType TokenType, duration time.Duration) (string, error) {
+// GenerateOneTimeToken generates a signed token of a single-use type such as
+// EmailVerificationToken. Single use is enforced by the caller using the jti.
+func (j *JWTManager) GenerateOneTimeToken(userID int64, email string, tokenType TokenType, duration time.Duration, opts ...TokenOption) (string, *Claims, error) {
+	if tokenType == AccessToken || tokenType == RefreshToken {
+		return "", nil, fmt.Errorf("token type %s cannot be issued as a one-time token", tokenType)
+	}
+
+	base := Claims{
+		UserID: userID,
+		Email:  email,
+	}
+	for _, opt := range opts {
+		opt(&base)
+	}
+
+	return j.generateToken(base, tokenType, duration)
+}
+
+// generateToken creates a JWT token from the base claims with the given type and lifetime
+func (j *JWTManager) generateToken(base Claims, tokenType TokenType, duration time.Duration) (string, *Claims, error) {
+	tokenID, err := NewTokenID()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token id: %w", err)
+	}
+
 	now := time.Now()
-	claims := Claims{
-		UserID:   userID,
-		Email:    email,
-		Username: username,
-		Type:     tokenType,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   fmt.Sprintf("%d", userID),
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "rhythmify-auth",
-		},
+	claims := base
+	claims.Type = tokenType
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		Subject:   fmt.Sprintf("%d", base.UserID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    "rhythmify-auth",
+		Audience:  base.Audience,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	signed, err := j.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, &claims, nil
 }
 
-// ValidateToken validates and parses a JWT token
-func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+// sign signs the claims with the shared secret or the active keyring key
+func (j *JWTManager) sign(claims Claims) (string, error) {
+	if j.keyring == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.secretKey))
+	}
+
+	key := j.keyring.Active()
+	if key == nil {
+		return "", errors.New("keyring has no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.privateKey)
+}
+
+// verificationKey resolves the key for a parsed token
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if j.keyring == nil {
 		// Verify signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(j.secretKey), nil
-	})
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keyring.Key(kid)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	// The algorithm is pinned by the key, never taken from the token alone
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.publicKey, nil
+}
+
+// ValidateToken validates and parses a JWT token
+func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.verificationKey)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -112,9 +347,10 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-// RefreshAccessToken generates a new access token using refresh token
-func (j *JWTManager) RefreshAccessToken(refreshTokenString string) (*TokenPair, error) {
-	// Validate refresh token
+// ValidateRefreshToken validates a refresh token and returns its claims.
+// It only checks the signature and expiry; whether the token has already
+// been rotated is tracked by the caller's refresh token store.
+func (j *JWTManager) ValidateRefreshToken(refreshTokenString string) (*Claims, error) {
 	claims, err := j.ValidateToken(refreshTokenString)
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
@@ -125,6 +361,18 @@ func (j *JWTManager) RefreshAccessToken(refreshTokenString string) (*TokenPair,
 		return nil, errors.New("token is not a refresh token")
 	}
 
-	// Generate new token pair
-	return j.GenerateTokenPair(claims.UserID, claims.Email, claims.Username)
-}
\ No newline at end of file
+	if claims.ID == "" {
+		return nil, errors.New("refresh token has no id")
+	}
+
+	return claims, nil
+}
+
+// NewTokenID generates a random identifier suitable for a jti claim
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,368 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported asymmetric signing algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// ErrUnknownKey is returned when a token names a kid the keyring doesn't hold
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// SigningKey is an asymmetric key identified by its key ID (kid).
+// Keys loaded from a public key PEM can only verify tokens.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// CanSign reports whether the key holds private key material
+func (k *SigningKey) CanSign() bool {
+	return k.privateKey != nil
+}
+
+// signingMethod returns the jwt signing method matching the key algorithm
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// Keyring holds the active signing key and previous keys that are still
+// accepted for verification, so keys can be rotated without logging
+// everyone out.
+type Keyring struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	order  []string
+}
+
+// NewKeyring creates a keyring. The active key may be nil for a
+// verification-only keyring.
+func NewKeyring(active *SigningKey, previous ...*SigningKey) (*Keyring, error) {
+	ring := &Keyring{
+		keys: make(map[string]*SigningKey),
+	}
+
+	if active != nil {
+		if !active.CanSign() {
+			return nil, errors.New("active key must contain a private key")
+		}
+		ring.active = active
+		ring.add(active)
+	}
+
+	for _, key := range previous {
+		ring.add(key)
+	}
+
+	if len(ring.keys) == 0 {
+		return nil, errors.New("keyring has no keys")
+	}
+
+	return ring, nil
+}
+
+// NewKeyringFromJWKS creates a verification-only keyring from a JSON Web Key
+// Set, such as the one published by the auth service. Keys of unsupported
+// types are skipped.
+func NewKeyringFromJWKS(set *JWKSet) (*Keyring, error) {
+	keys := make([]*SigningKey, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.SigningKey()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeyring(nil, keys...)
+}
+
+// LoadKeyring loads the active private key and previous keys from PEM files.
+// An empty activePath creates a verification-only keyring.
+func LoadKeyring(activePath string, previousPaths []string) (*Keyring, error) {
+	var active *SigningKey
+	if activePath != "" {
+		key, err := LoadKeyFile(activePath)
+		if err != nil {
+			return nil, err
+		}
+		active = key
+	}
+
+	previous := make([]*SigningKey, 0, len(previousPaths))
+	for _, path := range previousPaths {
+		key, err := LoadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+
+	return NewKeyring(active, previous...)
+}
+
+// Active returns the key used for signing, nil for a verification-only keyring
+func (r *Keyring) Active() *SigningKey {
+	return r.active
+}
+
+// Key returns the key with the given kid
+func (r *Keyring) Key(kid string) (*SigningKey, bool) {
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// JWKS returns the public keys of the keyring as a JSON Web Key Set
+func (r *Keyring) JWKS() *JWKSet {
+	set := &JWKSet{Keys: make([]JWK, 0, len(r.order))}
+	for _, kid := range r.order {
+		jwk, err := publicJWK(r.keys[kid])
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// add registers a key, ignoring duplicates
+func (r *Keyring) add(key *SigningKey) {
+	if _, exists := r.keys[key.ID]; exists {
+		return
+	}
+	r.keys[key.ID] = key
+	r.order = append(r.order, key.ID)
+}
+
+// LoadKeyFile reads a PEM encoded RSA or Ed25519 key from disk
+func LoadKeyFile(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+// ParseKeyPEM parses a PEM encoded private or public key. RSA keys are used
+// with RS256 and Ed25519 keys with EdDSA. The kid is the RFC 7638 thumbprint
+// of the public key.
+func ParseKeyPEM(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newSigningKey(parsed)
+}
+
+// newSigningKey wraps a parsed key
+func newSigningKey(parsed interface{}) (*SigningKey, error) {
+	key := &SigningKey{}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = AlgorithmRS256
+		key.privateKey = k
+		key.publicKey = &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm = AlgorithmRS256
+		key.publicKey = k
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgorithmEdDSA
+		key.privateKey = k
+		key.publicKey = k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm = AlgorithmEdDSA
+		key.publicKey = k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	jwk, err := publicJWK(key)
+	if err != nil {
+		return nil, err
+	}
+
+	key.ID, err = jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// JWK is a public JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+
+	// RSA parameters
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP parameters
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Thumbprint computes the RFC 7638 SHA-256 thumbprint of the key
+func (k JWK) Thumbprint() (string, error) {
+	var members interface{}
+	switch k.Kty {
+	case "RSA":
+		// Member order is lexicographic as required by RFC 7638
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// PublicKey decodes the public key described by the JWK
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// SigningKey returns a verification-only key for the JWK. The kid of the JWK
+// is kept so that tokens naming it can be verified.
+func (k JWK) SigningKey() (*SigningKey, error) {
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newSigningKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.Alg != "" && k.Alg != key.Algorithm {
+		return nil, fmt.Errorf("algorithm %q does not match key type %q", k.Alg, k.Kty)
+	}
+	if k.Kid != "" {
+		key.ID = k.Kid
+	}
+
+	return key, nil
+}
+
+// publicJWK converts the public part of a key to a JWK
+func publicJWK(key *SigningKey) (JWK, error) {
+	switch pub := key.publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: AlgorithmRS256,
+			Kid: key.ID,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: AlgorithmEdDSA,
+			Kid: key.ID,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", key.publicKey)
+	}
+}
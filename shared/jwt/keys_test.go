@@ -0,0 +1,415 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys holds one RSA and one Ed25519 key pair
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+
+	return testKeys{rsa: rsaKey, ed25519: edKey}
+}
+
+// encodePEM encodes DER bytes as a PEM block of the given type
+func encodePEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+// b64 encodes bytes as JWK members are encoded
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// pkcs8PEM encodes a private key as a PKCS #8 PEM block
+func pkcs8PEM(t *testing.T, key interface{}) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	return encodePEM("PRIVATE KEY", der)
+}
+
+// pkixPEM encodes a public key as a PKIX PEM block
+func pkixPEM(t *testing.T, key interface{}) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	return encodePEM("PUBLIC KEY", der)
+}
+
+// mustParseKey parses a PEM key or fails the test
+func mustParseKey(t *testing.T, data []byte) *SigningKey {
+	t.Helper()
+
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		t.Fatalf("ParseKeyPEM failed: %v", err)
+	}
+	return key
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	keys := newTestKeys(t)
+
+	rsaKid, err := (JWK{Kty: "RSA", N: b64(keys.rsa.N.Bytes()), E: "AQAB"}).Thumbprint()
+	if err != nil {
+		t.Fatalf("Thumbprint failed: %v", err)
+	}
+	edKid, err := (JWK{Kty: "OKP", Crv: "Ed25519", X: b64(keys.ed25519.Public().(ed25519.PublicKey))}).Thumbprint()
+	if err != nil {
+		t.Fatalf("Thumbprint failed: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		pem           []byte
+		wantAlgorithm string
+		wantKid       string
+		wantCanSign   bool
+	}{
+		{
+			name:          "RSA PKCS#1 private key",
+			pem:           encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(keys.rsa)),
+			wantAlgorithm: AlgorithmRS256,
+			wantKid:       rsaKid,
+			wantCanSign:   true,
+		},
+		{
+			name:          "RSA PKCS#8 private key",
+			pem:           pkcs8PEM(t, keys.rsa),
+			wantAlgorithm: AlgorithmRS256,
+			wantKid:       rsaKid,
+			wantCanSign:   true,
+		},
+		{
+			name:          "RSA PKCS#1 public key",
+			pem:           encodePEM("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&keys.rsa.PublicKey)),
+			wantAlgorithm: AlgorithmRS256,
+			wantKid:       rsaKid,
+		},
+		{
+			name:          "RSA PKIX public key",
+			pem:           pkixPEM(t, &keys.rsa.PublicKey),
+			wantAlgorithm: AlgorithmRS256,
+			wantKid:       rsaKid,
+		},
+		{
+			name:          "Ed25519 private key",
+			pem:           pkcs8PEM(t, keys.ed25519),
+			wantAlgorithm: AlgorithmEdDSA,
+			wantKid:       edKid,
+			wantCanSign:   true,
+		},
+		{
+			name:          "Ed25519 public key",
+			pem:           pkixPEM(t, keys.ed25519.Public()),
+			wantAlgorithm: AlgorithmEdDSA,
+			wantKid:       edKid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := mustParseKey(t, tt.pem)
+
+			if key.Algorithm != tt.wantAlgorithm {
+				t.Errorf("Algorithm = %q, want %q", key.Algorithm, tt.wantAlgorithm)
+			}
+			if key.ID != tt.wantKid {
+				t.Errorf("ID = %q, want thumbprint %q", key.ID, tt.wantKid)
+			}
+			if key.CanSign() != tt.wantCanSign {
+				t.Errorf("CanSign = %v, want %v", key.CanSign(), tt.wantCanSign)
+			}
+		})
+	}
+}
+
+func TestParseKeyPEMErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pem     []byte
+		wantErr string
+	}{
+		{name: "no PEM block", pem: []byte("not a key"), wantErr: "no PEM block"},
+		{name: "certificate", pem: encodePEM("CERTIFICATE", []byte{0x30}), wantErr: "unsupported PEM block type"},
+		{name: "corrupt key", pem: encodePEM("PRIVATE KEY", []byte{0x30, 0x00}), wantErr: "asn1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKeyPEM(tt.pem)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseKeyPEM error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	keys := newTestKeys(t)
+	dir := t.TempDir()
+
+	activePath := filepath.Join(dir, "active.pem")
+	previousPath := filepath.Join(dir, "previous.pem")
+	if err := os.WriteFile(activePath, pkcs8PEM(t, keys.ed25519), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	if err := os.WriteFile(previousPath, pkixPEM(t, &keys.rsa.PublicKey), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	ring, err := LoadKeyring(activePath, []string{previousPath})
+	if err != nil {
+		t.Fatalf("LoadKeyring failed: %v", err)
+	}
+	if ring.Active() == nil || ring.Active().Algorithm != AlgorithmEdDSA {
+		t.Fatalf("Active = %+v, want the Ed25519 key", ring.Active())
+	}
+	previous := mustParseKey(t, encodePEM("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&keys.rsa.PublicKey)))
+	if _, ok := ring.Key(previous.ID); !ok {
+		t.Error("previous key is not in the keyring")
+	}
+
+	if _, err := LoadKeyring(filepath.Join(dir, "missing.pem"), nil); err == nil || !strings.Contains(err.Error(), "missing.pem") {
+		t.Errorf("LoadKeyring of a missing file = %v, want an error naming the file", err)
+	}
+
+	// A public key cannot sign
+	if _, err := LoadKeyring(previousPath, nil); err == nil {
+		t.Error("LoadKeyring with a public active key succeeded")
+	}
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  JWK
+		want string
+	}{
+		{
+			// RFC 7638, section 3.1
+			name: "RSA",
+			jwk: JWK{
+				Kty: "RSA",
+				N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+				E:   "AQAB",
+				// Members outside the thumbprint are ignored
+				Alg: AlgorithmRS256,
+				Kid: "2011-04-29",
+			},
+			want: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			// RFC 8037, appendix A.3
+			name: "Ed25519",
+			jwk:  JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+			want: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.jwk.Thumbprint()
+			if err != nil {
+				t.Fatalf("Thumbprint failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Thumbprint = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (JWK{Kty: "EC"}).Thumbprint(); err == nil {
+		t.Error("Thumbprint of an EC key succeeded")
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	active := mustParseKey(t, pkcs8PEM(t, keys.rsa))
+	previous := mustParseKey(t, pkcs8PEM(t, keys.ed25519))
+
+	ring, err := NewKeyring(active, previous, active)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	set := ring.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2 without duplicates", len(set.Keys))
+	}
+
+	want := []JWK{
+		{Kty: "RSA", Use: "sig", Alg: AlgorithmRS256, Kid: active.ID, N: b64(keys.rsa.N.Bytes()), E: "AQAB"},
+		{Kty: "OKP", Use: "sig", Alg: AlgorithmEdDSA, Kid: previous.ID, Crv: "Ed25519", X: b64(keys.ed25519.Public().(ed25519.PublicKey))},
+	}
+	for i, jwk := range set.Keys {
+		if jwk != want[i] {
+			t.Errorf("JWKS key %d = %+v, want %+v", i, jwk, want[i])
+		}
+	}
+
+	// Services verifying with the published set accept tokens of the active key
+	manager := NewJWTManagerWithKeyring(ring, 15*time.Minute, time.Hour)
+	tokens, err := manager.GenerateTokenPair(1, "user@example.com", "user")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+
+	published, err := NewKeyringFromJWKS(set)
+	if err != nil {
+		t.Fatalf("NewKeyringFromJWKS failed: %v", err)
+	}
+	verifier := NewJWTManagerWithKeyring(published, 15*time.Minute, time.Hour)
+	if _, err := verifier.ValidateToken(tokens.AccessToken); err != nil {
+		t.Errorf("ValidateToken with the published keys = %v, want success", err)
+	}
+	if _, _, err := verifier.GenerateOneTimeToken(1, "user@example.com", EmailVerificationToken, time.Hour); err == nil {
+		t.Error("verification-only manager signed a token")
+	}
+}
+
+func TestValidateTokenPinsAlgorithmToKey(t *testing.T) {
+	keys := newTestKeys(t)
+	rsaKey := mustParseKey(t, pkcs8PEM(t, keys.rsa))
+	edKey := mustParseKey(t, pkcs8PEM(t, keys.ed25519))
+
+	ring, err := NewKeyring(rsaKey, edKey)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	manager := NewJWTManagerWithKeyring(ring, 15*time.Minute, time.Hour)
+
+	claims := Claims{
+		UserID: 1,
+		Type:   AccessToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "token-id",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	// sign signs the claims with method and key, naming kid in the header
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "RS256 with the RSA kid", token: sign(jwt.SigningMethodRS256, rsaKey.ID, keys.rsa)},
+		{name: "EdDSA with the Ed25519 kid", token: sign(jwt.SigningMethodEdDSA, edKey.ID, keys.ed25519)},
+		{name: "EdDSA with the RSA kid", token: sign(jwt.SigningMethodEdDSA, rsaKey.ID, keys.ed25519), wantErr: true},
+		{name: "RS256 with the Ed25519 kid", token: sign(jwt.SigningMethodRS256, edKey.ID, keys.rsa), wantErr: true},
+		{name: "PS256 with the RSA kid", token: sign(jwt.SigningMethodPS256, rsaKey.ID, keys.rsa), wantErr: true},
+		{
+			// The public key used as an HMAC secret must not verify
+			name:    "HS256 keyed with the public key",
+			token:   sign(jwt.SigningMethodHS256, rsaKey.ID, pkixPEM(t, &keys.rsa.PublicKey)),
+			wantErr: true,
+		},
+		{name: "unknown kid", token: sign(jwt.SigningMethodRS256, "unknown", keys.rsa), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.ValidateToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateToken error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTokenAfterRotation(t *testing.T) {
+	keys := newTestKeys(t)
+	oldKey := mustParseKey(t, pkcs8PEM(t, keys.rsa))
+	newKey := mustParseKey(t, pkcs8PEM(t, keys.ed25519))
+
+	oldRing, err := NewKeyring(oldKey)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	before, err := NewJWTManagerWithKeyring(oldRing, 15*time.Minute, time.Hour).GenerateTokenPair(1, "user@example.com", "user")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+
+	// The new key signs, the old one is kept for verification
+	rotatedRing, err := NewKeyring(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	rotated := NewJWTManagerWithKeyring(rotatedRing, 15*time.Minute, time.Hour)
+
+	if _, err := rotated.ValidateToken(before.AccessToken); err != nil {
+		t.Errorf("ValidateToken of a token signed before the rotation = %v, want success", err)
+	}
+	if _, err := rotated.ValidateRefreshToken(before.RefreshToken); err != nil {
+		t.Errorf("ValidateRefreshToken of a token signed before the rotation = %v, want success", err)
+	}
+
+	after, err := rotated.GenerateTokenPair(1, "user@example.com", "user")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	token, _, err := jwt.NewParser().ParseUnverified(after.AccessToken, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified failed: %v", err)
+	}
+	if token.Header["kid"] != newKey.ID || token.Header["alg"] != AlgorithmEdDSA {
+		t.Errorf("token header = %v, want the new key", token.Header)
+	}
+
+	// Once the old key is dropped its tokens are rejected
+	newRing, err := NewKeyring(newKey)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	_, err = NewJWTManagerWithKeyring(newRing, 15*time.Minute, time.Hour).ValidateToken(before.AccessToken)
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("ValidateToken after dropping the old key = %v, want ErrUnknownKey", err)
+	}
+}
@@ -16,9 +16,10 @@ type Response struct {
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
+	Success bool        `json:"success"`
+	Error   string      `json:"error"`
+	Code    string      `json:"code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 // SuccessResponse sends a successful response
@@ -39,6 +40,17 @@ func ErrorResponseWithCode(c *gin.Context, statusCode int, error string, code st
 	})
 }
 
+// ErrorResponseWithDetails sends an error response with custom error code and
+// structured details clients can display
+func ErrorResponseWithDetails(c *gin.Context, statusCode int, error string, code string, details interface{}) {
+	c.JSON(statusCode, ErrorResponse{
+		Success: false,
+		Error:   error,
+		Code:    code,
+		Details: details,
+	})
+}
+
 // BadRequest sends a 400 Bad Request response
 func BadRequest(c *gin.Context, error string) {
 	ErrorResponseWithCode(c, http.StatusBadRequest, error, "BAD_REQUEST")
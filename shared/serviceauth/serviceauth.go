@@ -0,0 +1,112 @@
+// Package serviceauth signs and verifies service-to-service HTTP requests.
+//
+// Every calling service has a name and one or more shared secret keys. A
+// request is signed with HMAC-SHA256 over its method, path and query, a
+// unix timestamp, a random nonce and the SHA-256 of its body:
+//
+//	METHOD \n PATH[?QUERY] \n TIMESTAMP \n NONCE \n hex(sha256(BODY))
+//
+// The receiver rejects stale timestamps and nonces it has already seen.
+package serviceauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers carrying the signature
+const (
+	HeaderService   = "X-Service-Name"
+	HeaderTimestamp = "X-Service-Timestamp"
+	HeaderNonce     = "X-Service-Nonce"
+	HeaderSignature = "X-Service-Signature"
+)
+
+// MaxBodySize is the largest request body that can be signed
+const MaxBodySize = 1 << 20
+
+// StringToSign builds the canonical string a request signature covers
+func StringToSign(method, path, rawQuery string, timestamp int64, nonce string, bodyHash string) string {
+	target := path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		target,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		bodyHash,
+	}, "\n")
+}
+
+// HashBody returns the hex-encoded SHA-256 of a request body
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Signature returns the hex-encoded HMAC-SHA256 of the string to sign
+func Signature(key []byte, stringToSign string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature in constant time
+func Verify(key []byte, stringToSign string, signature string) bool {
+	expected := Signature(key, stringToSign)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// NewNonce generates a random request nonce
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign adds the signature headers of the calling service to a request. The
+// body is read and replaced so the request can still be sent.
+func Sign(req *http.Request, service string, key []byte) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(req.Body, MaxBodySize+1))
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		if len(body) > MaxBodySize {
+			return fmt.Errorf("request body exceeds %d bytes", MaxBodySize)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	nonce, err := NewNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+
+	stringToSign := StringToSign(req.Method, req.URL.EscapedPath(), req.URL.RawQuery, timestamp, nonce, HashBody(body))
+
+	req.Header.Set(HeaderService, service)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Signature(key, stringToSign))
+
+	return nil
+}
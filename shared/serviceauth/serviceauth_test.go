@@ -0,0 +1,122 @@
+package serviceauth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStringToSign(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		rawQuery string
+		want     string
+	}{
+		{
+			name:   "without query",
+			method: "post",
+			path:   "/oauth/introspect",
+			want:   "POST\n/oauth/introspect\n1700000000\nnonce\nhash",
+		},
+		{
+			name:     "with query",
+			method:   "GET",
+			path:     "/internal/users/telegram/42",
+			rawQuery: "fields=id&x=%20",
+			want:     "GET\n/internal/users/telegram/42?fields=id&x=%20\n1700000000\nnonce\nhash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StringToSign(tt.method, tt.path, tt.rawQuery, 1700000000, "nonce", "hash"); got != tt.want {
+				t.Errorf("StringToSign = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashBody(t *testing.T) {
+	// SHA-256 of the empty string
+	if got := HashBody(nil); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("HashBody(nil) = %s", got)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	key := []byte("playlist-key")
+	req := httptest.NewRequest(http.MethodPost, "http://auth:8081/oauth/introspect?pretty=1", strings.NewReader("token=abc"))
+
+	if err := Sign(req, "playlist", key); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	// The body can still be sent
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != "token=abc" {
+		t.Errorf("body after signing = %q", body)
+	}
+	if req.Header.Get(HeaderService) != "playlist" {
+		t.Errorf("service header = %q", req.Header.Get(HeaderService))
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp header: %v", err)
+	}
+	nonce := req.Header.Get(HeaderNonce)
+	signature := req.Header.Get(HeaderSignature)
+
+	tests := []struct {
+		name      string
+		key       []byte
+		method    string
+		path      string
+		rawQuery  string
+		body      string
+		signature string
+		want      bool
+	}{
+		{name: "signed request", key: key, method: "POST", path: "/oauth/introspect", rawQuery: "pretty=1", body: "token=abc", signature: signature, want: true},
+		{name: "upper case signature", key: key, method: "POST", path: "/oauth/introspect", rawQuery: "pretty=1", body: "token=abc", signature: strings.ToUpper(signature), want: true},
+		{name: "other key", key: []byte("other-key"), method: "POST", path: "/oauth/introspect", rawQuery: "pretty=1", body: "token=abc", signature: signature},
+		{name: "other method", key: key, method: "PUT", path: "/oauth/introspect", rawQuery: "pretty=1", body: "token=abc", signature: signature},
+		{name: "other path", key: key, method: "POST", path: "/internal/introspect", rawQuery: "pretty=1", body: "token=abc", signature: signature},
+		{name: "other query", key: key, method: "POST", path: "/oauth/introspect", rawQuery: "pretty=2", body: "token=abc", signature: signature},
+		{name: "query dropped", key: key, method: "POST", path: "/oauth/introspect", body: "token=abc", signature: signature},
+		{name: "other body", key: key, method: "POST", path: "/oauth/introspect", rawQuery: "pretty=1", body: "token=abd", signature: signature},
+		{name: "truncated signature", key: key, method: "POST", path: "/oauth/introspect", rawQuery: "pretty=1", body: "token=abc", signature: signature[:len(signature)-2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stringToSign := StringToSign(tt.method, tt.path, tt.rawQuery, timestamp, nonce, HashBody([]byte(tt.body)))
+			if got := Verify(tt.key, stringToSign, tt.signature); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignRejectsLargeBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/oauth/introspect", strings.NewReader(strings.Repeat("a", MaxBodySize+1)))
+	if err := Sign(req, "playlist", []byte("key")); err == nil {
+		t.Error("Sign accepted a body over MaxBodySize")
+	}
+}
+
+func TestNewNonce(t *testing.T) {
+	a, err := NewNonce()
+	if err != nil {
+		t.Fatalf("NewNonce failed: %v", err)
+	}
+	b, _ := NewNonce()
+	if len(a) != 32 || a == b {
+		t.Errorf("NewNonce = %q, %q, want distinct 32 character nonces", a, b)
+	}
+}